@@ -124,34 +124,7 @@ const AddProduct = () => {
       });
 
       if (res.ok) {
-        const createdProduct = await res.json();
-        
-        // Auto-sync stock to inventory service
-        if (createdProduct.id && values.stock > 0) {
-          try {
-            const token = document.cookie
-              .split('; ')
-              .find(row => row.startsWith('access_token='))
-              ?.split('=')[1];
-            
-            if (token) {
-              await fetch(`${process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8000'}/inventory/stock`, {
-                method: "POST",
-                headers: {
-                  "Content-Type": "application/json",
-                  "Authorization": `Bearer ${token}`,
-                },
-                body: JSON.stringify({
-                  product_id: createdProduct.id,
-                  quantity_change: values.stock,
-                }),
-              });
-            }
-          } catch (stockError) {
-            console.error("Failed to sync stock:", stockError);
-          }
-        }
-        
+        // Inventory initializes stock from the product.created event
         alert("Product created successfully!");
         window.location.reload(); // Simple reload to refresh list
       } else {
@@ -384,18 +357,26 @@ const AddProduct = () => {
                                           type="file"
                                           accept="image/*"
                                           className="hidden"
-                                          onChange={(e) => {
+                                          onChange={async (e) => {
                                             const file = e.target.files?.[0];
                                             if (file) {
-                                              const reader = new FileReader();
-                                              reader.onloadend = () => {
-                                                const currentImages = form.getValues("images");
-                                                form.setValue("images", {
-                                                  ...currentImages,
-                                                  [color]: reader.result as string,
-                                                }, { shouldValidate: true });
-                                              };
-                                              reader.readAsDataURL(file);
+                                              // Upload first; the product only stores the returned URL
+                                              const res = await fetch(`${process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8000'}/images`, {
+                                                method: "POST",
+                                                headers: { "Content-Type": file.type },
+                                                body: file,
+                                              });
+                                              if (!res.ok) {
+                                                const error = await res.json();
+                                                alert(`Failed to upload image: ${error.error || "Unknown error"}`);
+                                                return;
+                                              }
+                                              const uploaded = await res.json();
+                                              const currentImages = form.getValues("images");
+                                              form.setValue("images", {
+                                                ...currentImages,
+                                                [color]: uploaded.urls.large,
+                                              }, { shouldValidate: true });
                                             }
                                           }}
                                         />
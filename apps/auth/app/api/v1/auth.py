@@ -27,7 +27,7 @@ async def verify_token(token: str = Depends(oauth2_scheme)):
             detail="Invalid or expired token",
             headers={"WWW-Authenticate": "Bearer"},
         )
-    return {"user_id": payload.get("sub")}
+    return {"user_id": payload.get("sub"), "role": payload.get("role", "user")}
 
 @router.post("/login")
 async def login(data: LoginRequest, db: AsyncSession = Depends(get_session)):
@@ -52,7 +52,7 @@ async def login(data: LoginRequest, db: AsyncSession = Depends(get_session)):
         )
     
     access_token = security.create_access_token(
-        data={"sub": str(user.id)}
+        data={"sub": str(user.id), "role": user.role}
     )
 
     refresh_token_str = security.create_refresh_token_str()
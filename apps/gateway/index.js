@@ -27,6 +27,7 @@ app.use(
 // Authentication Middleware
 const checkAuth = async (req, res, next) => {
   delete req.headers["x-user-id"];
+  delete req.headers["x-user-role"];
 
   const authHeader = req.headers["authorization"];
   if (!authHeader) {
@@ -52,6 +53,7 @@ const checkAuth = async (req, res, next) => {
     );
 
     req.headers["x-user-id"] = response.data.user_id;
+    req.headers["x-user-role"] = response.data.role || "user";
     console.log(`User Id verified: ${response.data.user_id}`);
     next();
   } catch (error) {
@@ -65,6 +67,19 @@ const checkAuth = async (req, res, next) => {
   }
 };
 
+// Must run after checkAuth
+const requireAdmin = (req, res, next) => {
+  if (req.headers["x-user-role"] !== "admin") {
+    return res.status(403).json({ error: "Admin access required" });
+  }
+  next();
+};
+
+// Only the product service may ask which products orders reference
+app.use("/order/orders/product-references", (req, res) =>
+  res.status(404).json({ error: "Not found" }),
+);
+
 // Order Service Proxy
 app.use(
   "/order",
@@ -114,6 +129,27 @@ const PROTO_PATH_INVENTORY =
 const grpc = require("@grpc/grpc-js");
 const protoLoader = require("@grpc/proto-loader");
 
+// gRPC status code -> HTTP status for errors from the product and inventory services
+const GRPC_HTTP_STATUS = {
+  [grpc.status.INVALID_ARGUMENT]: 400,
+  [grpc.status.UNAUTHENTICATED]: 401,
+  [grpc.status.PERMISSION_DENIED]: 403,
+  [grpc.status.NOT_FOUND]: 404,
+  [grpc.status.ALREADY_EXISTS]: 409,
+  [grpc.status.FAILED_PRECONDITION]: 409,
+  [grpc.status.ABORTED]: 409,
+  [grpc.status.RESOURCE_EXHAUSTED]: 429,
+  [grpc.status.CANCELLED]: 499,
+  [grpc.status.UNAVAILABLE]: 503,
+  [grpc.status.DEADLINE_EXCEEDED]: 504,
+};
+
+const sendGrpcError = (res, err) => {
+  res
+    .status(GRPC_HTTP_STATUS[err.code] || 500)
+    .json({ error: err.details || err.message, code: grpc.status[err.code] });
+};
+
 // Product Service Client
 const productPackageDefinition = protoLoader.loadSync(PROTO_PATH_PRODUCT, {
   keepCase: true,
@@ -129,6 +165,14 @@ const productClient = new productProto.ProductService(
   process.env.PRODUCT_SERVICE_URL || "product-service:5004",
   grpc.credentials.createInsecure(),
 );
+const categoryClient = new productProto.CategoryService(
+  process.env.PRODUCT_SERVICE_URL || "product-service:5004",
+  grpc.credentials.createInsecure(),
+);
+const imageClient = new productProto.ImageService(
+  process.env.PRODUCT_SERVICE_URL || "product-service:5004",
+  grpc.credentials.createInsecure(),
+);
 
 // Inventory Service Client
 const inventoryPackageDefinition = protoLoader.loadSync(PROTO_PATH_INVENTORY, {
@@ -147,60 +191,943 @@ const inventoryClient = new inventoryProto.InventoryService(
 );
 
 // --- Product Routes (REST -> gRPC) ---
+// Follow next_page_token via ?page_token=; ?page= is kept for older clients.
+// total_count is only computed for ?page= requests or ?include_total=true.
+const listProductsRequest = (query) => ({
+  page: query.page_token ? 0 : parseInt(query.page) || 0,
+  page_token: query.page_token || "",
+  limit: parseInt(query.limit) || 10,
+  category_id: query.category || "",
+  include_total: query.include_total === "true",
+});
+
+// Cost prices are for staff only; public product routes drop them
+const withoutCost = ({ cost_price, ...product }) => product;
+
 app.get("/products", (req, res) => {
   productClient.ListProducts(
+    listProductsRequest(req.query),
+    (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json({
+        ...response,
+        products: (response.products || []).map(withoutCost),
+      });
+    },
+  );
+});
+
+// Admin listing that also returns archived (soft-deleted) products
+app.get("/admin/products", checkAuth, requireAdmin, (req, res) => {
+  productClient.ListProducts(
+    { ...listProductsRequest(req.query), include_archived: true },
+    (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response);
+    },
+  );
+});
+
+// Must be registered before /products/:id so "search" isn't treated as an id
+app.get("/products/search", (req, res) => {
+  const list = (value) => (value ? String(value).split(",") : []);
+  productClient.SearchProducts(
     {
+      query: req.query.q || "",
+      category_id: req.query.category || "",
+      min_price: parseFloat(req.query.min_price) || 0,
+      max_price: parseFloat(req.query.max_price) || 0,
+      sizes: list(req.query.sizes),
+      colors: list(req.query.colors),
+      sort: req.query.sort || "",
       page: parseInt(req.query.page) || 1,
       limit: parseInt(req.query.limit) || 10,
     },
     (err, response) => {
-      if (err) return res.status(500).json({ error: err.message });
-      res.json(response);
+      if (err) return sendGrpcError(res, err);
+      res.json({
+        ...response,
+        products: (response.products || []).map(withoutCost),
+      });
     },
   );
 });
 
 app.get("/products/:id", (req, res) => {
   productClient.GetProduct({ id: req.params.id }, (err, response) => {
-    if (err) return res.status(500).json({ error: err.message });
-    res.json(response);
+    if (err) return sendGrpcError(res, err);
+    res.json(withoutCost(response));
   });
 });
 
-app.post("/products", express.json({ limit: "50mb" }), (req, res) => {
+app.post("/products", express.json(), (req, res) => {
   productClient.CreateProduct(req.body, (err, response) => {
-    if (err) return res.status(500).json({ error: err.message });
-    res.json(response);
+    if (err) return sendGrpcError(res, err);
+    res.json(withoutCost(response));
   });
 });
 
-app.delete("/products/:id", (req, res) => {
+app.put(
+  "/products/:id",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    productClient.UpdateProduct(
+      // Only the fields in the body change; the rest keep their stored values
+      {
+        ...req.body,
+        id: req.params.id,
+        update_fields: Object.keys(req.body).filter((key) => key !== "id"),
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(withoutCost(response));
+      },
+    );
+  },
+);
+
+app.delete("/products/:id", checkAuth, requireAdmin, (req, res) => {
   productClient.DeleteProduct({ id: req.params.id }, (err, response) => {
-    if (err) return res.status(500).json({ error: err.message });
+    if (err) return sendGrpcError(res, err);
+    res.json(response);
+  });
+});
+
+app.post("/products/:id/restore", checkAuth, requireAdmin, (req, res) => {
+  productClient.RestoreProduct({ id: req.params.id }, (err, response) => {
+    if (err) return sendGrpcError(res, err);
+    res.json(response);
+  });
+});
+
+// --- Image Routes ---
+// Upload the raw file as the request body (Content-Type: image/*); it is
+// streamed to the product service in chunks to stay under gRPC's message limit.
+const IMAGE_CHUNK_SIZE = 64 * 1024;
+
+app.post(
+  "/images",
+  checkAuth,
+  requireAdmin,
+  express.raw({ type: "image/*", limit: "10mb" }),
+  (req, res) => {
+    if (!Buffer.isBuffer(req.body) || req.body.length === 0) {
+      return res.status(400).json({ error: "Expected an image/* request body" });
+    }
+    const call = imageClient.UploadImage((err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response);
+    });
+    for (let i = 0; i < req.body.length; i += IMAGE_CHUNK_SIZE) {
+      call.write({ chunk: req.body.subarray(i, i + IMAGE_CHUNK_SIZE) });
+    }
+    call.end();
+  },
+);
+
+app.delete("/images/*", checkAuth, requireAdmin, (req, res) => {
+  imageClient.DeleteImage({ key: req.params[0] }, (err, response) => {
+    if (err) return sendGrpcError(res, err);
+    res.json(response);
+  });
+});
+
+// Only used by the local storage backend; with S3 the URLs point at the bucket
+app.use(
+  "/images",
+  createProxyMiddleware({
+    target: process.env.PRODUCT_IMAGE_URL || "http://product-service:8014",
+    changeOrigin: true,
+  }),
+);
+
+// --- Category Routes (REST -> gRPC) ---
+app.get("/categories", (req, res) => {
+  categoryClient.GetCategoryTree(
+    { root_id: req.query.root || "" },
+    (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response);
+    },
+  );
+});
+
+app.get("/categories/:id", (req, res) => {
+  categoryClient.GetCategory({ id: req.params.id }, (err, response) => {
+    if (err) return sendGrpcError(res, err);
+    res.json(response);
+  });
+});
+
+app.post(
+  "/categories",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    categoryClient.CreateCategory(req.body, (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response);
+    });
+  },
+);
+
+app.put(
+  "/categories/:id",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    categoryClient.UpdateCategory(
+      { ...req.body, id: req.params.id },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+app.post(
+  "/categories/:id/move",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    categoryClient.MoveCategory(
+      { ...req.body, id: req.params.id },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+app.delete("/categories/:id", checkAuth, requireAdmin, (req, res) => {
+  categoryClient.DeleteCategory({ id: req.params.id }, (err, response) => {
+    if (err) return sendGrpcError(res, err);
     res.json(response);
   });
 });
 
 // --- Inventory Routes (REST -> gRPC) ---
-app.post("/inventory/stock", express.json(), (req, res) => {
-  inventoryClient.UpdateStock(
+app.post(
+  "/inventory/stock",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    inventoryClient.UpdateStock(
+      {
+        product_id: req.body.product_id,
+        quantity_change: req.body.quantity_change,
+        location: req.body.location || "",
+        reason: req.body.reason || "",
+        reference_id: req.body.reference_id || "",
+        // Stock that expires arrives in a lot: { lot_code, expires_on: "YYYY-MM-DD" }
+        lot_code: req.body.lot_code || "",
+        expires_on: req.body.expires_on || "",
+        actor: req.headers["x-user-id"],
+        // Retries carrying the same Idempotency-Key are applied only once
+        request_id: req.headers["idempotency-key"] || "",
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+// Stock overview; ?filter=below_threshold|zero_stock, ?updated_since=<RFC 3339>,
+// ?sort=product_id|quantity|updated_at, ?order=desc
+const inventoryQuery = (query) => ({
+  filter: query.filter || "",
+  updated_since: query.updated_since || "",
+  sort: query.sort || "",
+  descending: query.order === "desc",
+});
+
+app.get("/inventory", checkAuth, requireAdmin, (req, res) => {
+  inventoryClient.ListInventory(
+    {
+      ...inventoryQuery(req.query),
+      limit: parseInt(req.query.limit) || 20,
+      page_token: req.query.page_token || "",
+    },
+    (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response);
+    },
+  );
+});
+
+app.get("/inventory/valuation", checkAuth, requireAdmin, (req, res) => {
+  inventoryClient.GetInventoryValuation({}, (err, response) => {
+    if (err) return sendGrpcError(res, err);
+    res.json(response);
+  });
+});
+
+// What to buy from forecast demand; ?product_ids=a,b (default: all sold),
+// ?method=moving_average|exponential_smoothing, ?window_days=, ?only_needed=true
+app.get(
+  "/inventory/reorder-suggestions",
+  checkAuth,
+  requireAdmin,
+  (req, res) => {
+    const productIds = (req.query.product_ids || "")
+      .split(",")
+      .map((id) => id.trim())
+      .filter(Boolean);
+    inventoryClient.GetReorderSuggestions(
+      {
+        product_ids: productIds,
+        method: req.query.method || "",
+        window_days: parseInt(req.query.window_days) || 0,
+        only_needed: req.query.only_needed === "true",
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+// Same filters as /inventory, archived products included, streamed as a CSV
+// download
+app.get("/inventory/export.csv", checkAuth, requireAdmin, (req, res) => {
+  const call = inventoryClient.ExportInventory(inventoryQuery(req.query));
+  res.setHeader("Content-Type", "text/csv; charset=utf-8");
+  res.setHeader("Content-Disposition", 'attachment; filename="inventory.csv"');
+  call.on("data", (chunk) => res.write(chunk.data));
+  call.on("end", () => res.end());
+  call.on("error", (err) => {
+    if (!res.headersSent) return sendGrpcError(res, err);
+    res.destroy(err);
+  });
+  req.on("close", () => call.cancel());
+});
+
+// Each open watch holds a gRPC stream on the inventory service
+const MAX_WATCHES_PER_USER = 5;
+const WATCH_HEARTBEAT_MS = 15 * 1000;
+const openWatches = new Map(); // User id -> open /inventory/watch streams
+
+// Live stock as Server-Sent Events: ?product_ids=a,b,c. The current stock of
+// each product comes first, then one "stock" event per change. A comment line
+// every 15 seconds keeps idle streams from being closed by proxies.
+app.get("/inventory/watch", checkAuth, (req, res) => {
+  const userId = req.headers["x-user-id"];
+  const open = openWatches.get(userId) || 0;
+  if (open >= MAX_WATCHES_PER_USER) {
+    return res.status(429).json({
+      error: `At most ${MAX_WATCHES_PER_USER} open watches per user`,
+    });
+  }
+  openWatches.set(userId, open + 1);
+
+  const productIds = (req.query.product_ids || "")
+    .split(",")
+    .map((id) => id.trim())
+    .filter(Boolean);
+  const call = inventoryClient.WatchStock({ product_ids: productIds });
+  let started = false;
+  const heartbeat = setInterval(() => {
+    if (started) res.write(": heartbeat\n\n");
+  }, WATCH_HEARTBEAT_MS);
+  let released = false;
+  const release = () => {
+    if (released) return;
+    released = true;
+    clearInterval(heartbeat);
+    const left = openWatches.get(userId) - 1;
+    if (left > 0) openWatches.set(userId, left);
+    else openWatches.delete(userId);
+  };
+
+  call.on("data", ({ reorder_point, ...change }) => {
+    if (!started) {
+      started = true;
+      res.writeHead(200, {
+        "Content-Type": "text/event-stream",
+        "Cache-Control": "no-cache",
+        Connection: "keep-alive",
+      });
+    }
+    res.write(`event: stock\ndata: ${JSON.stringify(change)}\n\n`);
+  });
+  call.on("end", () => {
+    release();
+    res.end();
+  });
+  call.on("error", (err) => {
+    release();
+    if (err.code === grpc.status.CANCELLED) return;
+    if (!started) return sendGrpcError(res, err);
+    res.end();
+  });
+  req.on("close", () => {
+    release();
+    call.cancel();
+  });
+});
+
+// Splits CSV text into rows of cells as RFC 4180 describes: a quoted cell
+// may hold commas, line breaks and "" for a quote.
+const parseCsv = (text) => {
+  const rows = [];
+  let row = [];
+  let cell = "";
+  let quoted = false;
+  for (let i = 0; i < text.length; i++) {
+    const c = text[i];
+    if (quoted) {
+      if (c !== '"') {
+        cell += c;
+      } else if (text[i + 1] === '"') {
+        cell += '"';
+        i++;
+      } else {
+        quoted = false;
+      }
+    } else if (c === '"' && cell.trim() === "") {
+      quoted = true;
+      cell = "";
+    } else if (c === ",") {
+      row.push(cell);
+      cell = "";
+    } else if (c === "\n" || c === "\r") {
+      if (c === "\r" && text[i + 1] === "\n") i++;
+      row.push(cell);
+      rows.push(row);
+      row = [];
+      cell = "";
+    } else {
+      cell += c;
+    }
+  }
+  if (quoted) throw new Error(`row ${rows.length + 1}: unterminated quote`);
+  if (cell !== "" || row.length > 0) {
+    row.push(cell);
+    rows.push(row);
+  }
+  return rows;
+};
+
+// Physical stock counts as CSV rows of product_id,counted_quantity[,location];
+// a header row is skipped. Rows without a location use ?location= or the
+// default one. ?dry_run=true only reports the differences.
+const parseStockCounts = (text) => {
+  const counts = [];
+  const rows = parseCsv(text.replace(/^\uFEFF/, ""));
+  for (let i = 0; i < rows.length; i++) {
+    const cells = rows[i].map((c) => c.trim());
+    if (cells.length === 1 && cells[0] === "") continue;
+    if (i === 0 && cells[0] === "product_id") continue;
+    const quantity = Number(cells[1]);
+    if (
+      cells.length > 3 ||
+      !cells[0] ||
+      cells[1] === "" ||
+      !Number.isInteger(quantity)
+    ) {
+      throw new Error(
+        `row ${i + 1}: expected product_id,counted_quantity[,location]`,
+      );
+    }
+    counts.push({
+      product_id: cells[0],
+      counted_quantity: quantity,
+      location: cells[2] || "",
+    });
+  }
+  return counts;
+};
+
+app.post(
+  "/inventory/import",
+  checkAuth,
+  requireAdmin,
+  express.text({ type: "text/csv", limit: "5mb" }),
+  (req, res) => {
+    if (typeof req.body !== "string") {
+      return res
+        .status(400)
+        .json({ error: "Expected a text/csv request body" });
+    }
+    let counts;
+    try {
+      counts = parseStockCounts(req.body);
+    } catch (e) {
+      return res.status(400).json({ error: e.message });
+    }
+    const call = inventoryClient.ImportStock((err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response);
+    });
+    call.write({
+      options: {
+        dry_run: req.query.dry_run === "true",
+        location: req.query.location || "",
+        reference_id: req.query.reference_id || "",
+        actor: req.headers["x-user-id"],
+        request_id: req.headers["idempotency-key"] || "",
+      },
+    });
+    counts.forEach((count) => call.write({ count }));
+    call.end();
+  },
+);
+
+// Stock locations (warehouses and stores)
+app.get("/inventory/locations", checkAuth, requireAdmin, (req, res) => {
+  inventoryClient.ListLocations(
+    { include_inactive: req.query.include_inactive === "true" },
+    (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response.locations || []);
+    },
+  );
+});
+
+app.post(
+  "/inventory/locations",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    inventoryClient.CreateLocation(
+      {
+        code: req.body.code,
+        name: req.body.name,
+        postcode: req.body.postcode || "",
+        service_area: req.body.service_area || [],
+        priority: req.body.priority || 0,
+        active: req.body.active !== false,
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.status(201).json(response);
+      },
+    );
+  },
+);
+
+// Preview where items would ship from; nothing is reserved
+app.post("/inventory/allocate", checkAuth, express.json(), (req, res) => {
+  inventoryClient.AllocateStock(
+    {
+      items: req.body.items || [],
+      postcode: req.body.postcode || "",
+      strategy: req.body.strategy || "",
+    },
+    (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response);
+    },
+  );
+});
+
+// Stock transfers between locations: create, then ship, then receive (or cancel)
+app.post(
+  "/inventory/transfers",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    inventoryClient.CreateTransfer(
+      {
+        from_location: req.body.from_location,
+        to_location: req.body.to_location,
+        lines: req.body.lines || [],
+        note: req.body.note || "",
+        actor: req.headers["x-user-id"],
+        request_id: req.headers["idempotency-key"] || "",
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.status(201).json(response);
+      },
+    );
+  },
+);
+
+app.get("/inventory/transfers", checkAuth, requireAdmin, (req, res) => {
+  inventoryClient.ListTransfers(
     {
-      product_id: req.body.product_id,
-      quantity_change: req.body.quantity_change,
+      status: req.query.status || "",
+      location: req.query.location || "",
+      limit: parseInt(req.query.limit) || 20,
+      page_token: req.query.page_token || "",
     },
     (err, response) => {
-      if (err) return res.status(500).json({ error: err.message });
+      if (err) return sendGrpcError(res, err);
       res.json(response);
     },
   );
 });
 
+app.get("/inventory/transfers/:id", checkAuth, requireAdmin, (req, res) => {
+  inventoryClient.GetTransfer({ id: req.params.id }, (err, response) => {
+    if (err) return sendGrpcError(res, err);
+    res.json(response);
+  });
+});
+
+app.post(
+  "/inventory/transfers/:id/ship",
+  checkAuth,
+  requireAdmin,
+  (req, res) => {
+    inventoryClient.ShipTransfer(
+      { id: req.params.id, actor: req.headers["x-user-id"] },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+// Body lines list only products that arrived short or over, with a note
+app.post(
+  "/inventory/transfers/:id/receive",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    inventoryClient.ReceiveTransfer(
+      {
+        id: req.params.id,
+        actor: req.headers["x-user-id"],
+        lines: (req.body && req.body.lines) || [],
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+app.post(
+  "/inventory/transfers/:id/cancel",
+  checkAuth,
+  requireAdmin,
+  (req, res) => {
+    inventoryClient.CancelTransfer(
+      { id: req.params.id, actor: req.headers["x-user-id"] },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+// Suppliers stock is bought from
+app.get("/inventory/suppliers", checkAuth, requireAdmin, (req, res) => {
+  inventoryClient.ListSuppliers(
+    { include_inactive: req.query.include_inactive === "true" },
+    (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response.suppliers || []);
+    },
+  );
+});
+
+app.post(
+  "/inventory/suppliers",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    inventoryClient.CreateSupplier(
+      {
+        code: req.body.code,
+        name: req.body.name,
+        email: req.body.email || "",
+        phone: req.body.phone || "",
+        lead_time_days: req.body.lead_time_days || 0,
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.status(201).json(response);
+      },
+    );
+  },
+);
+
+// Replaces the supplier: omitted details are cleared and active defaults to true
+app.put(
+  "/inventory/suppliers/:code",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    inventoryClient.UpdateSupplier(
+      {
+        code: req.params.code,
+        name: req.body.name,
+        email: req.body.email || "",
+        phone: req.body.phone || "",
+        lead_time_days: req.body.lead_time_days || 0,
+        active: req.body.active !== false,
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+// Purchase orders: stock expected from a supplier, received over one or more deliveries
+app.post(
+  "/inventory/purchase-orders",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    inventoryClient.CreatePurchaseOrder(
+      {
+        supplier_code: req.body.supplier_code,
+        location: req.body.location || "",
+        lines: req.body.lines || [],
+        expected_at: req.body.expected_at || "",
+        note: req.body.note || "",
+        actor: req.headers["x-user-id"],
+        request_id: req.headers["idempotency-key"] || "",
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.status(201).json(response);
+      },
+    );
+  },
+);
+
+app.get("/inventory/purchase-orders", checkAuth, requireAdmin, (req, res) => {
+  inventoryClient.ListPurchaseOrders(
+    {
+      status: req.query.status || "",
+      supplier_code: req.query.supplier || "",
+      limit: parseInt(req.query.limit) || 20,
+      page_token: req.query.page_token || "",
+    },
+    (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response);
+    },
+  );
+});
+
+app.get(
+  "/inventory/purchase-orders/:id",
+  checkAuth,
+  requireAdmin,
+  (req, res) => {
+    inventoryClient.GetPurchaseOrder({ id: req.params.id }, (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response);
+    });
+  },
+);
+
+// Body lines list what arrived in this delivery: { product_id, quantity },
+// plus lot_code and expires_on for stock that expires
+app.post(
+  "/inventory/purchase-orders/:id/receive",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    inventoryClient.ReceivePurchaseOrder(
+      {
+        id: req.params.id,
+        actor: req.headers["x-user-id"],
+        lines: (req.body && req.body.lines) || [],
+        request_id: req.headers["idempotency-key"] || "",
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+app.post(
+  "/inventory/purchase-orders/:id/cancel",
+  checkAuth,
+  requireAdmin,
+  (req, res) => {
+    inventoryClient.CancelPurchaseOrder(
+      { id: req.params.id, actor: req.headers["x-user-id"] },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+// Flash sales sell a set quantity of one product from Redis for a limited
+// time, with an optional per-user limit
+app.post(
+  "/inventory/flash-sales",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    inventoryClient.CreateFlashSale(
+      {
+        product_id: req.body.product_id,
+        location: req.body.location || "",
+        quantity: req.body.quantity,
+        per_user_limit: req.body.per_user_limit || 0,
+        starts_at: req.body.starts_at || "",
+        ends_at: req.body.ends_at || "",
+        actor: req.headers["x-user-id"],
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.status(201).json(response);
+      },
+    );
+  },
+);
+
+app.get("/inventory/flash-sales", checkAuth, requireAdmin, (req, res) => {
+  inventoryClient.ListFlashSales(
+    {
+      status: req.query.status || "",
+      product_id: req.query.product_id || "",
+    },
+    (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response.flash_sales || []);
+    },
+  );
+});
+
+app.get("/inventory/flash-sales/:id", checkAuth, requireAdmin, (req, res) => {
+  inventoryClient.GetFlashSale({ id: req.params.id }, (err, response) => {
+    if (err) return sendGrpcError(res, err);
+    res.json(response);
+  });
+});
+
+app.post(
+  "/inventory/flash-sales/:id/end",
+  checkAuth,
+  requireAdmin,
+  (req, res) => {
+    inventoryClient.EndFlashSale({ id: req.params.id }, (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response);
+    });
+  },
+);
+
+// Lots whose last day of sale is within ?within_days= (default 30), soonest first
+app.get("/inventory/lots/expiring", checkAuth, requireAdmin, (req, res) => {
+  inventoryClient.ListExpiringLots(
+    {
+      within_days: parseInt(req.query.within_days) || 0,
+      location: req.query.location || "",
+    },
+    (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response.lots || []);
+    },
+  );
+});
+
+// Stock ledger, newest first; follow next_page_token via ?page_token=
+app.get(
+  "/inventory/:productId/movements",
+  checkAuth,
+  requireAdmin,
+  (req, res) => {
+    inventoryClient.ListStockMovements(
+      {
+        product_id: req.params.productId,
+        limit: parseInt(req.query.limit) || 20,
+        page_token: req.query.page_token || "",
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+// A product's lots, earliest expiry first per location; ?include_expired=true adds written-off lots
+app.get("/inventory/:productId/lots", checkAuth, requireAdmin, (req, res) => {
+  inventoryClient.ListLots(
+    {
+      product_id: req.params.productId,
+      location: req.query.location || "",
+      include_expired: req.query.include_expired === "true",
+    },
+    (err, response) => {
+      if (err) return sendGrpcError(res, err);
+      res.json(response.lots || []);
+    },
+  );
+});
+
+// Audit stored stock against the ledger; ?apply=true rewrites it from the ledger
+app.post(
+  "/inventory/:productId/reconcile",
+  checkAuth,
+  requireAdmin,
+  (req, res) => {
+    inventoryClient.ReconcileStock(
+      { product_id: req.params.productId, apply: req.query.apply === "true" },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
+// Stock at or below the reorder point raises an inventory.low_stock event; 0 turns it off
+app.put(
+  "/inventory/:productId/reorder-point",
+  checkAuth,
+  requireAdmin,
+  express.json(),
+  (req, res) => {
+    inventoryClient.SetReorderPoint(
+      {
+        product_id: req.params.productId,
+        reorder_point: req.body.reorder_point,
+      },
+      (err, response) => {
+        if (err) return sendGrpcError(res, err);
+        res.json(response);
+      },
+    );
+  },
+);
+
 app.get("/inventory/:productId", (req, res) => {
   inventoryClient.GetStock(
-    { product_id: req.params.productId },
+    { product_id: req.params.productId, location: req.query.location || "" },
     (err, response) => {
-      if (err) return res.status(500).json({ error: err.message });
-      res.json(response);
+      if (err) return sendGrpcError(res, err);
+      // Reorder points and backorders are back-office data
+      const { reorder_point, backordered, ...stock } = response;
+      res.json(stock);
     },
   );
 });
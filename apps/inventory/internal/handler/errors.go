@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"net"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/repository"
+	"github.com/thapakon-thai/eshop-microservices/proto/grpcerr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+const errorDomain = "inventory.eshop"
+
+// Postgres SQLSTATE for a violated CHECK constraint (quantity >= 0)
+const checkViolation = "23514"
+
+// toStatus converts a service or repository error into a gRPC status error
+// carrying a google.rpc.ErrorInfo detail. Errors that are already statuses
+// pass through unchanged.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var svcErr *grpcerr.Error
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &svcErr):
+		return newStatus(grpcerr.Code(svcErr.Kind), svcErr.Reason, svcErr.Message)
+	case errors.Is(err, repository.ErrInsufficientStock):
+		return newStatus(codes.FailedPrecondition, "INSUFFICIENT_STOCK", err.Error())
+	case errors.Is(err, repository.ErrStockArchived):
+		return newStatus(codes.FailedPrecondition, "STOCK_ARCHIVED", err.Error())
+	case errors.Is(err, repository.ErrRequestIDReused):
+		return newStatus(codes.InvalidArgument, "REQUEST_ID_REUSED", err.Error())
+	case errors.Is(err, repository.ErrLocationExists):
+		return newStatus(codes.AlreadyExists, "LOCATION_EXISTS", err.Error())
+	case errors.Is(err, repository.ErrLocationNotFound):
+		return newStatus(codes.NotFound, "LOCATION_NOT_FOUND", err.Error())
+	case errors.Is(err, repository.ErrTransferNotFound):
+		return newStatus(codes.NotFound, "TRANSFER_NOT_FOUND", err.Error())
+	case errors.Is(err, repository.ErrTransferState):
+		return newStatus(codes.FailedPrecondition, "INVALID_TRANSFER_STATE", err.Error())
+	case errors.Is(err, repository.ErrSupplierExists):
+		return newStatus(codes.AlreadyExists, "SUPPLIER_EXISTS", err.Error())
+	case errors.Is(err, repository.ErrSupplierNotFound):
+		return newStatus(codes.NotFound, "SUPPLIER_NOT_FOUND", err.Error())
+	case errors.Is(err, repository.ErrPurchaseOrderNotFound):
+		return newStatus(codes.NotFound, "PURCHASE_ORDER_NOT_FOUND", err.Error())
+	case errors.Is(err, repository.ErrPurchaseOrderState):
+		return newStatus(codes.FailedPrecondition, "INVALID_PURCHASE_ORDER_STATE", err.Error())
+	case errors.Is(err, repository.ErrOverReceived):
+		return newStatus(codes.FailedPrecondition, "OVER_RECEIVED", err.Error())
+	case errors.Is(err, repository.ErrLotExpired):
+		return newStatus(codes.FailedPrecondition, "LOT_EXPIRED", err.Error())
+	case errors.Is(err, repository.ErrLotMismatch):
+		return newStatus(codes.FailedPrecondition, "LOT_MISMATCH", err.Error())
+	case errors.Is(err, repository.ErrFlashSaleNotFound):
+		return newStatus(codes.NotFound, "FLASH_SALE_NOT_FOUND", err.Error())
+	case errors.Is(err, repository.ErrFlashSaleState):
+		return newStatus(codes.FailedPrecondition, "INVALID_FLASH_SALE_STATE", err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return newStatus(codes.NotFound, "NOT_FOUND", "no stock record for product")
+	case errors.Is(err, context.DeadlineExceeded):
+		return newStatus(codes.DeadlineExceeded, "DEADLINE_EXCEEDED", err.Error())
+	case errors.Is(err, context.Canceled):
+		return newStatus(codes.Canceled, "CANCELED", err.Error())
+	case errors.As(err, &pgErr) && pgErr.Code == checkViolation:
+		return newStatus(codes.FailedPrecondition, "INSUFFICIENT_STOCK", repository.ErrInsufficientStock.Error())
+	case isConnectionError(err):
+		slog.Error("Database unavailable", "error", err)
+		return newStatus(codes.Unavailable, "DATABASE_UNAVAILABLE", "database unavailable")
+	}
+	slog.Error("Internal error", "error", err)
+	return newStatus(codes.Internal, "INTERNAL", "internal error")
+}
+
+// isConnectionError reports failures to reach Postgres at all, as opposed to
+// errors returned by a statement that ran.
+func isConnectionError(err error) bool {
+	var connErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connErr) || errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) || pgconn.Timeout(err)
+}
+
+func newStatus(code codes.Code, reason, msg string) error {
+	return grpcerr.Status(errorDomain, code, reason, msg)
+}
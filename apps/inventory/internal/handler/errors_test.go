@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/repository"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/service"
+	"github.com/thapakon-thai/eshop-microservices/proto/grpcerr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{"invalid argument", grpcerr.New(service.ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required"), codes.InvalidArgument, "PRODUCT_ID_REQUIRED"},
+		{"failed precondition", grpcerr.New(service.ErrFailedPrecondition, "FLASH_SALE_ENDED", "ended"), codes.FailedPrecondition, "FLASH_SALE_ENDED"},
+		{"wrapped sentinel", fmt.Errorf("move stock: %w", repository.ErrInsufficientStock), codes.FailedPrecondition, "INSUFFICIENT_STOCK"},
+		{"request id reused", repository.ErrRequestIDReused, codes.InvalidArgument, "REQUEST_ID_REUSED"},
+		{"location exists", repository.ErrLocationExists, codes.AlreadyExists, "LOCATION_EXISTS"},
+		{"location missing", repository.ErrLocationNotFound, codes.NotFound, "LOCATION_NOT_FOUND"},
+		{"transfer state", repository.ErrTransferState, codes.FailedPrecondition, "INVALID_TRANSFER_STATE"},
+		{"record not found", gorm.ErrRecordNotFound, codes.NotFound, "NOT_FOUND"},
+		{"deadline", context.DeadlineExceeded, codes.DeadlineExceeded, "DEADLINE_EXCEEDED"},
+		{"canceled", context.Canceled, codes.Canceled, "CANCELED"},
+		{"check violation", &pgconn.PgError{Code: checkViolation}, codes.FailedPrecondition, "INSUFFICIENT_STOCK"},
+		{"other constraint", &pgconn.PgError{Code: "23505"}, codes.Internal, "INTERNAL"},
+		{"bad connection", fmt.Errorf("query: %w", driver.ErrBadConn), codes.Unavailable, "DATABASE_UNAVAILABLE"},
+		{"unknown", errors.New("boom"), codes.Internal, "INTERNAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(toStatus(tt.err))
+			if !ok || st.Code() != tt.code {
+				t.Fatalf("code = %v, want %v", st.Code(), tt.code)
+			}
+			details := st.Details()
+			if len(details) != 1 {
+				t.Fatalf("details = %v, want one ErrorInfo", details)
+			}
+			info, ok := details[0].(*errdetails.ErrorInfo)
+			if !ok || info.Reason != tt.reason || info.Domain != errorDomain {
+				t.Fatalf("detail = %v, want %s in %s", details[0], tt.reason, errorDomain)
+			}
+		})
+	}
+}
+
+func TestToStatusPassesStatusesThrough(t *testing.T) {
+	if toStatus(nil) != nil {
+		t.Fatal("toStatus(nil) != nil")
+	}
+	upstream := status.Error(codes.Unavailable, "product service down")
+	if got := toStatus(upstream); got != upstream {
+		t.Fatalf("toStatus(status) = %v, want it unchanged", got)
+	}
+}
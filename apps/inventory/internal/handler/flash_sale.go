@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	pb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
+	"google.golang.org/grpc/codes"
+)
+
+func (h *InventoryGrpcHandler) CreateFlashSale(ctx context.Context, req *pb.CreateFlashSaleRequest) (*pb.FlashSale, error) {
+	sale := &models.FlashSale{
+		ProductID:    req.ProductId,
+		LocationCode: req.Location,
+		Quantity:     req.Quantity,
+		PerUserLimit: req.PerUserLimit,
+		CreatedBy:    req.Actor,
+	}
+	if req.StartsAt != "" {
+		startsAt, err := time.Parse(time.RFC3339, req.StartsAt)
+		if err != nil {
+			return nil, newStatus(codes.InvalidArgument, "INVALID_STARTS_AT", "starts_at must be an RFC 3339 timestamp")
+		}
+		sale.StartsAt = startsAt.UTC()
+	}
+	endsAt, err := time.Parse(time.RFC3339, req.EndsAt)
+	if err != nil {
+		return nil, newStatus(codes.InvalidArgument, "INVALID_ENDS_AT", "ends_at must be an RFC 3339 timestamp")
+	}
+	sale.EndsAt = endsAt.UTC()
+
+	created, err := h.svc.CreateFlashSale(ctx, sale)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoFlashSale(created), nil
+}
+
+func (h *InventoryGrpcHandler) GetFlashSale(ctx context.Context, req *pb.GetFlashSaleRequest) (*pb.FlashSale, error) {
+	sale, err := h.svc.GetFlashSale(ctx, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoFlashSale(sale), nil
+}
+
+func (h *InventoryGrpcHandler) ListFlashSales(ctx context.Context, req *pb.ListFlashSalesRequest) (*pb.ListFlashSalesResponse, error) {
+	sales, err := h.svc.ListFlashSales(ctx, models.FlashSaleQuery{Status: req.Status, ProductID: req.ProductId})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &pb.ListFlashSalesResponse{}
+	for _, sale := range sales {
+		res.FlashSales = append(res.FlashSales, toProtoFlashSale(sale))
+	}
+	return res, nil
+}
+
+func (h *InventoryGrpcHandler) EndFlashSale(ctx context.Context, req *pb.EndFlashSaleRequest) (*pb.FlashSale, error) {
+	sale, err := h.svc.EndFlashSale(ctx, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoFlashSale(sale), nil
+}
+
+func toProtoFlashSale(sale *models.FlashSale) *pb.FlashSale {
+	return &pb.FlashSale{
+		Id:           sale.ID,
+		ProductId:    sale.ProductID,
+		Location:     sale.LocationCode,
+		Quantity:     sale.Quantity,
+		PerUserLimit: sale.PerUserLimit,
+		StartsAt:     sale.StartsAt.UTC().Format(time.RFC3339),
+		EndsAt:       sale.EndsAt.UTC().Format(time.RFC3339),
+		Status:       sale.Status,
+		Sold:         sale.Sold,
+		CreatedBy:    sale.CreatedBy,
+		CreatedAt:    sale.CreatedAt.UTC().Format(time.RFC3339),
+		EndedAt:      formatOptionalTime(sale.EndedAt),
+	}
+}
@@ -2,8 +2,12 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"time"
 
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/repository"
 	"github.com/thapakon-thai/eshop-microservices/inventory/internal/service"
 	pb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
 )
@@ -18,18 +22,268 @@ func NewInventoryGrpcHandler(svc *service.InventoryService) *InventoryGrpcHandle
 }
 
 func (h *InventoryGrpcHandler) GetStock(ctx context.Context, req *pb.GetStockRequest) (*pb.GetStockResponse, error) {
-	inv, err := h.svc.GetStock(ctx, req.ProductId)
+	inv, stocks, err := h.svc.GetStock(ctx, req.ProductId, req.Location)
 	if err != nil {
-		slog.Warn("Product not found in inventory, returning 0", "product_id", req.ProductId)
-		return &pb.GetStockResponse{ProductId: req.ProductId, Quantity: 0}, nil
+		return nil, toStatus(err)
 	}
-	return &pb.GetStockResponse{ProductId: req.ProductId, Quantity: inv.Quantity}, nil
+
+	res := &pb.GetStockResponse{ProductId: req.ProductId, Quantity: inv.Quantity, ReorderPoint: inv.ReorderPoint, Backordered: inv.Backordered}
+	if req.Location != "" {
+		res.Quantity = 0
+	}
+	for _, ls := range stocks {
+		res.Locations = append(res.Locations, &pb.LocationStock{Location: ls.LocationCode, Quantity: ls.Quantity})
+		if req.Location != "" {
+			res.Quantity = ls.Quantity
+		}
+	}
+
+	incoming, err := h.svc.IncomingStock(ctx, req.ProductId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res.Incoming = incoming.Quantity
+	res.NextExpectedAt = formatOptionalTime(incoming.NextExpectedAt)
+	return res, nil
 }
 
 func (h *InventoryGrpcHandler) UpdateStock(ctx context.Context, req *pb.UpdateStockRequest) (*pb.UpdateStockResponse, error) {
-	inv, err := h.svc.UpdateStock(ctx, req.ProductId, req.QuantityChange)
+	lot, err := parseLot(req.LotCode, req.ExpiresOn)
+	if err != nil {
+		return nil, err
+	}
+	inv, at, err := h.svc.UpdateStock(ctx, req.ProductId, req.Location, req.QuantityChange, models.MovementInfo{
+		Reason:      req.Reason,
+		ReferenceID: req.ReferenceId,
+		Actor:       req.Actor,
+		Lot:         lot,
+	}, req.RequestId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.UpdateStockResponse{
+		Success:          true,
+		NewQuantity:      inv.Quantity,
+		Location:         at.LocationCode,
+		LocationQuantity: at.Quantity,
+	}, nil
+}
+
+func (h *InventoryGrpcHandler) BatchGetStock(ctx context.Context, req *pb.BatchGetStockRequest) (*pb.BatchGetStockResponse, error) {
+	stocks, err := h.svc.GetStocks(ctx, req.ProductIds)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &pb.BatchGetStockResponse{Stocks: make([]*pb.StockLevel, 0, len(req.ProductIds))}
+	for _, id := range req.ProductIds {
+		level := &pb.StockLevel{ProductId: id}
+		if inv, ok := stocks[id]; ok {
+			level.Quantity = inv.Quantity
+			level.Tracked = true
+		}
+		res.Stocks = append(res.Stocks, level)
+	}
+	return res, nil
+}
+
+func (h *InventoryGrpcHandler) AdjustStockBatch(ctx context.Context, req *pb.AdjustStockBatchRequest) (*pb.AdjustStockBatchResponse, error) {
+	adjustments := make([]models.StockAdjustment, 0, len(req.Adjustments))
+	for _, adj := range req.Adjustments {
+		adjustments = append(adjustments, models.StockAdjustment{
+			ProductID:    adj.ProductId,
+			LocationCode: adj.Location,
+			Change:       adj.QuantityChange,
+		})
+	}
+
+	results, err := h.svc.AdjustStockBatch(ctx, adjustments, models.MovementInfo{
+		Reason:      req.Reason,
+		ReferenceID: req.ReferenceId,
+		Actor:       req.Actor,
+	}, req.RequestId)
+	if err != nil && !errors.Is(err, repository.ErrAdjustmentRejected) {
+		return nil, toStatus(err)
+	}
+
+	res := &pb.AdjustStockBatchResponse{Success: err == nil}
+	if err != nil {
+		res.Message = err.Error()
+	}
+	for _, r := range results {
+		res.Results = append(res.Results, &pb.StockAdjustmentResult{
+			ProductId:        r.ProductID,
+			QuantityChange:   r.Change,
+			NewQuantity:      r.NewQuantity,
+			Success:          r.Message == "",
+			Message:          r.Message,
+			Reason:           r.Reason,
+			Location:         r.LocationCode,
+			LocationQuantity: r.LocationQuantity,
+		})
+	}
+	return res, nil
+}
+
+func (h *InventoryGrpcHandler) ListStockMovements(ctx context.Context, req *pb.ListStockMovementsRequest) (*pb.ListStockMovementsResponse, error) {
+	movements, next, err := h.svc.ListMovements(ctx, models.MovementQuery{
+		ProductID:   req.ProductId,
+		ReferenceID: req.ReferenceId,
+		Limit:       int(req.Limit),
+		PageToken:   req.PageToken,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &pb.ListStockMovementsResponse{NextPageToken: next}
+	for _, m := range movements {
+		res.Movements = append(res.Movements, &pb.StockMovement{
+			Id:            m.ID,
+			ProductId:     m.ProductID,
+			Delta:         m.Delta,
+			QuantityAfter: m.QuantityAfter,
+			Reason:        m.Reason,
+			ReferenceId:   m.ReferenceID,
+			Actor:         m.Actor,
+			CreatedAt:     m.CreatedAt.UTC().Format(time.RFC3339),
+			Location:      m.LocationCode,
+		})
+	}
+	return res, nil
+}
+
+func (h *InventoryGrpcHandler) ReconcileStock(ctx context.Context, req *pb.ReconcileStockRequest) (*pb.ReconcileStockResponse, error) {
+	rec, err := h.svc.ReconcileStock(ctx, req.ProductId, req.Apply)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if rec.StoredQuantity != rec.LedgerQuantity || rec.LocationQuantity != rec.LedgerQuantity {
+		slog.Warn("Stock differs from ledger", "product_id", rec.ProductID, "stored", rec.StoredQuantity, "ledger", rec.LedgerQuantity, "locations", rec.LocationQuantity, "applied", rec.Applied)
+	}
+	return &pb.ReconcileStockResponse{
+		ProductId:        rec.ProductID,
+		StoredQuantity:   rec.StoredQuantity,
+		LedgerQuantity:   rec.LedgerQuantity,
+		Applied:          rec.Applied,
+		LocationQuantity: rec.LocationQuantity,
+	}, nil
+}
+
+func (h *InventoryGrpcHandler) CreateLocation(ctx context.Context, req *pb.Location) (*pb.Location, error) {
+	location := &models.Location{
+		Code:        req.Code,
+		Name:        req.Name,
+		Postcode:    req.Postcode,
+		ServiceArea: req.ServiceArea,
+		Priority:    req.Priority,
+		Active:      req.Active,
+	}
+	if err := h.svc.CreateLocation(ctx, location); err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoLocation(location), nil
+}
+
+func (h *InventoryGrpcHandler) ListLocations(ctx context.Context, req *pb.ListLocationsRequest) (*pb.ListLocationsResponse, error) {
+	locations, err := h.svc.ListLocations(ctx, req.IncludeInactive)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &pb.ListLocationsResponse{}
+	for _, loc := range locations {
+		res.Locations = append(res.Locations, toProtoLocation(loc))
+	}
+	return res, nil
+}
+
+func (h *InventoryGrpcHandler) AllocateStock(ctx context.Context, req *pb.AllocateStockRequest) (*pb.AllocateStockResponse, error) {
+	plan, err := h.svc.AllocateStock(ctx, toAllocationRequest(req.Items, req.Postcode, req.Strategy))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.AllocateStockResponse{
+		Allocations: toProtoAllocations(plan.Allocations),
+		Fulfillable: plan.Fulfillable(),
+		Shortfalls:  toProtoShortfalls(plan.Shortfalls),
+	}, nil
+}
+
+func (h *InventoryGrpcHandler) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.ReserveStockResponse, error) {
+	allocReq := toAllocationRequest(req.Items, req.Postcode, req.Strategy)
+	allocReq.AllowBackorder = req.AllowBackorder
+	plan, err := h.svc.ReserveStock(ctx, allocReq, models.MovementInfo{
+		Reason:      req.Reason,
+		ReferenceID: req.ReferenceId,
+		Actor:       req.Actor,
+	}, req.RequestId)
+	if err != nil && !errors.Is(err, repository.ErrAdjustmentRejected) {
+		return nil, toStatus(err)
+	}
+
+	res := &pb.ReserveStockResponse{Success: err == nil}
+	if err != nil {
+		res.Message = err.Error()
+		res.Shortfalls = toProtoShortfalls(plan.Shortfalls)
+	} else {
+		res.Allocations = toProtoAllocations(plan.Allocations)
+		for _, line := range plan.Backorders {
+			res.Backorders = append(res.Backorders, &pb.Backorder{ProductId: line.ProductID, Quantity: line.Quantity})
+		}
+	}
+	return res, nil
+}
+
+func (h *InventoryGrpcHandler) ReleaseStock(ctx context.Context, req *pb.ReleaseStockRequest) (*pb.ReleaseStockResponse, error) {
+	released, restored, err := h.svc.ReleaseStock(ctx, req.RequestId, req.ProductIds, models.MovementInfo{
+		ReferenceID: req.ReferenceId,
+		Actor:       req.Actor,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.ReleaseStockResponse{Released: released, Allocations: toProtoAllocations(restored)}, nil
+}
+
+func toProtoLocation(loc *models.Location) *pb.Location {
+	return &pb.Location{
+		Code:        loc.Code,
+		Name:        loc.Name,
+		Postcode:    loc.Postcode,
+		ServiceArea: loc.ServiceArea,
+		Priority:    loc.Priority,
+		Active:      loc.Active,
+	}
+}
+
+func toAllocationRequest(items []*pb.AllocationItem, postcode, strategy string) models.AllocationRequest {
+	req := models.AllocationRequest{Postcode: postcode, Strategy: strategy}
+	for _, item := range items {
+		req.Items = append(req.Items, models.AllocationItem{ProductID: item.ProductId, Quantity: item.Quantity})
+	}
+	return req
+}
+
+func toProtoAllocations(allocations []models.Allocation) []*pb.Allocation {
+	out := make([]*pb.Allocation, 0, len(allocations))
+	for _, a := range allocations {
+		out = append(out, &pb.Allocation{ProductId: a.ProductID, Location: a.LocationCode, Quantity: a.Quantity})
+	}
+	return out
+}
+
+func toProtoShortfalls(shortfalls []models.Shortfall) []*pb.Shortfall {
+	out := make([]*pb.Shortfall, 0, len(shortfalls))
+	for _, sf := range shortfalls {
+		out = append(out, &pb.Shortfall{ProductId: sf.ProductID, Missing: sf.Missing})
+	}
+	return out
+}
+
+func (h *InventoryGrpcHandler) SetReorderPoint(ctx context.Context, req *pb.SetReorderPointRequest) (*pb.GetStockResponse, error) {
+	inv, err := h.svc.SetReorderPoint(ctx, req.ProductId, req.ReorderPoint)
 	if err != nil {
-		return &pb.UpdateStockResponse{Success: false, Message: err.Error()}, nil
+		return nil, toStatus(err)
 	}
-	return &pb.UpdateStockResponse{Success: true, NewQuantity: inv.Quantity}, nil
+	return &pb.GetStockResponse{ProductId: inv.ProductID, Quantity: inv.Quantity, ReorderPoint: inv.ReorderPoint}, nil
 }
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	pb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
+	"google.golang.org/grpc/codes"
+)
+
+func (h *InventoryGrpcHandler) ListLots(ctx context.Context, req *pb.ListLotsRequest) (*pb.ListLotsResponse, error) {
+	lots, err := h.svc.ListLots(ctx, req.ProductId, req.Location, req.IncludeExpired)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoLots(lots), nil
+}
+
+func (h *InventoryGrpcHandler) ListExpiringLots(ctx context.Context, req *pb.ListExpiringLotsRequest) (*pb.ListLotsResponse, error) {
+	lots, err := h.svc.ExpiringLots(ctx, int(req.WithinDays), req.Location)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoLots(lots), nil
+}
+
+// parseLot reads the optional lot of added stock; nil when neither field is
+// set.
+func parseLot(code, expiresOn string) (*models.LotInfo, error) {
+	if code == "" && expiresOn == "" {
+		return nil, nil
+	}
+	lot := &models.LotInfo{Code: code}
+	if expiresOn != "" {
+		t, err := time.Parse(time.DateOnly, expiresOn)
+		if err != nil {
+			return nil, newStatus(codes.InvalidArgument, "INVALID_EXPIRES_ON", "expires_on must be a date (YYYY-MM-DD)")
+		}
+		lot.ExpiresOn = t
+	}
+	return lot, nil
+}
+
+func toProtoLots(lots []*models.Lot) *pb.ListLotsResponse {
+	res := &pb.ListLotsResponse{}
+	for _, l := range lots {
+		res.Lots = append(res.Lots, &pb.Lot{
+			ProductId:  l.ProductID,
+			Location:   l.LocationCode,
+			Code:       l.Code,
+			ExpiresOn:  l.ExpiresOn.Format(time.DateOnly),
+			Quantity:   l.Quantity,
+			Status:     l.Status,
+			ReceivedAt: l.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return res
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	pb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
+	"google.golang.org/grpc/codes"
+)
+
+func (h *InventoryGrpcHandler) CreateSupplier(ctx context.Context, req *pb.Supplier) (*pb.Supplier, error) {
+	supplier := fromProtoSupplier(req)
+	if err := h.svc.CreateSupplier(ctx, supplier); err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoSupplier(supplier), nil
+}
+
+func (h *InventoryGrpcHandler) UpdateSupplier(ctx context.Context, req *pb.Supplier) (*pb.Supplier, error) {
+	supplier := fromProtoSupplier(req)
+	if err := h.svc.UpdateSupplier(ctx, supplier); err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoSupplier(supplier), nil
+}
+
+func (h *InventoryGrpcHandler) ListSuppliers(ctx context.Context, req *pb.ListSuppliersRequest) (*pb.ListSuppliersResponse, error) {
+	suppliers, err := h.svc.ListSuppliers(ctx, req.IncludeInactive)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &pb.ListSuppliersResponse{}
+	for _, s := range suppliers {
+		res.Suppliers = append(res.Suppliers, toProtoSupplier(s))
+	}
+	return res, nil
+}
+
+func (h *InventoryGrpcHandler) CreatePurchaseOrder(ctx context.Context, req *pb.CreatePurchaseOrderRequest) (*pb.PurchaseOrder, error) {
+	po := &models.PurchaseOrder{
+		SupplierCode: req.SupplierCode,
+		LocationCode: req.Location,
+		Note:         req.Note,
+		CreatedBy:    req.Actor,
+	}
+	if req.ExpectedAt != "" {
+		expected, err := time.Parse(time.RFC3339, req.ExpectedAt)
+		if err != nil {
+			return nil, newStatus(codes.InvalidArgument, "INVALID_EXPECTED_AT", "expected_at must be an RFC 3339 timestamp")
+		}
+		expected = expected.UTC()
+		po.ExpectedAt = &expected
+	}
+	for _, line := range req.Lines {
+		po.Lines = append(po.Lines, models.PurchaseOrderLine{ProductID: line.ProductId, Quantity: line.Quantity, UnitCost: line.UnitCost})
+	}
+	if err := h.svc.CreatePurchaseOrder(ctx, po, req.RequestId); err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoPurchaseOrder(po), nil
+}
+
+func (h *InventoryGrpcHandler) GetPurchaseOrder(ctx context.Context, req *pb.GetPurchaseOrderRequest) (*pb.PurchaseOrder, error) {
+	po, err := h.svc.GetPurchaseOrder(ctx, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoPurchaseOrder(po), nil
+}
+
+func (h *InventoryGrpcHandler) ListPurchaseOrders(ctx context.Context, req *pb.ListPurchaseOrdersRequest) (*pb.ListPurchaseOrdersResponse, error) {
+	orders, next, err := h.svc.ListPurchaseOrders(ctx, models.PurchaseOrderQuery{
+		Status:       req.Status,
+		SupplierCode: req.SupplierCode,
+		Limit:        int(req.Limit),
+		PageToken:    req.PageToken,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &pb.ListPurchaseOrdersResponse{NextPageToken: next}
+	for _, po := range orders {
+		res.PurchaseOrders = append(res.PurchaseOrders, toProtoPurchaseOrder(po))
+	}
+	return res, nil
+}
+
+func (h *InventoryGrpcHandler) ReceivePurchaseOrder(ctx context.Context, req *pb.ReceivePurchaseOrderRequest) (*pb.PurchaseOrder, error) {
+	deliveries := make([]models.Delivery, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lot, err := parseLot(line.LotCode, line.ExpiresOn)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, models.Delivery{ProductID: line.ProductId, Quantity: line.Quantity, Lot: lot})
+	}
+	po, err := h.svc.ReceivePurchaseOrder(ctx, req.Id, deliveries, req.Actor, req.RequestId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoPurchaseOrder(po), nil
+}
+
+func (h *InventoryGrpcHandler) CancelPurchaseOrder(ctx context.Context, req *pb.CancelPurchaseOrderRequest) (*pb.PurchaseOrder, error) {
+	po, err := h.svc.CancelPurchaseOrder(ctx, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoPurchaseOrder(po), nil
+}
+
+func fromProtoSupplier(s *pb.Supplier) *models.Supplier {
+	return &models.Supplier{
+		Code:         s.Code,
+		Name:         s.Name,
+		Email:        s.Email,
+		Phone:        s.Phone,
+		LeadTimeDays: s.LeadTimeDays,
+		Active:       s.Active,
+	}
+}
+
+func toProtoSupplier(s *models.Supplier) *pb.Supplier {
+	return &pb.Supplier{
+		Code:         s.Code,
+		Name:         s.Name,
+		Email:        s.Email,
+		Phone:        s.Phone,
+		LeadTimeDays: s.LeadTimeDays,
+		Active:       s.Active,
+	}
+}
+
+func toProtoPurchaseOrder(po *models.PurchaseOrder) *pb.PurchaseOrder {
+	res := &pb.PurchaseOrder{
+		Id:           po.ID,
+		SupplierCode: po.SupplierCode,
+		Location:     po.LocationCode,
+		Status:       po.Status,
+		TotalCost:    po.TotalCost(),
+		ExpectedAt:   formatOptionalTime(po.ExpectedAt),
+		Note:         po.Note,
+		CreatedBy:    po.CreatedBy,
+		CreatedAt:    po.CreatedAt.UTC().Format(time.RFC3339),
+		ReceivedAt:   formatOptionalTime(po.ReceivedAt),
+		CancelledAt:  formatOptionalTime(po.CancelledAt),
+	}
+	for _, line := range po.Lines {
+		pl := &pb.PurchaseOrderLine{
+			ProductId:        line.ProductID,
+			Quantity:         line.Quantity,
+			UnitCost:         line.UnitCost,
+			ReceivedQuantity: line.ReceivedQuantity,
+		}
+		if po.Status != models.PurchaseOrderCancelled {
+			pl.Outstanding = line.Outstanding()
+		}
+		res.Lines = append(res.Lines, pl)
+	}
+	return res
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	pb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
+	"google.golang.org/grpc/codes"
+)
+
+func (h *InventoryGrpcHandler) ListInventory(ctx context.Context, req *pb.ListInventoryRequest) (*pb.ListInventoryResponse, error) {
+	q, err := inventoryQuery(req.Filter, req.UpdatedSince, req.Sort, req.Descending)
+	if err != nil {
+		return nil, err
+	}
+	q.Limit, q.PageToken = int(req.Limit), req.PageToken
+
+	inventories, next, err := h.svc.ListInventory(ctx, q)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &pb.ListInventoryResponse{NextPageToken: next}
+	for _, inv := range inventories {
+		res.Items = append(res.Items, &pb.InventoryItem{
+			ProductId:    inv.ProductID,
+			Quantity:     inv.Quantity,
+			ReorderPoint: inv.ReorderPoint,
+			StockState:   models.StockStateOf(inv.Quantity, inv.ReorderPoint),
+			UpdatedAt:    inv.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return res, nil
+}
+
+func (h *InventoryGrpcHandler) GetInventoryValuation(ctx context.Context, _ *pb.GetInventoryValuationRequest) (*pb.InventoryValuation, error) {
+	report, err := h.svc.InventoryValuation(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &pb.InventoryValuation{
+		TotalQuantity:    report.TotalQuantity,
+		TotalValue:       report.TotalValue,
+		UnpricedProducts: int32(report.UnpricedProducts),
+		GeneratedAt:      report.GeneratedAt.Format(time.RFC3339),
+	}
+	for _, line := range report.Lines {
+		res.Lines = append(res.Lines, &pb.ValuationLine{
+			ProductId: line.ProductID,
+			Name:      line.Name,
+			Quantity:  line.Quantity,
+			CostPrice: line.CostPrice,
+			Value:     line.Value,
+			Priced:    line.Priced,
+		})
+	}
+	return res, nil
+}
+
+// exportChunkSize keeps each message well under gRPC's default size limit.
+const exportChunkSize = 32 * 1024
+
+func (h *InventoryGrpcHandler) ExportInventory(req *pb.ExportInventoryRequest, stream pb.InventoryService_ExportInventoryServer) error {
+	q, err := inventoryQuery(req.Filter, req.UpdatedSince, req.Sort, req.Descending)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriterSize(chunkWriter{stream}, exportChunkSize)
+	if err := h.svc.ExportInventoryCSV(stream.Context(), q, w); err != nil {
+		return toStatus(err)
+	}
+	return toStatus(w.Flush())
+}
+
+// chunkWriter sends each write as one ExportChunk.
+type chunkWriter struct {
+	stream pb.InventoryService_ExportInventoryServer
+}
+
+func (c chunkWriter) Write(p []byte) (int, error) {
+	if err := c.stream.Send(&pb.ExportChunk{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func inventoryQuery(filter, updatedSince, sort string, descending bool) (models.InventoryQuery, error) {
+	q := models.InventoryQuery{Filter: filter, Sort: sort, Descending: descending}
+	if updatedSince != "" {
+		t, err := time.Parse(time.RFC3339, updatedSince)
+		if err != nil {
+			return q, newStatus(codes.InvalidArgument, "INVALID_UPDATED_SINCE", "updated_since must be an RFC 3339 timestamp")
+		}
+		q.UpdatedSince = t
+	}
+	return q, nil
+}
+
+func (h *InventoryGrpcHandler) GetReorderSuggestions(ctx context.Context, req *pb.GetReorderSuggestionsRequest) (*pb.GetReorderSuggestionsResponse, error) {
+	q := models.ForecastQuery{
+		ProductIDs: req.ProductIds,
+		Method:     req.Method,
+		WindowDays: int(req.WindowDays),
+		OnlyNeeded: req.OnlyNeeded,
+	}
+	suggestions, err := h.svc.ReorderSuggestions(ctx, &q)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &pb.GetReorderSuggestionsResponse{Method: q.Method, WindowDays: int32(q.WindowDays)}
+	for _, sg := range suggestions {
+		res.Suggestions = append(res.Suggestions, &pb.ReorderSuggestion{
+			ProductId:         sg.ProductID,
+			DailyDemand:       sg.DailyDemand,
+			LeadTimeDays:      sg.LeadTimeDays,
+			SupplierCode:      sg.SupplierCode,
+			SafetyStock:       sg.SafetyStock,
+			ReorderPoint:      sg.ReorderPoint,
+			OnHand:            sg.OnHand,
+			Incoming:          sg.Incoming,
+			Backordered:       sg.Backordered,
+			SuggestedQuantity: sg.Suggested,
+		})
+	}
+	return res, nil
+}
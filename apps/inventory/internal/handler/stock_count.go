@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"io"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/repository"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/service"
+	pb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
+	"google.golang.org/grpc/codes"
+)
+
+func (h *InventoryGrpcHandler) ImportStock(stream pb.InventoryService_ImportStockServer) error {
+	opts := &pb.ImportStockOptions{}
+	var counts []models.StockCount
+	for first := true; ; first = false {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch p := req.Payload.(type) {
+		case *pb.ImportStockRequest_Options:
+			if !first {
+				return newStatus(codes.InvalidArgument, "OPTIONS_NOT_FIRST", "options must be the first message")
+			}
+			opts = p.Options
+		case *pb.ImportStockRequest_Count:
+			// Stop reading early rather than buffer an oversized import
+			if len(counts) == service.MaxImportSize {
+				return newStatus(codes.InvalidArgument, "IMPORT_TOO_LARGE", "too many counts in one import")
+			}
+			counts = append(counts, models.StockCount{
+				ProductID:    p.Count.ProductId,
+				LocationCode: p.Count.Location,
+				Counted:      p.Count.CountedQuantity,
+			})
+		}
+	}
+
+	results, err := h.svc.ImportStock(stream.Context(), counts, opts.Location, models.MovementInfo{
+		ReferenceID: opts.ReferenceId,
+		Actor:       opts.Actor,
+	}, opts.RequestId, opts.DryRun)
+	if err != nil && !errors.Is(err, repository.ErrAdjustmentRejected) {
+		return toStatus(err)
+	}
+
+	res := &pb.ImportStockResponse{Applied: err == nil && !opts.DryRun}
+	if err != nil {
+		res.Message = err.Error()
+	}
+	for _, r := range results {
+		if r.Delta != 0 {
+			res.Changed++
+		}
+		res.TotalDelta += int64(r.Delta)
+		res.Results = append(res.Results, &pb.StockCountResult{
+			ProductId:        r.ProductID,
+			Location:         r.LocationCode,
+			PreviousQuantity: r.Previous,
+			CountedQuantity:  r.Counted,
+			Delta:            r.Delta,
+			NewProduct:       r.NewProduct,
+			Reason:           r.Reason,
+			Message:          r.Message,
+		})
+	}
+	return stream.SendAndClose(res)
+}
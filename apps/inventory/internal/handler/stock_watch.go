@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	pb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
+)
+
+func (h *InventoryGrpcHandler) WatchStock(req *pb.WatchStockRequest, stream pb.InventoryService_WatchStockServer) error {
+	err := h.svc.WatchStock(stream.Context(), req.ProductIds, func(c models.StockChange) error {
+		res := &pb.StockChange{
+			ProductId:    c.ProductID,
+			Quantity:     c.Quantity,
+			ReorderPoint: c.ReorderPoint,
+			StockState:   models.StockStateOf(c.Quantity, c.ReorderPoint),
+		}
+		if !c.UpdatedAt.IsZero() {
+			res.UpdatedAt = c.UpdatedAt.UTC().Format(time.RFC3339)
+		}
+		return stream.Send(res)
+	})
+	return toStatus(err)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	pb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
+)
+
+func (h *InventoryGrpcHandler) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.StockTransfer, error) {
+	transfer := &models.StockTransfer{
+		FromLocation: req.FromLocation,
+		ToLocation:   req.ToLocation,
+		Note:         req.Note,
+		CreatedBy:    req.Actor,
+	}
+	for _, line := range req.Lines {
+		transfer.Lines = append(transfer.Lines, models.StockTransferLine{ProductID: line.ProductId, Quantity: line.Quantity})
+	}
+	if err := h.svc.CreateTransfer(ctx, transfer, req.RequestId); err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoTransfer(transfer), nil
+}
+
+func (h *InventoryGrpcHandler) GetTransfer(ctx context.Context, req *pb.GetTransferRequest) (*pb.StockTransfer, error) {
+	transfer, err := h.svc.GetTransfer(ctx, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoTransfer(transfer), nil
+}
+
+func (h *InventoryGrpcHandler) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	transfers, next, err := h.svc.ListTransfers(ctx, models.TransferQuery{
+		Status:    req.Status,
+		Location:  req.Location,
+		Limit:     int(req.Limit),
+		PageToken: req.PageToken,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &pb.ListTransfersResponse{NextPageToken: next}
+	for _, t := range transfers {
+		res.Transfers = append(res.Transfers, toProtoTransfer(t))
+	}
+	return res, nil
+}
+
+func (h *InventoryGrpcHandler) ShipTransfer(ctx context.Context, req *pb.ShipTransferRequest) (*pb.StockTransfer, error) {
+	transfer, err := h.svc.ShipTransfer(ctx, req.Id, req.Actor)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoTransfer(transfer), nil
+}
+
+func (h *InventoryGrpcHandler) ReceiveTransfer(ctx context.Context, req *pb.ReceiveTransferRequest) (*pb.StockTransfer, error) {
+	received := make([]models.ReceivedLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		received = append(received, models.ReceivedLine{ProductID: line.ProductId, Quantity: line.ReceivedQuantity, Note: line.Note})
+	}
+	transfer, err := h.svc.ReceiveTransfer(ctx, req.Id, received, req.Actor)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoTransfer(transfer), nil
+}
+
+func (h *InventoryGrpcHandler) CancelTransfer(ctx context.Context, req *pb.CancelTransferRequest) (*pb.StockTransfer, error) {
+	transfer, err := h.svc.CancelTransfer(ctx, req.Id, req.Actor)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProtoTransfer(transfer), nil
+}
+
+func toProtoTransfer(t *models.StockTransfer) *pb.StockTransfer {
+	res := &pb.StockTransfer{
+		Id:           t.ID,
+		FromLocation: t.FromLocation,
+		ToLocation:   t.ToLocation,
+		Status:       t.Status,
+		Note:         t.Note,
+		CreatedBy:    t.CreatedBy,
+		CreatedAt:    t.CreatedAt.UTC().Format(time.RFC3339),
+		ShippedAt:    formatOptionalTime(t.ShippedAt),
+		ReceivedAt:   formatOptionalTime(t.ReceivedAt),
+		CancelledAt:  formatOptionalTime(t.CancelledAt),
+	}
+	for _, line := range t.Lines {
+		pl := &pb.StockTransferLine{ProductId: line.ProductID, Quantity: line.Quantity, Note: line.Note}
+		if t.Status == models.TransferReceived {
+			pl.ReceivedQuantity = line.ReceivedQuantity
+			pl.Discrepancy = line.Discrepancy()
+		}
+		res.Lines = append(res.Lines, pl)
+	}
+	return res
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,37 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	productPb "github.com/thapakon-thai/eshop-microservices/proto/product"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ProductClient reads catalogue details from the product service.
+type ProductClient struct {
+	client productPb.ProductServiceClient
+}
+
+func NewProductClient(productUrl string) (*ProductClient, error) {
+	conn, err := grpc.Dial(productUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return &ProductClient{client: productPb.NewProductServiceClient(conn)}, nil
+}
+
+// ProductCosts fetches name and cost price for productIDs in one batch call.
+// Archived products are included; valuations still count their stock.
+func (c *ProductClient) ProductCosts(ctx context.Context, productIDs []string) (map[string]models.ProductCost, error) {
+	res, err := c.client.BatchGetProducts(ctx, &productPb.BatchGetProductsRequest{Ids: productIDs})
+	if err != nil {
+		return nil, err
+	}
+	costs := make(map[string]models.ProductCost, len(res.Products))
+	for _, p := range res.Products {
+		costs[p.Id] = models.ProductCost{Name: p.Name, CostPrice: p.CostPrice}
+	}
+	return costs, nil
+}
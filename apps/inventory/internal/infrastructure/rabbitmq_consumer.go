@@ -0,0 +1,110 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/service"
+)
+
+const (
+	productExchange = "product_events"
+	productQueue    = "inventory_product_events"
+)
+
+type ProductEventConsumer struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	svc     *service.InventoryService
+}
+
+func NewProductEventConsumer(url string, svc *service.InventoryService) (*ProductEventConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open a channel: %v", err)
+	}
+
+	c := &ProductEventConsumer{conn: conn, channel: ch, svc: svc}
+	if err := c.declare(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *ProductEventConsumer) declare() error {
+	if err := c.channel.ExchangeDeclare(productExchange, "topic", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare an exchange: %v", err)
+	}
+	if _, err := c.channel.QueueDeclare(productQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare a queue: %v", err)
+	}
+	if err := c.channel.QueueBind(productQueue, "product.*", productExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind a queue: %v", err)
+	}
+	return nil
+}
+
+// Start blocks, handling deliveries until the channel is closed.
+func (c *ProductEventConsumer) Start(ctx context.Context) error {
+	msgs, err := c.channel.Consume(productQueue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to register a consumer: %v", err)
+	}
+
+	slog.Info("Waiting for product events", "queue", productQueue)
+	for msg := range msgs {
+		if err := c.handle(ctx, msg.Body); err != nil {
+			// Retry once in case the failure was transient, then drop it so a
+			// poison message can't block the queue.
+			slog.Error("Failed to handle product event", "redelivered", msg.Redelivered, "error", err)
+			msg.Nack(false, !msg.Redelivered)
+			continue
+		}
+		msg.Ack(false)
+	}
+	return nil
+}
+
+func (c *ProductEventConsumer) handle(ctx context.Context, body []byte) error {
+	var event models.ProductEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return fmt.Errorf("invalid event payload: %v", err)
+	}
+	if event.Version > models.SupportedProductEventVersion {
+		return fmt.Errorf("unsupported %s version %d", event.Type, event.Version)
+	}
+	if event.Data.ProductID == "" {
+		return fmt.Errorf("%s %s has no product_id", event.Type, event.ID)
+	}
+
+	switch event.Type {
+	case models.ProductCreated:
+		if err := c.svc.InitializeStock(ctx, event.Data.ProductID, event.Data.Stock, event.ID); err != nil {
+			return err
+		}
+		return c.svc.SetAvailability(ctx, event.Data.ProductID, event.Data.Availability, event.Data.BackorderLimit, event.Data.ReleaseDate)
+	case models.ProductUpdated:
+		return c.svc.SetAvailability(ctx, event.Data.ProductID, event.Data.Availability, event.Data.BackorderLimit, event.Data.ReleaseDate)
+	case models.ProductDeleted:
+		return c.svc.ArchiveStock(ctx, event.Data.ProductID)
+	case models.ProductRestored:
+		return c.svc.RestoreStock(ctx, event.Data.ProductID)
+	}
+	return nil
+}
+
+func (c *ProductEventConsumer) Close() {
+	c.channel.Close()
+	c.conn.Close()
+}
@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/service"
+)
+
+const (
+	orderExchange = "order_events"
+	orderQueue    = "inventory_order_events"
+)
+
+// OrderEventConsumer counts the items of new orders into daily sales, the
+// history demand forecasts are made from.
+type OrderEventConsumer struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	svc     *service.InventoryService
+}
+
+func NewOrderEventConsumer(url string, svc *service.InventoryService) (*OrderEventConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open a channel: %v", err)
+	}
+
+	c := &OrderEventConsumer{conn: conn, channel: ch, svc: svc}
+	if err := c.declare(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *OrderEventConsumer) declare() error {
+	if err := c.channel.ExchangeDeclare(orderExchange, "topic", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare an exchange: %v", err)
+	}
+	if _, err := c.channel.QueueDeclare(orderQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare a queue: %v", err)
+	}
+	if err := c.channel.QueueBind(orderQueue, models.OrderCreated, orderExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind a queue: %v", err)
+	}
+	return nil
+}
+
+// Start blocks, handling deliveries until the channel is closed.
+func (c *OrderEventConsumer) Start(ctx context.Context) error {
+	msgs, err := c.channel.Consume(orderQueue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to register a consumer: %v", err)
+	}
+
+	slog.Info("Waiting for order events", "queue", orderQueue)
+	for msg := range msgs {
+		if err := c.handle(ctx, msg.Body); err != nil {
+			// Retry once in case the failure was transient, then drop it so a
+			// poison message can't block the queue.
+			slog.Error("Failed to handle order event", "redelivered", msg.Redelivered, "error", err)
+			msg.Nack(false, !msg.Redelivered)
+			continue
+		}
+		msg.Ack(false)
+	}
+	return nil
+}
+
+func (c *OrderEventConsumer) handle(ctx context.Context, body []byte) error {
+	var event models.OrderEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return fmt.Errorf("invalid event payload: %v", err)
+	}
+	return c.svc.RecordOrder(ctx, event)
+}
+
+func (c *OrderEventConsumer) Close() {
+	c.channel.Close()
+	c.conn.Close()
+}
@@ -0,0 +1,72 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+const inventoryExchange = "inventory_events"
+
+type EventPublisher struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+func NewEventPublisher(url string) (*EventPublisher, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open a channel: %v", err)
+	}
+
+	if err := ch.ExchangeDeclare(inventoryExchange, "topic", true, false, false, false, nil); err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare an exchange: %v", err)
+	}
+	return &EventPublisher{conn: conn, channel: ch}, nil
+}
+
+// PublishOutboxEvent sends an event saved in the outbox as it was saved.
+func (p *EventPublisher) PublishOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	if err := p.publishBody(ctx, event.EventID, event.Type, event.OccurredAt, event.Payload); err != nil {
+		return err
+	}
+	slog.Info("Published outbox event", "type", event.Type, "id", event.EventID)
+	return nil
+}
+
+// publishBody uses the event type as routing key, so consumers can bind to
+// "inventory.*" or a single event.
+func (p *EventPublisher) publishBody(ctx context.Context, id, eventType string, at time.Time, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := p.channel.PublishWithContext(ctx, inventoryExchange, eventType, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    id,
+		Type:         eventType,
+		Timestamp:    at,
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %v", err)
+	}
+	return nil
+}
+
+func (p *EventPublisher) Close() {
+	p.channel.Close()
+	p.conn.Close()
+}
@@ -0,0 +1,295 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/service"
+)
+
+// Each sale keeps five keys, in this order in every script's KEYS:
+//
+//	meta      hash of limit, ends_at and expire_at (ms), base, closed and
+//	          sealed
+//	stock     units left to sell
+//	users     hash of units bought by user, kept when the sale has a limit
+//	requests  hash of units taken by request id, or "refunded"
+//	log       list of JSON FlashSaleEntry, purchases and refunds in order
+//
+// The braces put all of a sale's keys in one Redis Cluster slot.
+func flashSaleKeys(id int64) []string {
+	prefix := fmt.Sprintf("flash-sale:{%d}:", id)
+	return []string{prefix + "meta", prefix + "stock", prefix + "users", prefix + "requests", prefix + "log"}
+}
+
+// flashSaleKeyTTL is how long a sale's keys outlive its end time in case
+// ending it never removes them.
+const flashSaleKeyTTL = 24 * time.Hour
+
+// ARGV: stock, per-user limit, end time and expiry time in ms, log base,
+// 1 when the sale was loaded before
+//
+// Returns 1 when loaded, 0 when loaded already and -1 when the counters of
+// a sale loaded before are gone.
+var loadScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+  return 0
+end
+if ARGV[6] == '1' then
+  return -1
+end
+redis.call('HSET', KEYS[1], 'limit', ARGV[2], 'ends_at', ARGV[3], 'expire_at', ARGV[4], 'base', ARGV[5], 'closed', '0', 'sealed', '0')
+redis.call('SET', KEYS[2], ARGV[1])
+redis.call('PEXPIREAT', KEYS[1], ARGV[4])
+redis.call('PEXPIREAT', KEYS[2], ARGV[4])
+return 1
+`)
+
+// KEYS: the five keys of each sale in turn
+// ARGV: request id, user, now in ms, then each sale's quantity and log entry
+//
+// Returns {0} when taken, otherwise {1 not selling | 2 sold out | 3 over
+// the limit, index of the sale, units left to sell or to buy}.
+var purchaseScript = redis.NewScript(`
+local request, user, now = ARGV[1], ARGV[2], tonumber(ARGV[3])
+local n = #KEYS / 5
+-- A retried request that already went through takes nothing more
+local prior = redis.call('HGET', KEYS[4], request)
+if prior and prior ~= 'refunded' then
+  return {0}
+end
+local limits = {}
+for i = 0, n - 1 do
+  local k, qty = i * 5, tonumber(ARGV[4 + i * 2])
+  local meta = redis.call('HMGET', KEYS[k + 1], 'closed', 'ends_at', 'limit')
+  if not meta[2] or meta[1] == '1' or now >= tonumber(meta[2]) then
+    return {1, i, 0}
+  end
+  local left = tonumber(redis.call('GET', KEYS[k + 2]) or '0')
+  if left < qty then
+    return {2, i, left}
+  end
+  limits[i] = tonumber(meta[3])
+  if limits[i] > 0 then
+    local bought = tonumber(redis.call('HGET', KEYS[k + 3], user) or '0')
+    if bought + qty > limits[i] then
+      return {3, i, limits[i] - bought}
+    end
+  end
+end
+for i = 0, n - 1 do
+  local k, qty = i * 5, ARGV[4 + i * 2]
+  redis.call('DECRBY', KEYS[k + 2], qty)
+  if limits[i] > 0 then
+    redis.call('HINCRBY', KEYS[k + 3], user, qty)
+  end
+  redis.call('HSET', KEYS[k + 4], request, qty)
+  redis.call('RPUSH', KEYS[k + 5], ARGV[5 + i * 2])
+  local expire = redis.call('HGET', KEYS[k + 1], 'expire_at')
+  for j = 3, 5 do
+    redis.call('PEXPIREAT', KEYS[k + j], expire)
+  end
+end
+return {0}
+`)
+
+// KEYS: the five keys of each sale in turn
+// ARGV: request id, user, then each sale's log entry
+//
+// Returns the indexes of the sales that are sealed or gone.
+var refundScript = redis.NewScript(`
+local request, user = ARGV[1], ARGV[2]
+local refused = {}
+for i = 0, #KEYS / 5 - 1 do
+  local k = i * 5
+  local qty = redis.call('HGET', KEYS[k + 4], request)
+  if qty == 'refunded' then
+    -- Refunded already
+  elseif redis.call('EXISTS', KEYS[k + 1]) == 0 or redis.call('HGET', KEYS[k + 1], 'sealed') == '1' then
+    table.insert(refused, i)
+  elseif qty then
+    redis.call('INCRBY', KEYS[k + 2], qty)
+    if tonumber(redis.call('HGET', KEYS[k + 1], 'limit') or '0') > 0 then
+      redis.call('HINCRBY', KEYS[k + 3], user, -tonumber(qty))
+    end
+    redis.call('HSET', KEYS[k + 4], request, 'refunded')
+    redis.call('RPUSH', KEYS[k + 5], ARGV[3 + i])
+  end
+end
+return refused
+`)
+
+var stopScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+  redis.call('HSET', KEYS[1], 'closed', '1')
+end
+return 0
+`)
+
+var sealScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+  redis.call('HSET', KEYS[1], 'closed', '1', 'sealed', '1')
+end
+return 0
+`)
+
+// ARGV: offset of the first entry, counted from the sale's first, and limit
+var entriesScript = redis.NewScript(`
+local offset = tonumber(ARGV[1])
+local from = offset - tonumber(redis.call('HGET', KEYS[1], 'base') or offset)
+if from < 0 then
+  from = 0
+end
+return redis.call('LRANGE', KEYS[5], from, from + tonumber(ARGV[2]) - 1)
+`)
+
+// RedisFlashSaleStore keeps flash sale counters in Redis. Purchases are
+// checked and taken by a Lua script, so a sale never sells more than it
+// holds or a user more than the limit, however many replicas sell at once.
+// The logs hold sales not yet in the ledger, so Redis must persist them
+// with the append-only file.
+type RedisFlashSaleStore struct {
+	client *redis.Client
+}
+
+func NewRedisFlashSaleStore(url string) (*RedisFlashSaleStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %v", err)
+	}
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %v", err)
+	}
+	config, err := client.ConfigGet(ctx, "appendonly").Result()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to read Redis persistence config: %v", err)
+	}
+	if config["appendonly"] != "yes" {
+		client.Close()
+		return nil, fmt.Errorf("redis must run with appendonly yes to keep flash sale logs")
+	}
+	return &RedisFlashSaleStore{client: client}, nil
+}
+
+func (s *RedisFlashSaleStore) Load(ctx context.Context, sale *models.FlashSale) error {
+	loaded, err := loadScript.Run(ctx, s.client, flashSaleKeys(sale.ID),
+		max(sale.Quantity-sale.Sold, 0),
+		sale.PerUserLimit,
+		sale.EndsAt.UnixMilli(),
+		sale.EndsAt.Add(flashSaleKeyTTL).UnixMilli(),
+		sale.Reconciled,
+		sale.Loaded,
+	).Int64()
+	if err != nil {
+		return err
+	}
+	if loaded < 0 {
+		return fmt.Errorf("%w: %d", service.ErrFlashSaleLost, sale.ID)
+	}
+	return nil
+}
+
+func (s *RedisFlashSaleStore) Purchase(ctx context.Context, requestID, user, referenceID string, items []models.FlashSaleItem) error {
+	now := time.Now()
+	keys := make([]string, 0, len(items)*5)
+	args := []any{requestID, user, now.UnixMilli()}
+	for _, item := range items {
+		entry, err := json.Marshal(models.FlashSaleEntry{
+			RequestID:   requestID,
+			UserID:      user,
+			ReferenceID: referenceID,
+			Quantity:    item.Quantity,
+			At:          now,
+		})
+		if err != nil {
+			return err
+		}
+		keys = append(keys, flashSaleKeys(item.Sale.ID)...)
+		args = append(args, item.Quantity, entry)
+	}
+
+	result, err := purchaseScript.Run(ctx, s.client, keys, args...).Int64Slice()
+	if err != nil {
+		return err
+	}
+	if result[0] == 0 {
+		return nil
+	}
+	if len(result) < 3 || result[1] < 0 || int(result[1]) >= len(items) {
+		return fmt.Errorf("unexpected flash sale purchase result %v", result)
+	}
+	rejected := &service.FlashSaleRejection{SaleID: items[result[1]].Sale.ID, Remaining: int32(result[2])}
+	switch result[0] {
+	case 1:
+		rejected.Reason = service.FlashSaleNotSelling
+	case 2:
+		rejected.Reason = service.FlashSaleSoldOut
+	default:
+		rejected.Reason = service.FlashSaleLimitReached
+	}
+	return rejected
+}
+
+func (s *RedisFlashSaleStore) Refund(ctx context.Context, requestID, user string, sales []*models.FlashSale) ([]int64, error) {
+	entry, err := json.Marshal(models.FlashSaleEntry{Refund: true, RequestID: requestID, UserID: user, At: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(sales)*5)
+	args := []any{requestID, user}
+	for _, sale := range sales {
+		keys = append(keys, flashSaleKeys(sale.ID)...)
+		args = append(args, entry)
+	}
+	refused, err := refundScript.Run(ctx, s.client, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(refused))
+	for _, i := range refused {
+		if i < 0 || int(i) >= len(sales) {
+			return nil, fmt.Errorf("unexpected flash sale refund result %v", refused)
+		}
+		ids = append(ids, sales[i].ID)
+	}
+	return ids, nil
+}
+
+func (s *RedisFlashSaleStore) Stop(ctx context.Context, saleID int64) error {
+	return stopScript.Run(ctx, s.client, flashSaleKeys(saleID)[:1]).Err()
+}
+
+func (s *RedisFlashSaleStore) Seal(ctx context.Context, saleID int64) error {
+	return sealScript.Run(ctx, s.client, flashSaleKeys(saleID)[:1]).Err()
+}
+
+func (s *RedisFlashSaleStore) Entries(ctx context.Context, saleID, offset int64, limit int) ([]models.FlashSaleEntry, error) {
+	raw, err := entriesScript.Run(ctx, s.client, flashSaleKeys(saleID), offset, limit).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]models.FlashSaleEntry, len(raw))
+	for i, r := range raw {
+		if err := json.Unmarshal([]byte(r), &entries[i]); err != nil {
+			return nil, fmt.Errorf("invalid flash sale log entry %q: %v", r, err)
+		}
+	}
+	return entries, nil
+}
+
+func (s *RedisFlashSaleStore) Remove(ctx context.Context, saleID int64) error {
+	return s.client.Del(ctx, flashSaleKeys(saleID)...).Err()
+}
+
+func (s *RedisFlashSaleStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,229 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/service"
+)
+
+func newTestStore(t *testing.T) (*RedisFlashSaleStore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	store := &RedisFlashSaleStore{client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+	t.Cleanup(func() { store.Close() })
+	return store, mr
+}
+
+func loadTestSale(t *testing.T, store *RedisFlashSaleStore, id int64, quantity, limit int32) *models.FlashSale {
+	t.Helper()
+	sale := &models.FlashSale{ID: id, ProductID: "p1", Quantity: quantity, PerUserLimit: limit, EndsAt: time.Now().Add(time.Hour)}
+	if err := store.Load(context.Background(), sale); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return sale
+}
+
+func wantRejection(t *testing.T, err error, reason string, remaining int32) {
+	t.Helper()
+	var rejected *service.FlashSaleRejection
+	if !errors.As(err, &rejected) {
+		t.Fatalf("err = %v, want %s rejection", err, reason)
+	}
+	if rejected.Reason != reason || rejected.Remaining != remaining {
+		t.Fatalf("rejection = %+v, want %s with %d remaining", rejected, reason, remaining)
+	}
+}
+
+func TestRedisFlashSalePurchase(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+	sale := loadTestSale(t, store, 1, 5, 0)
+
+	if err := store.Purchase(ctx, "r1", "u1", "o1", []models.FlashSaleItem{{Sale: sale, Quantity: 3}}); err != nil {
+		t.Fatalf("purchase: %v", err)
+	}
+	err := store.Purchase(ctx, "r2", "u2", "o2", []models.FlashSaleItem{{Sale: sale, Quantity: 3}})
+	wantRejection(t, err, service.FlashSaleSoldOut, 2)
+	if err := store.Purchase(ctx, "r3", "u2", "o3", []models.FlashSaleItem{{Sale: sale, Quantity: 2}}); err != nil {
+		t.Fatalf("purchase the rest: %v", err)
+	}
+
+	entries, err := store.Entries(ctx, sale.ID, 0, 10)
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if len(entries) != 2 || entries[0].RequestID != "r1" || entries[0].Quantity != 3 || entries[1].RequestID != "r3" {
+		t.Fatalf("entries = %+v, want r1 for 3 then r3", entries)
+	}
+}
+
+func TestRedisFlashSalePurchaseTakesAllOrNothing(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+	plenty := loadTestSale(t, store, 1, 10, 0)
+	scarce := loadTestSale(t, store, 2, 1, 0)
+
+	err := store.Purchase(ctx, "r1", "u1", "o1", []models.FlashSaleItem{{Sale: plenty, Quantity: 4}, {Sale: scarce, Quantity: 2}})
+	wantRejection(t, err, service.FlashSaleSoldOut, 1)
+	entries, err := store.Entries(ctx, plenty.ID, 0, 10)
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("entries = %+v, want none after a rejected purchase", entries)
+	}
+}
+
+func TestRedisFlashSaleRetry(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+	sale := loadTestSale(t, store, 1, 5, 0)
+
+	for range 2 {
+		if err := store.Purchase(ctx, "r1", "u1", "o1", []models.FlashSaleItem{{Sale: sale, Quantity: 3}}); err != nil {
+			t.Fatalf("purchase: %v", err)
+		}
+	}
+	err := store.Purchase(ctx, "r2", "u2", "o2", []models.FlashSaleItem{{Sale: sale, Quantity: 3}})
+	wantRejection(t, err, service.FlashSaleSoldOut, 2)
+}
+
+func TestRedisFlashSaleLimit(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+	sale := loadTestSale(t, store, 1, 10, 3)
+
+	if err := store.Purchase(ctx, "r1", "u1", "o1", []models.FlashSaleItem{{Sale: sale, Quantity: 2}}); err != nil {
+		t.Fatalf("purchase: %v", err)
+	}
+	err := store.Purchase(ctx, "r2", "u1", "o2", []models.FlashSaleItem{{Sale: sale, Quantity: 2}})
+	wantRejection(t, err, service.FlashSaleLimitReached, 1)
+	if err := store.Purchase(ctx, "r3", "u2", "o3", []models.FlashSaleItem{{Sale: sale, Quantity: 3}}); err != nil {
+		t.Fatalf("another user: %v", err)
+	}
+}
+
+func TestRedisFlashSaleRefund(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+	sale := loadTestSale(t, store, 1, 3, 3)
+
+	if err := store.Purchase(ctx, "r1", "u1", "o1", []models.FlashSaleItem{{Sale: sale, Quantity: 3}}); err != nil {
+		t.Fatalf("purchase: %v", err)
+	}
+	for range 2 {
+		sealed, err := store.Refund(ctx, "r1", "u1", []*models.FlashSale{sale})
+		if err != nil {
+			t.Fatalf("refund: %v", err)
+		}
+		if len(sealed) != 0 {
+			t.Fatalf("sealed = %v, want none", sealed)
+		}
+	}
+	// The stock and the user's limit are back, once
+	if err := store.Purchase(ctx, "r2", "u1", "o2", []models.FlashSaleItem{{Sale: sale, Quantity: 3}}); err != nil {
+		t.Fatalf("purchase after refund: %v", err)
+	}
+
+	entries, err := store.Entries(ctx, sale.ID, 0, 10)
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if len(entries) != 3 || !entries[1].Refund || entries[1].RequestID != "r1" {
+		t.Fatalf("entries = %+v, want purchase, one refund, purchase", entries)
+	}
+}
+
+func TestRedisFlashSaleStopAndSeal(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+	sale := loadTestSale(t, store, 1, 5, 0)
+	if err := store.Purchase(ctx, "r1", "u1", "o1", []models.FlashSaleItem{{Sale: sale, Quantity: 1}}); err != nil {
+		t.Fatalf("purchase: %v", err)
+	}
+	if err := store.Purchase(ctx, "r2", "u1", "o2", []models.FlashSaleItem{{Sale: sale, Quantity: 1}}); err != nil {
+		t.Fatalf("purchase: %v", err)
+	}
+
+	if err := store.Stop(ctx, sale.ID); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	err := store.Purchase(ctx, "r3", "u1", "o3", []models.FlashSaleItem{{Sale: sale, Quantity: 1}})
+	wantRejection(t, err, service.FlashSaleNotSelling, 0)
+	// Stopped sales still log refunds
+	if sealed, err := store.Refund(ctx, "r1", "u1", []*models.FlashSale{sale}); err != nil || len(sealed) != 0 {
+		t.Fatalf("refund after stop: sealed = %v, err = %v", sealed, err)
+	}
+
+	if err := store.Seal(ctx, sale.ID); err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	sealed, err := store.Refund(ctx, "r2", "u1", []*models.FlashSale{sale})
+	if err != nil {
+		t.Fatalf("refund after seal: %v", err)
+	}
+	if !slices.Equal(sealed, []int64{sale.ID}) {
+		t.Fatalf("sealed = %v, want [%d]", sealed, sale.ID)
+	}
+	if sealed, err := store.Refund(ctx, "r1", "u1", []*models.FlashSale{sale}); err != nil || len(sealed) != 0 {
+		t.Fatalf("refund again after seal: sealed = %v, err = %v, want nothing to do", sealed, err)
+	}
+	entries, err := store.Entries(ctx, sale.ID, 0, 10)
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("entries = %+v, want two purchases and one refund", entries)
+	}
+}
+
+func TestRedisFlashSaleEntriesFromBase(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+	sale := &models.FlashSale{ID: 1, Quantity: 5, Reconciled: 4, EndsAt: time.Now().Add(time.Hour)}
+	if err := store.Load(ctx, sale); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := store.Purchase(ctx, "r1", "u1", "o1", []models.FlashSaleItem{{Sale: sale, Quantity: 1}}); err != nil {
+		t.Fatalf("purchase: %v", err)
+	}
+	entries, err := store.Entries(ctx, sale.ID, 4, 10)
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].RequestID != "r1" {
+		t.Fatalf("entries = %+v, want r1 at offset 4", entries)
+	}
+	if entries, err := store.Entries(ctx, sale.ID, 5, 10); err != nil || len(entries) != 0 {
+		t.Fatalf("entries past the end = %+v, err = %v", entries, err)
+	}
+}
+
+func TestRedisFlashSaleLoadLost(t *testing.T) {
+	store, mr := newTestStore(t)
+	ctx := context.Background()
+	sale := loadTestSale(t, store, 1, 5, 0)
+	sale.Loaded = true
+	if err := store.Load(ctx, sale); err != nil {
+		t.Fatalf("load again: %v", err)
+	}
+
+	mr.FlushAll()
+	if err := store.Load(ctx, sale); !errors.Is(err, service.ErrFlashSaleLost) {
+		t.Fatalf("reload lost sale: err = %v, want ErrFlashSaleLost", err)
+	}
+	sealed, err := store.Refund(ctx, "r1", "u1", []*models.FlashSale{sale})
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if !slices.Equal(sealed, []int64{sale.ID}) {
+		t.Fatalf("sealed = %v, want the lost sale refunded in the ledger", sealed)
+	}
+}
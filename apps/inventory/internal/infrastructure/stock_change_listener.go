@@ -0,0 +1,78 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/service"
+)
+
+// StockChangeListener feeds stock change notifications from Postgres to the
+// service's stock watchers, so changes made by any replica reach them.
+type StockChangeListener struct {
+	dsn string
+	svc *service.InventoryService
+}
+
+func NewStockChangeListener(dsn string, svc *service.InventoryService) *StockChangeListener {
+	return &StockChangeListener{dsn: dsn, svc: svc}
+}
+
+// Run blocks until ctx is cancelled, reconnecting with backoff when the
+// connection drops.
+func (l *StockChangeListener) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		slog.Error("Stock change listener disconnected", "retry_in", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+func (l *StockChangeListener) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %v", err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+models.StockChangeChannel); err != nil {
+		return fmt.Errorf("failed to listen: %v", err)
+	}
+	slog.Info("Listening for stock changes", "channel", models.StockChangeChannel)
+	// Changes made while we weren't listening were missed
+	l.svc.ResyncStockWatchers(ctx)
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var change models.StockChange
+		if err := json.Unmarshal([]byte(n.Payload), &change); err != nil {
+			slog.Error("Failed to decode stock change", "payload", n.Payload, "error", err)
+			continue
+		}
+		// Reconnecting resyncs the watchers, so nothing stays missed
+		if err := l.svc.PublishStockChange(ctx, change); err != nil {
+			return fmt.Errorf("failed to publish stock change: %v", err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Availability values, as set on the product. Anything else means only stock
+// in hand can be sold.
+const (
+	AvailabilityBackorder = "backorder"
+	AvailabilityPreorder  = "preorder" // Oversold only until the release date
+)
+
+const (
+	BackorderOpen   = "open"
+	BackorderFilled = "filled"
+	// Its reservation was released; see ReleaseStock
+	BackorderCancelled = "cancelled"
+)
+
+// Backorder is stock sold before it arrived. Open backorders of a product are
+// filled whole, oldest first, as soon as enough stock is in hand.
+type Backorder struct {
+	ID          uint   `gorm:"primaryKey"`
+	ProductID   string `gorm:"index:idx_backorders_product_status"`
+	Status      string `gorm:"index:idx_backorders_product_status"`
+	ReferenceID string `gorm:"index"` // As given to ReserveStock, e.g. the order id
+	RequestID   string `gorm:"index"` // Of the ReserveStock call that opened it
+	Quantity    int32
+	Actor       string
+	CreatedAt   time.Time
+	FilledAt    *time.Time
+	Allocations []Allocation `gorm:"serializer:json"` // Where a filled backorder's stock was taken
+}
+
+// BackorderFill is a backorder that was just filled and where its stock was
+// taken.
+type BackorderFill struct {
+	Backorder   Backorder
+	Allocations []Allocation
+}
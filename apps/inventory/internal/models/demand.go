@@ -0,0 +1,111 @@
+package models
+
+import "time"
+
+// OrderEvent mirrors the order.created event the order service publishes
+// on the "order_events" exchange. Only the fields inventory needs are
+// decoded.
+type OrderEvent struct {
+	OrderID   int64      `json:"order_id"`
+	CreatedAt *time.Time `json:"created_at"` // Missing from events published before it was added
+	Items     []struct {
+		ProductID string `json:"product_id"`
+		Quantity  int32  `json:"quantity"`
+	} `json:"items"`
+}
+
+const OrderCreated = "order.created"
+
+// DailySales is how much of a product was ordered on one UTC day.
+type DailySales struct {
+	ProductID string    `gorm:"primaryKey"`
+	Day       time.Time `gorm:"primaryKey;type:date"`
+	Quantity  int32     `gorm:"not null"`
+}
+
+// RecordedOrder marks an order already counted in DailySales, so a
+// redelivered event isn't counted twice.
+type RecordedOrder struct {
+	OrderID    int64     `gorm:"primaryKey;autoIncrement:false"`
+	RecordedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// Forecast methods
+const (
+	ForecastMovingAverage        = "moving_average"
+	ForecastExponentialSmoothing = "exponential_smoothing"
+)
+
+type ForecastQuery struct {
+	ProductIDs []string // Empty for every product sold in the window
+	Method     string
+	WindowDays int
+	OnlyNeeded bool
+}
+
+// ProductSupplier is the supplier of a product's latest purchase order.
+type ProductSupplier struct {
+	ProductID    string
+	SupplierCode string
+	LeadTimeDays int32
+}
+
+// ReorderSuggestion is how much of a product to buy, from its forecast
+// demand and what is in hand or on the way.
+type ReorderSuggestion struct {
+	ProductID    string
+	DailyDemand  float64
+	LeadTimeDays int32
+	SupplierCode string
+	SafetyStock  int32
+	ReorderPoint int32 // Demand over the lead time plus SafetyStock
+	OnHand       int32
+	Incoming     int32
+	Backordered  int32
+	Suggested    int32 // 0 while the stock position is above ReorderPoint
+}
+
+// Position is the stock available to meet future demand.
+func (s ReorderSuggestion) Position() int32 { return s.OnHand + s.Incoming - s.Backordered }
+
+// ReorderReportEvent is published weekly, through the outbox, on the
+// "inventory_events" exchange with the products that need ordering.
+type ReorderReportEvent struct {
+	ID         string             `json:"id"`
+	Type       string             `json:"type"`
+	Version    int                `json:"version"`
+	OccurredAt time.Time          `json:"occurred_at"`
+	Data       *ReorderReportData `json:"data"`
+}
+
+type ReorderReportData struct {
+	Method      string                 `json:"method"`
+	WindowDays  int                    `json:"window_days"`
+	Suggestions []ReorderSuggestionRow `json:"suggestions"`
+}
+
+type ReorderSuggestionRow struct {
+	ProductID         string  `json:"product_id"`
+	SupplierCode      string  `json:"supplier_code,omitempty"`
+	DailyDemand       float64 `json:"daily_demand"`
+	ReorderPoint      int32   `json:"reorder_point"`
+	Position          int32   `json:"position"` // On hand plus incoming, less backordered
+	SuggestedQuantity int32   `json:"suggested_quantity"`
+}
+
+const (
+	ReorderReport = "inventory.reorder_report"
+
+	// ReorderReportVersion is bumped on breaking changes to ReorderReportData.
+	ReorderReportVersion = 1
+)
+
+// ScheduledRun is when a periodic job last ran. Keeping it in the database
+// holds the job to wall-clock time across restarts, and lets only one
+// replica claim each run.
+type ScheduledRun struct {
+	Job       string    `gorm:"primaryKey"`
+	LastRunAt time.Time `gorm:"not null"`
+}
+
+const JobReorderReport = "reorder-report"
@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// ProductEvent mirrors the envelope the product service publishes on the
+// "product_events" exchange. Only the fields inventory needs are decoded.
+type ProductEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+	Data    struct {
+		ProductID string `json:"product_id"`
+		Stock     int32  `json:"stock"`
+
+		Availability   string     `json:"availability"`
+		ReleaseDate    *time.Time `json:"release_date"`
+		BackorderLimit int32      `json:"backorder_limit"`
+	} `json:"data"`
+}
+
+const (
+	ProductCreated  = "product.created"
+	ProductUpdated  = "product.updated"
+	ProductDeleted  = "product.deleted"
+	ProductRestored = "product.restored"
+
+	// SupportedProductEventVersion is the newest schema this service can read.
+	SupportedProductEventVersion = 1
+)
+
+// StockEvent is published on the "inventory_events" exchange with its Type
+// as routing key.
+type StockEvent struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       *StockEventData `json:"data"`
+}
+
+type StockEventData struct {
+	ProductID    string `json:"product_id"`
+	Quantity     int32  `json:"quantity"` // Total over all locations
+	ReorderPoint int32  `json:"reorder_point"`
+}
+
+const (
+	StockLow    = "inventory.low_stock"
+	StockOut    = "inventory.out_of_stock"
+	StockBackIn = "inventory.back_in_stock"
+
+	// StockEventVersion is bumped on breaking changes to StockEventData.
+	StockEventVersion = 1
+)
+
+// BackorderEvent is published on the "inventory_events" exchange when a
+// backorder is filled, so the order waiting for it can go ahead.
+type BackorderEvent struct {
+	ID         string              `json:"id"`
+	Type       string              `json:"type"`
+	Version    int                 `json:"version"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	Data       *BackorderEventData `json:"data"`
+}
+
+type BackorderEventData struct {
+	ReferenceID string                `json:"reference_id"`
+	ProductID   string                `json:"product_id"`
+	Quantity    int32                 `json:"quantity"`
+	Allocations []BackorderAllocation `json:"allocations"`
+}
+
+// BackorderAllocation is where part of a filled backorder ships from.
+type BackorderAllocation struct {
+	Location string `json:"location"`
+	Quantity int32  `json:"quantity"`
+}
+
+const (
+	BackorderFilledEvent = "inventory.backorder_filled"
+
+	// BackorderEventVersion is bumped on breaking changes to BackorderEventData.
+	BackorderEventVersion = 1
+)
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FlashSale sells a fixed quantity of one product for a limited time. While
+// it runs, the quantity sits at a hold location of its own and is sold from
+// a counter in Redis; sales are reconciled back into the ledger afterwards.
+type FlashSale struct {
+	ID           int64     `gorm:"primaryKey"`
+	ProductID    string    `gorm:"index;not null"`
+	LocationCode string    `gorm:"not null"` // Where the stock is held from and returns to
+	Quantity     int32     `gorm:"not null"`
+	PerUserLimit int32     `gorm:"not null;default:0"` // 0 for no limit
+	StartsAt     time.Time `gorm:"not null"`
+	EndsAt       time.Time `gorm:"not null"`
+	Status       string    `gorm:"index;not null"`
+	Sold         int32     `gorm:"not null;default:0"`     // Reconciled into the ledger so far
+	Reconciled   int64     `gorm:"not null;default:0"`     // Entries of the Redis log applied
+	Loaded       bool      `gorm:"not null;default:false"` // Counters were set up in Redis
+	CreatedBy    string    `gorm:"not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	StartedAt    *time.Time
+	EndedAt      *time.Time
+}
+
+const (
+	FlashSaleScheduled = "scheduled"
+	FlashSaleActive    = "active"
+	FlashSaleEnded     = "ended"
+)
+
+const flashSaleHoldPrefix = "flash-sale-"
+
+// HoldLocation is where the sale's stock is kept while it runs. It isn't a
+// registered location, so nothing else allocates from it.
+func (f *FlashSale) HoldLocation() string {
+	return flashSaleHoldPrefix + strconv.FormatInt(f.ID, 10)
+}
+
+// IsFlashSaleHold reports whether code is a flash sale's hold location.
+func IsFlashSaleHold(code string) bool { return strings.HasPrefix(code, flashSaleHoldPrefix) }
+
+// FlashSalePurchase is one reservation's share of a flash sale, as
+// reconciled from Redis.
+type FlashSalePurchase struct {
+	ID          int64  `gorm:"primaryKey"`
+	FlashSaleID int64  `gorm:"uniqueIndex:idx_flash_sale_purchases_request,priority:1;not null"`
+	RequestID   string `gorm:"uniqueIndex:idx_flash_sale_purchases_request,priority:2;not null"`
+	UserID      string `gorm:"index;not null"`
+	ReferenceID string
+	Quantity    int32        `gorm:"not null"`
+	Refunded    bool         `gorm:"not null;default:false"`
+	Lots        []LotPortion `gorm:"serializer:json"` // Lots the stock was taken out of
+	PurchasedAt time.Time    `gorm:"not null"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time
+}
+
+// FlashSaleEntry is a purchase, or the refund of one, logged in Redis and
+// waiting to be reconciled.
+type FlashSaleEntry struct {
+	Refund      bool      `json:"refund,omitempty"`
+	RequestID   string    `json:"request_id"`
+	UserID      string    `json:"user_id"`
+	ReferenceID string    `json:"reference_id,omitempty"`
+	Quantity    int32     `json:"quantity"`
+	At          time.Time `json:"at"`
+}
+
+// FlashSaleItem is one campaign's part of a purchase.
+type FlashSaleItem struct {
+	Sale     *FlashSale
+	Quantity int32
+}
+
+// FlashSaleQuery filters ListFlashSales; empty fields match everything.
+type FlashSaleQuery struct {
+	Status    string
+	ProductID string
+	RequestID string // Sales with a purchase by the request, not refunded
+}
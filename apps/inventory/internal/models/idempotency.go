@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// IdempotencyKey remembers the result of a mutation made with a caller
+// supplied request id, so a retried call can be answered without applying the
+// change twice. It is written in the same transaction as the mutation.
+type IdempotencyKey struct {
+	RequestID   string    `gorm:"primaryKey"`
+	Method      string    `gorm:"not null"`
+	RequestHash string    `gorm:"not null"` // Detects a request id reused with different arguments
+	Response    []byte    // JSON of the original result
+	CreatedAt   time.Time `gorm:"autoCreateTime;index"`
+}
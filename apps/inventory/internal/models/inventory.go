@@ -1,9 +1,58 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Inventory struct {
 	gorm.Model
 	ProductID string `gorm:"uniqueIndex"`
-	Quantity  int32
+	Quantity  int32  `gorm:"check:chk_inventories_quantity_non_negative,quantity >= 0"`
+
+	// ReorderPoint is the quantity at or below which stock counts as low;
+	// 0 turns low-stock alerts off for the product.
+	ReorderPoint int32  `gorm:"not null;default:0"`
+	StockState   string `gorm:"not null;default:''"` // Last evaluated StockState*; empty until first evaluated
+
+	// How far the product may be oversold, copied from the product service
+	Availability   string `gorm:"not null;default:''"` // Availability*; empty means in stock only
+	BackorderLimit int32  `gorm:"not null;default:0"`
+	ReleaseDate    *time.Time
+	// Units owed to open backorders. Stock in hand is held for them, so
+	// what is free to sell is Quantity - Backordered, down to -BackorderLimit.
+	Backordered int32 `gorm:"not null;default:0;check:chk_inventories_backordered_non_negative,backordered >= 0"`
+}
+
+// CanBackorder reports whether quantity more units may be owed to
+// backorders at now, on top of the stock in hand.
+func (inv *Inventory) CanBackorder(quantity int32, now time.Time) bool {
+	switch inv.Availability {
+	case AvailabilityBackorder:
+	case AvailabilityPreorder:
+		if inv.ReleaseDate == nil || !now.Before(*inv.ReleaseDate) {
+			return false
+		}
+	default:
+		return false
+	}
+	return inv.Backordered+quantity-inv.Quantity <= inv.BackorderLimit
+}
+
+type StockAdjustment struct {
+	ProductID    string
+	LocationCode string
+	Change       int32
+}
+
+type StockAdjustmentResult struct {
+	ProductID   string
+	Change      int32
+	NewQuantity int32
+	Message     string // Empty when the line could be applied
+	Reason      string // Machine-readable form of Message
+
+	LocationCode     string
+	LocationQuantity int32
 }
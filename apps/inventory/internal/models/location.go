@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// Location is a warehouse or store that holds stock.
+type Location struct {
+	ID          uint   `gorm:"primaryKey"`
+	Code        string `gorm:"uniqueIndex;not null"`
+	Name        string `gorm:"not null"`
+	Postcode    string
+	ServiceArea []string `gorm:"serializer:json"`    // Postcode prefixes this location ships to first
+	Priority    int32    `gorm:"not null;default:0"` // Lower wins when locations are otherwise equal
+	Active      bool     `gorm:"not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// LocationStock is the quantity of a product held at one location.
+// Inventory.Quantity is kept equal to the sum over all locations.
+type LocationStock struct {
+	ID           uint   `gorm:"primaryKey"`
+	ProductID    string `gorm:"uniqueIndex:idx_location_stocks_product_location,priority:1;not null"`
+	LocationCode string `gorm:"uniqueIndex:idx_location_stocks_product_location,priority:2;not null"`
+	Quantity     int32  `gorm:"not null;check:chk_location_stocks_quantity_non_negative,quantity >= 0"`
+	UpdatedAt    time.Time
+}
+
+const (
+	StrategyNearest      = "nearest"
+	StrategyFewestSplits = "fewest_splits"
+)
+
+type AllocationItem struct {
+	ProductID string
+	Quantity  int32
+}
+
+// Allocation takes Quantity of a product from one location.
+type Allocation struct {
+	ProductID    string
+	LocationCode string
+	Quantity     int32
+	Lots         []LotPortion `json:",omitempty"` // Lots the stock was taken out of, once taken
+}
+
+type Shortfall struct {
+	ProductID string
+	Missing   int32
+}
+
+// BackorderLine is the part of an item that waits for stock to arrive.
+type BackorderLine struct {
+	ProductID string
+	Quantity  int32
+}
+
+// AllocationPlan says where each requested item ships from. It is only
+// fulfillable when Shortfalls is empty.
+type AllocationPlan struct {
+	Allocations []Allocation
+	Shortfalls  []Shortfall
+	Backorders  []BackorderLine // Set by ReserveStock when backorders are allowed
+}
+
+func (p *AllocationPlan) Fulfillable() bool { return len(p.Shortfalls) == 0 }
+
+// AllocationRequest asks where items should ship from. Strategy is one of
+// the Strategy constants.
+type AllocationRequest struct {
+	Items    []AllocationItem
+	Postcode string
+	Strategy string
+	// Turn shortfalls into backorders where the product's availability allows
+	AllowBackorder bool
+}
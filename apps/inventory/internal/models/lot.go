@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Lot is stock of a product at one location received under one lot (batch)
+// code, sharing an expiry date. Stock received without a lot code is
+// unlotted: a location's quantity is its active lots plus that remainder.
+type Lot struct {
+	ID           uint      `gorm:"primaryKey"`
+	ProductID    string    `gorm:"not null;uniqueIndex:idx_lots_product_location_code,priority:1"`
+	LocationCode string    `gorm:"not null;uniqueIndex:idx_lots_product_location_code,priority:2"`
+	Code         string    `gorm:"not null;uniqueIndex:idx_lots_product_location_code,priority:3"`
+	ExpiresOn    time.Time `gorm:"type:date;not null;index"` // Last day the lot can be sold
+	// Left in an active lot; for an expired lot, what was written off
+	Quantity  int32  `gorm:"not null;check:chk_lots_quantity_non_negative,quantity >= 0"`
+	Status    string `gorm:"not null;index"`
+	ExpiredAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+const (
+	LotActive  = "active"
+	LotExpired = "expired"
+)
+
+// Expired reports whether the lot is past its expiry date on day, a UTC
+// midnight.
+func (l *Lot) Expired(day time.Time) bool { return l.ExpiresOn.Before(day) }
+
+// LotInfo names the lot stock arrives in.
+type LotInfo struct {
+	Code      string
+	ExpiresOn time.Time
+}
+
+// LotPortion is part of some stock that was taken out of one lot, so that
+// wherever the stock arrives it goes back into a lot of that code and expiry.
+type LotPortion struct {
+	Code      string
+	ExpiresOn time.Time
+	Quantity  int32
+}
+
+type LotQuery struct {
+	ProductID      string
+	LocationCode   string // Empty for every location
+	IncludeExpired bool
+	ExpiresBefore  *time.Time // Only active lots expiring before it, soonest first
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is an event saved in the same transaction as the change it
+// announces and published from the table afterwards. An event is never lost
+// because the broker was down when the change committed, and never sent for
+// a change that rolled back. Rows are deleted once published.
+type OutboxEvent struct {
+	ID         int64     `gorm:"primaryKey"` // Publishing order
+	EventID    string    `gorm:"not null"`
+	Type       string    `gorm:"not null"` // Routing key
+	OccurredAt time.Time `gorm:"not null"`
+	Payload    []byte    `gorm:"not null"` // JSON of the whole event
+}
+
+func NewOutboxEvent(eventID, eventType string, at time.Time, event any) (*OutboxEvent, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxEvent{EventID: eventID, Type: eventType, OccurredAt: at, Payload: payload}, nil
+}
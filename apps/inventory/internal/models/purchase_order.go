@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// Supplier is a company stock is bought from.
+type Supplier struct {
+	ID           uint   `gorm:"primaryKey"`
+	Code         string `gorm:"uniqueIndex;not null"`
+	Name         string `gorm:"not null"`
+	Email        string
+	Phone        string
+	LeadTimeDays int32 `gorm:"not null;default:0"` // Typical days from ordering to delivery
+	Active       bool  `gorm:"not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// PurchaseOrder is stock ordered from a supplier for delivery to one
+// location. It may arrive over several deliveries; each adds to stock as it
+// is received.
+type PurchaseOrder struct {
+	ID           int64  `gorm:"primaryKey"`
+	SupplierCode string `gorm:"index;not null"`
+	LocationCode string `gorm:"not null"`
+	Status       string `gorm:"index;not null"`
+	ExpectedAt   *time.Time
+	Note         string
+	CreatedBy    string              `gorm:"not null"`
+	Lines        []PurchaseOrderLine `gorm:"foreignKey:PurchaseOrderID"`
+	CreatedAt    time.Time           `gorm:"autoCreateTime"`
+	ReceivedAt   *time.Time
+	CancelledAt  *time.Time
+}
+
+// TotalCost is what the ordered quantities cost.
+func (po *PurchaseOrder) TotalCost() float64 {
+	var total float64
+	for _, line := range po.Lines {
+		total += float64(line.Quantity) * line.UnitCost
+	}
+	return total
+}
+
+type PurchaseOrderLine struct {
+	ID               int64  `gorm:"primaryKey"`
+	PurchaseOrderID  int64  `gorm:"index;not null"`
+	ProductID        string `gorm:"index;not null"`
+	Quantity         int32  `gorm:"not null"`
+	UnitCost         float64
+	ReceivedQuantity int32 `gorm:"not null;default:0"`
+}
+
+// Outstanding is how much of the line is still expected.
+func (l PurchaseOrderLine) Outstanding() int32 { return max(l.Quantity-l.ReceivedQuantity, 0) }
+
+const (
+	PurchaseOrderOpen              = "open"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderCancelled         = "cancelled"
+)
+
+// Delivery is how much of one product arrived for a purchase order.
+type Delivery struct {
+	ProductID string
+	Quantity  int32
+	Lot       *LotInfo // nil for unlotted stock
+}
+
+type PurchaseOrderQuery struct {
+	Status       string
+	SupplierCode string
+	Limit        int
+	PageToken    string
+	BeforeID     int64 // Decoded from PageToken; 0 starts at the newest
+}
+
+// IncomingStock is what open purchase orders still bring of a product.
+type IncomingStock struct {
+	ProductID      string
+	Quantity       int32
+	NextExpectedAt *time.Time
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Filters accepted by InventoryQuery.Filter.
+const (
+	FilterBelowThreshold = "below_threshold" // At or below a non-zero reorder point
+	FilterZeroStock      = "zero_stock"
+)
+
+// Sort keys accepted by InventoryQuery.Sort; every sort is tie-broken by id.
+const (
+	SortProductID = "product_id"
+	SortQuantity  = "quantity"
+	SortUpdatedAt = "updated_at"
+)
+
+type InventoryQuery struct {
+	Filter       string
+	UpdatedSince time.Time // Zero for no bound
+	Sort         string
+	Descending   bool
+	Limit        int
+	PageToken    string
+	WithArchived bool // Include archived products' rows, whose stock still has a value
+
+	// Decoded from PageToken: the sort value and id of the last row seen
+	AfterValue any
+	AfterID    uint
+}
+
+// ProductCost is the catalogue data a valuation needs for one product.
+type ProductCost struct {
+	Name      string
+	CostPrice float64
+}
+
+type ValuationLine struct {
+	ProductID string
+	Name      string
+	Quantity  int32
+	CostPrice float64
+	Value     float64 // Quantity * CostPrice
+	Priced    bool    // False when the product service had no cost for it
+}
+
+type ValuationReport struct {
+	Lines            []ValuationLine
+	TotalQuantity    int64
+	TotalValue       float64
+	UnpricedProducts int
+	GeneratedAt      time.Time
+}
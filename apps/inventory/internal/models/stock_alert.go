@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const (
+	StockStateOK  = "ok"
+	StockStateLow = "low"
+	StockStateOut = "out"
+)
+
+// StockStateOf classifies a quantity against a reorder point.
+func StockStateOf(quantity, reorderPoint int32) string {
+	switch {
+	case quantity <= 0:
+		return StockStateOut
+	case quantity <= reorderPoint:
+		return StockStateLow
+	}
+	return StockStateOK
+}
+
+// StockAlertLog remembers when each kind of alert was last published for a
+// product, so a quantity flapping around a threshold is only reported once
+// per debounce window.
+type StockAlertLog struct {
+	ProductID  string    `gorm:"primaryKey"`
+	Type       string    `gorm:"primaryKey"`
+	LastSentAt time.Time `gorm:"not null"`
+}
+
+// StockAlert is a threshold crossing that should be published.
+type StockAlert struct {
+	Type         string
+	ProductID    string
+	Quantity     int32
+	ReorderPoint int32
+}
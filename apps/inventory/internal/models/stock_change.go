@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// StockChangeChannel is the Postgres NOTIFY channel a trigger on inventories
+// signals every committed quantity or reorder point change on.
+const StockChangeChannel = "stock_changes"
+
+// StockChange is the payload of a StockChangeChannel notification.
+type StockChange struct {
+	ProductID    string    `json:"product_id"`
+	Quantity     int32     `json:"quantity"` // Total over all locations
+	ReorderPoint int32     `json:"reorder_point"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// StockCount is the absolute quantity a physical count found for a product at
+// one location.
+type StockCount struct {
+	ProductID    string
+	LocationCode string
+	Counted      int32
+}
+
+type StockCountResult struct {
+	ProductID    string
+	LocationCode string
+	Previous     int32 // Location quantity before the import
+	Counted      int32
+	Delta        int32  // Counted - Previous
+	NewProduct   bool   // Inventory had no row for the product
+	Message      string // Empty when the line could be applied
+	Reason       string // Machine-readable form of Message
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// StockMovement is an append-only ledger row written alongside every change
+// to Inventory.Quantity. Summing Delta for a product gives its quantity.
+// QuantityAfter is the product's total over all locations.
+type StockMovement struct {
+	ID            int64     `gorm:"primaryKey"`
+	ProductID     string    `gorm:"index:idx_stock_movements_product,priority:1;not null"`
+	Delta         int32     `gorm:"not null"`
+	QuantityAfter int32     `gorm:"not null"`
+	Reason        string    `gorm:"not null"`
+	ReferenceID   string    `gorm:"index"`
+	Actor         string    `gorm:"not null"`
+	LocationCode  string    // Empty for movements that predate locations
+	LotCode       string    // Lot that added stock arrived in, if any
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+const (
+	ReasonSale       = "sale"
+	ReasonReturn     = "return"
+	ReasonRestock    = "restock"
+	ReasonAdjustment = "adjustment"
+	ReasonCycleCount = "cycle-count"
+	// Set by the service itself, not accepted from callers
+	ReasonInitial        = "initial"
+	ReasonOpeningBalance = "opening-balance"
+	ReasonTransferOut    = "transfer-out"
+	ReasonTransferIn     = "transfer-in"
+	ReasonPurchase       = "purchase"
+	ReasonExpired        = "expired"
+	ReasonFlashSale      = "flash-sale"
+	ReasonRelease        = "release"
+)
+
+// MovementInfo describes why a stock change happened; it is copied onto every
+// ledger row the change writes.
+type MovementInfo struct {
+	Reason      string
+	ReferenceID string
+	Actor       string
+	Lot         *LotInfo // Lot added stock arrives in; nil for unlotted stock
+}
+
+type MovementQuery struct {
+	ProductID   string
+	ReferenceID string
+	Limit       int
+	PageToken   string
+	BeforeID    int64 // Decoded from PageToken; 0 starts at the newest
+}
+
+type Reconciliation struct {
+	ProductID        string
+	StoredQuantity   int32
+	LedgerQuantity   int32
+	LocationQuantity int32 // Sum of the location rows
+	Applied          bool
+}
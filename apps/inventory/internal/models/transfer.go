@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// StockTransfer moves stock between two locations. Shipping takes the stock
+// out of FromLocation, where it stays in transit (counted nowhere) until it
+// is received at ToLocation.
+type StockTransfer struct {
+	ID           int64  `gorm:"primaryKey"`
+	FromLocation string `gorm:"index;not null"`
+	ToLocation   string `gorm:"index;not null"`
+	Status       string `gorm:"index;not null"`
+	Note         string
+	CreatedBy    string              `gorm:"not null"`
+	Lines        []StockTransferLine `gorm:"foreignKey:TransferID"`
+	CreatedAt    time.Time           `gorm:"autoCreateTime"`
+	ShippedAt    *time.Time
+	ReceivedAt   *time.Time
+	CancelledAt  *time.Time
+}
+
+type StockTransferLine struct {
+	ID               int64        `gorm:"primaryKey"`
+	TransferID       int64        `gorm:"index;not null"`
+	ProductID        string       `gorm:"not null"`
+	Quantity         int32        `gorm:"not null"`
+	ReceivedQuantity int32        // Set on receipt; may differ from Quantity
+	Note             string       // Explains a discrepancy
+	Lots             []LotPortion `gorm:"serializer:json"` // Lots the shipped stock came out of
+}
+
+// Discrepancy is how many more units were shipped than received; negative
+// when more arrived than were sent.
+func (l StockTransferLine) Discrepancy() int32 { return l.Quantity - l.ReceivedQuantity }
+
+const (
+	TransferPending   = "pending"
+	TransferInTransit = "in_transit"
+	TransferReceived  = "received"
+	TransferCancelled = "cancelled"
+)
+
+// ReceivedLine is what actually arrived for one product of a transfer.
+type ReceivedLine struct {
+	ProductID string
+	Quantity  int32
+	Note      string
+}
+
+type TransferQuery struct {
+	Status    string
+	Location  string // Matches either end of the transfer
+	Limit     int
+	PageToken string
+	BeforeID  int64 // Decoded from PageToken; 0 starts at the newest
+}
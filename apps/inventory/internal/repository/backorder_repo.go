@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"gorm.io/gorm"
+)
+
+// backorderShortfalls moves the shortfalls of plan that the products'
+// availability allows into its backorders. The stock plan allocated is
+// taken into account, as it will be gone by the time the backorders open.
+func backorderShortfalls(plan *models.AllocationPlan, byProduct map[string]*models.Inventory, now time.Time) {
+	allocated := make(map[string]int32)
+	for _, a := range plan.Allocations {
+		allocated[a.ProductID] += a.Quantity
+	}
+	var remaining []models.Shortfall
+	for _, sf := range plan.Shortfalls {
+		inv, ok := byProduct[sf.ProductID]
+		if !ok {
+			remaining = append(remaining, sf)
+			continue
+		}
+		after := *inv
+		after.Quantity -= allocated[sf.ProductID]
+		if !after.CanBackorder(sf.Missing, now) {
+			remaining = append(remaining, sf)
+			continue
+		}
+		plan.Backorders = append(plan.Backorders, models.BackorderLine{ProductID: sf.ProductID, Quantity: sf.Missing})
+	}
+	plan.Shortfalls = remaining
+}
+
+// SetAvailability records how far the product may be oversold. Products
+// inventory has no row for yet are skipped; their row gets the policy with
+// the next update.
+func (r *postgresRepo) SetAvailability(ctx context.Context, productID, availability string, backorderLimit int32, releaseDate *time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Inventory{}).
+		Where("product_id = ?", productID).
+		Updates(map[string]any{
+			"availability":    availability,
+			"backorder_limit": backorderLimit,
+			"release_date":    releaseDate,
+		}).Error
+}
+
+// FillBackorders fills the open backorders of productIDs, or of every
+// product when productIDs is empty, oldest first, for as long as the stock in
+// hand covers the next one whole. Stock is taken from the locations holding
+// the most first. The filled backorders are returned with where their stock
+// came from. Unless announce is nil, the event it returns for each fill is
+// saved to the outbox in the same transaction.
+func (r *postgresRepo) FillBackorders(ctx context.Context, productIDs []string, announce func(models.BackorderFill) (*models.OutboxEvent, error)) ([]models.BackorderFill, error) {
+	var fills []models.BackorderFill
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		fills = nil
+		query := tx.Model(&models.Inventory{}).Where("backordered > 0 AND quantity > 0")
+		if len(productIDs) > 0 {
+			query = query.Where("product_id IN ?", productIDs)
+		}
+		var ids []string
+		if err := query.Order("product_id").Pluck("product_id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		byProduct, byLocation, err := lockStock(tx, ids)
+		if err != nil {
+			return err
+		}
+		expired, err := expiredLotStock(tx, ids)
+		if err != nil {
+			return err
+		}
+		var open []models.Backorder
+		if err := tx.Where("product_id IN ? AND status = ?", ids, models.BackorderOpen).
+			Order("id").
+			Find(&open).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		blocked := make(map[string]bool)
+		for _, bo := range open {
+			inv, ok := byProduct[bo.ProductID]
+			if !ok || blocked[bo.ProductID] {
+				continue
+			}
+			// Stock in expired lots can't fill anything
+			sellable := make(map[locationKey]int32)
+			var stocks []*models.LocationStock
+			var inHand int32
+			for key, ls := range byLocation {
+				if n := ls.Quantity - expired[key]; key.productID == bo.ProductID && n > 0 && !models.IsFlashSaleHold(ls.LocationCode) {
+					sellable[key] = n
+					stocks = append(stocks, ls)
+					inHand += n
+				}
+			}
+			if inHand < bo.Quantity {
+				// Later backorders wait their turn
+				blocked[bo.ProductID] = true
+				continue
+			}
+			slices.SortFunc(stocks, func(a, b *models.LocationStock) int {
+				if c := cmp.Compare(sellable[locationKey{b.ProductID, b.LocationCode}], sellable[locationKey{a.ProductID, a.LocationCode}]); c != 0 {
+					return c
+				}
+				return cmp.Compare(a.LocationCode, b.LocationCode)
+			})
+
+			fill := models.BackorderFill{Backorder: bo}
+			info := models.MovementInfo{Reason: models.ReasonSale, ReferenceID: bo.ReferenceID, Actor: bo.Actor}
+			need := bo.Quantity
+			for _, ls := range stocks {
+				if need == 0 {
+					break
+				}
+				take := min(need, sellable[locationKey{ls.ProductID, ls.LocationCode}])
+				ls.Quantity -= take
+				inv.Quantity -= take
+				need -= take
+				if err := recordMovement(tx, bo.ProductID, ls.LocationCode, -take, inv.Quantity, info); err != nil {
+					return err
+				}
+				lots, err := trackLots(tx, bo.ProductID, ls.LocationCode, -take, ls.Quantity, nil)
+				if err != nil {
+					return err
+				}
+				fill.Allocations = append(fill.Allocations, models.Allocation{ProductID: bo.ProductID, LocationCode: ls.LocationCode, Quantity: take, Lots: lots})
+			}
+			inv.Backordered -= bo.Quantity
+			fill.Backorder.Status = models.BackorderFilled
+			fill.Backorder.FilledAt = &now
+			fill.Backorder.Allocations = fill.Allocations
+			if err := tx.Model(&models.Backorder{ID: bo.ID}).
+				Updates(models.Backorder{Status: models.BackorderFilled, FilledAt: &now, Allocations: fill.Allocations}).Error; err != nil {
+				return err
+			}
+			if announce != nil {
+				event, err := announce(fill)
+				if err != nil {
+					return err
+				}
+				if err := tx.Create(event).Error; err != nil {
+					return err
+				}
+			}
+			fills = append(fills, fill)
+		}
+		if len(fills) == 0 {
+			return nil
+		}
+		return saveStock(tx, byProduct, byLocation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fills, nil
+}
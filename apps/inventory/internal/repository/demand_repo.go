@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordSales adds an order's items to the day's sales. It reports false,
+// changing nothing, when the order was already recorded.
+func (r *postgresRepo) RecordSales(ctx context.Context, orderID int64, sales []models.DailySales) (bool, error) {
+	recorded := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RecordedOrder{OrderID: orderID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		recorded = true
+		if len(sales) == 0 {
+			return nil
+		}
+
+		// Upserted in product_id order so concurrent orders lock rows alike
+		slices.SortFunc(sales, func(a, b models.DailySales) int { return strings.Compare(a.ProductID, b.ProductID) })
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]any{"quantity": gorm.Expr("daily_sales.quantity + excluded.quantity")}),
+		}).Create(&sales).Error
+	})
+	return recorded, err
+}
+
+// ListDailySales returns sales on days from since onwards, by product then
+// day. Days without sales have no row. With no productIDs it covers every
+// product.
+func (r *postgresRepo) ListDailySales(ctx context.Context, productIDs []string, since time.Time) ([]models.DailySales, error) {
+	query := r.db.WithContext(ctx).Where("day >= ?", since)
+	if len(productIDs) > 0 {
+		query = query.Where("product_id IN ?", productIDs)
+	}
+	var sales []models.DailySales
+	if err := query.Order("product_id, day").Find(&sales).Error; err != nil {
+		return nil, err
+	}
+	return sales, nil
+}
+
+// ProductSuppliers returns, for each of productIDs that was ever on a
+// purchase order, the supplier of the latest one that wasn't cancelled.
+func (r *postgresRepo) ProductSuppliers(ctx context.Context, productIDs []string) ([]models.ProductSupplier, error) {
+	var suppliers []models.ProductSupplier
+	err := r.db.WithContext(ctx).Table("purchase_order_lines AS l").
+		Select("DISTINCT ON (l.product_id) l.product_id, s.code AS supplier_code, s.lead_time_days").
+		Joins("JOIN purchase_orders po ON po.id = l.purchase_order_id").
+		Joins("JOIN suppliers s ON s.code = po.supplier_code").
+		Where("l.product_id IN ? AND po.status <> ?", productIDs, models.PurchaseOrderCancelled).
+		Order("l.product_id, po.id DESC").
+		Scan(&suppliers).Error
+	if err != nil {
+		return nil, err
+	}
+	return suppliers, nil
+}
+
+// LastScheduledRun returns when job last ran, or the zero time if never.
+func (r *postgresRepo) LastScheduledRun(ctx context.Context, job string) (time.Time, error) {
+	var run models.ScheduledRun
+	err := r.db.WithContext(ctx).Where("job = ?", job).Limit(1).Find(&run).Error
+	return run.LastRunAt, err
+}
+
+// ClaimScheduledRun records now as job's last run, if it still last ran at
+// last, and saves event to the outbox with it. It reports false, changing
+// nothing, when another replica claimed the run first.
+func (r *postgresRepo) ClaimScheduledRun(ctx context.Context, job string, last, now time.Time, event *models.OutboxEvent) (bool, error) {
+	claimed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var result *gorm.DB
+		if last.IsZero() {
+			result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ScheduledRun{Job: job, LastRunAt: now})
+		} else {
+			result = tx.Model(&models.ScheduledRun{}).
+				Where("job = ? AND last_run_at = ?", job, last).
+				Update("last_run_at", now)
+		}
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		claimed = true
+		return tx.Create(event).Error
+	})
+	return claimed, err
+}
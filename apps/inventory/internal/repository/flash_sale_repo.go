@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrFlashSaleNotFound = errors.New("flash sale not found")
+
+// ErrFlashSaleState means the flash sale's status doesn't allow the
+// requested step, e.g. ending one that already ended.
+var ErrFlashSaleState = errors.New("flash sale is not in a state that allows this")
+
+func (r *postgresRepo) CreateFlashSale(ctx context.Context, sale *models.FlashSale) error {
+	return r.db.WithContext(ctx).Create(sale).Error
+}
+
+func (r *postgresRepo) GetFlashSale(ctx context.Context, id int64) (*models.FlashSale, error) {
+	return getFlashSale(r.db.WithContext(ctx), id, false)
+}
+
+// getFlashSale loads a flash sale, optionally locking it so only one
+// status change or reconciliation can run at a time.
+func getFlashSale(tx *gorm.DB, id int64, lock bool) (*models.FlashSale, error) {
+	query := tx
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var sale models.FlashSale
+	err := query.Where("id = ?", id).First(&sale).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFlashSaleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sale, nil
+}
+
+func (r *postgresRepo) ListFlashSales(ctx context.Context, q models.FlashSaleQuery) ([]*models.FlashSale, error) {
+	query := r.db.WithContext(ctx)
+	if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+	if q.ProductID != "" {
+		query = query.Where("product_id = ?", q.ProductID)
+	}
+	if q.RequestID != "" {
+		query = query.Where("id IN (?)", r.db.Model(&models.FlashSalePurchase{}).
+			Select("flash_sale_id").
+			Where("request_id = ? AND NOT refunded", q.RequestID))
+	}
+	var sales []*models.FlashSale
+	if err := query.Order("id DESC").Find(&sales).Error; err != nil {
+		return nil, err
+	}
+	return sales, nil
+}
+
+// StartFlashSale moves the sale's quantity from its location to its hold
+// location and marks it active. When the location holds less, the sale's
+// quantity is cut down to what is there.
+func (r *postgresRepo) StartFlashSale(ctx context.Context, id int64) (*models.FlashSale, error) {
+	var sale *models.FlashSale
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if sale, err = getFlashSale(tx, id, true); err != nil {
+			return err
+		}
+		if sale.Status != models.FlashSaleScheduled {
+			return ErrFlashSaleState
+		}
+		available, err := lockedLocationStock(tx, sale.ProductID, sale.LocationCode)
+		if err != nil {
+			return err
+		}
+		sale.Quantity = min(sale.Quantity, available)
+		if err := holdFlashSaleStock(tx, sale, sale.Quantity); err != nil {
+			return err
+		}
+		now := time.Now()
+		sale.Status, sale.StartedAt = models.FlashSaleActive, &now
+		return tx.Model(sale).Select("quantity", "status", "started_at").Updates(sale).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sale, nil
+}
+
+// MarkFlashSaleLoaded records that the sale's counters were set up in the
+// store.
+func (r *postgresRepo) MarkFlashSaleLoaded(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Model(&models.FlashSale{}).
+		Where("id = ? AND NOT loaded", id).
+		Update("loaded", true).Error
+}
+
+// lockedLocationStock locks the product's inventories row and returns what
+// it holds at the location.
+func lockedLocationStock(tx *gorm.DB, productID, locationCode string) (int32, error) {
+	byProduct, byLocation, err := lockStock(tx, []string{productID})
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := byProduct[productID]; !ok {
+		return 0, fmt.Errorf("%w: %s", ErrStockArchived, productID)
+	}
+	if ls, ok := byLocation[locationKey{productID, locationCode}]; ok {
+		return ls.Quantity, nil
+	}
+	return 0, nil
+}
+
+// holdFlashSaleStock moves quantity from the sale's location to its hold
+// location, or back when quantity is negative, keeping it in its lots.
+func holdFlashSaleStock(tx *gorm.DB, sale *models.FlashSale, quantity int32) error {
+	if quantity == 0 {
+		return nil
+	}
+	info := models.MovementInfo{Reason: models.ReasonFlashSale, ReferenceID: fmt.Sprintf("flash-sale-%d", sale.ID), Actor: "system"}
+	from, to := sale.LocationCode, sale.HoldLocation()
+	if quantity < 0 {
+		from, to, quantity = to, from, -quantity
+	}
+	lots, err := moveTransferStock(tx, sale.ProductID, from, -quantity, info)
+	if err != nil {
+		return err
+	}
+	if _, err := moveTransferStock(tx, sale.ProductID, to, quantity, info); err != nil {
+		return err
+	}
+	return addLots(tx, sale.ProductID, to, lots)
+}
+
+// ReconcileFlashSale applies the entries fetch returns from the sale's
+// Redis log, starting at the first one not yet applied. Purchases are
+// taken out of the hold location as sales and refunds put back. A
+// purchase already applied is skipped, so the log can be read again after
+// a failure. The number of entries applied is returned; nothing is done
+// unless the sale is active.
+func (r *postgresRepo) ReconcileFlashSale(ctx context.Context, id int64, fetch func(offset int64) ([]models.FlashSaleEntry, error)) (*models.FlashSale, int, error) {
+	var sale *models.FlashSale
+	var applied int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		applied = 0
+		if sale, err = getFlashSale(tx, id, true); err != nil {
+			return err
+		}
+		if sale.Status != models.FlashSaleActive {
+			return nil
+		}
+		entries, err := fetch(sale.Reconciled)
+		if err != nil || len(entries) == 0 {
+			return err
+		}
+		for _, entry := range entries {
+			change, err := applyFlashSaleEntry(tx, sale, entry)
+			if err != nil {
+				return err
+			}
+			sale.Sold -= change
+		}
+		applied = len(entries)
+		sale.Reconciled += int64(applied)
+		return tx.Model(sale).Select("sold", "reconciled").Updates(sale).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return sale, applied, nil
+}
+
+// applyFlashSaleEntry records one log entry and returns the change it made
+// to the hold location.
+func applyFlashSaleEntry(tx *gorm.DB, sale *models.FlashSale, entry models.FlashSaleEntry) (int32, error) {
+	var purchase models.FlashSalePurchase
+	err := tx.Where("flash_sale_id = ? AND request_id = ?", sale.ID, entry.RequestID).First(&purchase).Error
+	found := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	if entry.Refund {
+		if !found || purchase.Refunded {
+			return 0, nil
+		}
+		return purchase.Quantity, refundFlashSalePurchase(tx, sale, &purchase, sale.HoldLocation())
+	}
+
+	if found && !purchase.Refunded {
+		// Applied already
+		return 0, nil
+	}
+	purchase.FlashSaleID, purchase.RequestID = sale.ID, entry.RequestID
+	purchase.UserID, purchase.ReferenceID = entry.UserID, entry.ReferenceID
+	purchase.Quantity, purchase.Refunded, purchase.PurchasedAt = entry.Quantity, false, entry.At
+	info := models.MovementInfo{Reason: models.ReasonSale, ReferenceID: entry.ReferenceID, Actor: entry.UserID}
+	if purchase.Lots, err = moveTransferStock(tx, sale.ProductID, sale.HoldLocation(), -entry.Quantity, info); err != nil {
+		return 0, err
+	}
+	// A request retried after its refund bought again under the same id
+	return -entry.Quantity, tx.Save(&purchase).Error
+}
+
+// refundFlashSalePurchase puts the purchase's stock back at locationCode in
+// the lots it came out of.
+func refundFlashSalePurchase(tx *gorm.DB, sale *models.FlashSale, purchase *models.FlashSalePurchase, locationCode string) error {
+	info := models.MovementInfo{Reason: models.ReasonFlashSale, ReferenceID: purchase.ReferenceID, Actor: purchase.UserID}
+	if _, err := moveTransferStock(tx, sale.ProductID, locationCode, purchase.Quantity, info); err != nil {
+		return err
+	}
+	if err := addLots(tx, sale.ProductID, locationCode, purchase.Lots); err != nil {
+		return err
+	}
+	return tx.Model(purchase).Update("refunded", true).Error
+}
+
+// RefundFlashSalePurchase refunds requestID's purchase from a sale whose
+// log is sealed, straight in the ledger: to the hold location while the
+// sale is active and to its location once it ended. It reports whether
+// there was a purchase to refund.
+func (r *postgresRepo) RefundFlashSalePurchase(ctx context.Context, id int64, requestID string) (*models.FlashSale, bool, error) {
+	var sale *models.FlashSale
+	var refunded bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		refunded = false
+		if sale, err = getFlashSale(tx, id, true); err != nil {
+			return err
+		}
+		var purchase models.FlashSalePurchase
+		err = tx.Where("flash_sale_id = ? AND request_id = ?", id, requestID).First(&purchase).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil || purchase.Refunded {
+			return err
+		}
+		to := sale.HoldLocation()
+		if sale.Status == models.FlashSaleEnded {
+			to = sale.LocationCode
+		}
+		if err := refundFlashSalePurchase(tx, sale, &purchase, to); err != nil {
+			return err
+		}
+		sale.Sold -= purchase.Quantity
+		refunded = true
+		return tx.Model(sale).Update("sold", sale.Sold).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return sale, refunded, nil
+}
+
+// EndFlashSale marks the sale ended, returning whatever is left at its
+// hold location to its location. Callers reconcile the sale's log first.
+func (r *postgresRepo) EndFlashSale(ctx context.Context, id int64) (*models.FlashSale, error) {
+	var sale *models.FlashSale
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if sale, err = getFlashSale(tx, id, true); err != nil {
+			return err
+		}
+		switch sale.Status {
+		case models.FlashSaleScheduled:
+		case models.FlashSaleActive:
+			held, err := lockedLocationStock(tx, sale.ProductID, sale.HoldLocation())
+			if err != nil {
+				return err
+			}
+			if err := holdFlashSaleStock(tx, sale, -held); err != nil {
+				return err
+			}
+		default:
+			return ErrFlashSaleState
+		}
+		now := time.Now()
+		sale.Status, sale.EndedAt = models.FlashSaleEnded, &now
+		return tx.Model(sale).Select("status", "ended_at").Updates(sale).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sale, nil
+}
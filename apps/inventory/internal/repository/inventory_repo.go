@@ -2,17 +2,100 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type InventoryRepository interface {
 	GetStock(ctx context.Context, productID string) (*models.Inventory, error)
-	UpdateStock(ctx context.Context, productID string, change int32) (*models.Inventory, error)
+	GetStocks(ctx context.Context, productIDs []string) ([]*models.Inventory, error)
+	ExpiredLotStock(ctx context.Context, productIDs []string) ([]*models.LocationStock, error)
+	GetLocationStocks(ctx context.Context, productIDs []string) ([]*models.LocationStock, error)
+	UpdateStock(ctx context.Context, productID, locationCode string, change int32, info models.MovementInfo, requestID string) (*models.Inventory, *models.LocationStock, error)
+	AdjustStockBatch(ctx context.Context, adjustments []models.StockAdjustment, info models.MovementInfo, requestID string) ([]models.StockAdjustmentResult, error)
+	ReserveStock(ctx context.Context, req models.AllocationRequest, plan func([]*models.LocationStock) *models.AllocationPlan, info models.MovementInfo, requestID string) (*models.AllocationPlan, error)
+	ReleaseStock(ctx context.Context, requestID string, info models.MovementInfo) (bool, []models.Allocation, error)
+	InitializeStock(ctx context.Context, productID, locationCode string, quantity int32, info models.MovementInfo) error
+	ArchiveStock(ctx context.Context, productID string) error
+	RestoreStock(ctx context.Context, productID string) error
+	ListMovements(ctx context.Context, q models.MovementQuery) ([]*models.StockMovement, error)
+	ReconcileStock(ctx context.Context, productID, locationCode string, apply bool) (*models.Reconciliation, error)
+	BackfillOpeningBalances(ctx context.Context) (int64, error)
+	DeleteIdempotencyKeysBefore(ctx context.Context, before time.Time) (int64, error)
+	CreateLocation(ctx context.Context, location *models.Location) error
+	EnsureLocation(ctx context.Context, location *models.Location) error
+	GetLocation(ctx context.Context, code string) (*models.Location, error)
+	ListLocations(ctx context.Context, includeInactive bool) ([]*models.Location, error)
+	MoveStockToLocation(ctx context.Context, locationCode string) (int64, error)
+	CreateTransfer(ctx context.Context, transfer *models.StockTransfer, requestID string) error
+	GetTransfer(ctx context.Context, id int64) (*models.StockTransfer, error)
+	ListTransfers(ctx context.Context, q models.TransferQuery) ([]*models.StockTransfer, error)
+	ShipTransfer(ctx context.Context, id int64, actor string) (*models.StockTransfer, error)
+	ReceiveTransfer(ctx context.Context, id int64, received []models.ReceivedLine, actor string) (*models.StockTransfer, error)
+	CancelTransfer(ctx context.Context, id int64, actor string) (*models.StockTransfer, error)
+	SetReorderPoint(ctx context.Context, productID string, reorderPoint int32) (*models.Inventory, error)
+	EvaluateStockAlerts(ctx context.Context, productIDs []string, debounce time.Duration, announce func(models.StockAlert) (*models.OutboxEvent, error)) ([]models.StockAlert, error)
+	ListInventory(ctx context.Context, q models.InventoryQuery) ([]*models.Inventory, error)
+	ImportStockCounts(ctx context.Context, counts []models.StockCount, info models.MovementInfo, requestID string, dryRun bool) ([]models.StockCountResult, error)
+	InstallStockChangeTrigger(ctx context.Context) error
+	SetAvailability(ctx context.Context, productID, availability string, backorderLimit int32, releaseDate *time.Time) error
+	FillBackorders(ctx context.Context, productIDs []string, announce func(models.BackorderFill) (*models.OutboxEvent, error)) ([]models.BackorderFill, error)
+	PublishOutboxEvents(ctx context.Context, limit int, publish func(*models.OutboxEvent) error) (int, error)
+	LastScheduledRun(ctx context.Context, job string) (time.Time, error)
+	ClaimScheduledRun(ctx context.Context, job string, last, now time.Time, event *models.OutboxEvent) (bool, error)
+	CreateSupplier(ctx context.Context, supplier *models.Supplier) error
+	UpdateSupplier(ctx context.Context, supplier *models.Supplier) error
+	GetSupplier(ctx context.Context, code string) (*models.Supplier, error)
+	ListSuppliers(ctx context.Context, includeInactive bool) ([]*models.Supplier, error)
+	CreatePurchaseOrder(ctx context.Context, po *models.PurchaseOrder, requestID string) error
+	GetPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error)
+	ListPurchaseOrders(ctx context.Context, q models.PurchaseOrderQuery) ([]*models.PurchaseOrder, error)
+	ReceivePurchaseOrder(ctx context.Context, id int64, deliveries []models.Delivery, actor, requestID string) (*models.PurchaseOrder, error)
+	CancelPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error)
+	IncomingStock(ctx context.Context, productIDs []string) ([]models.IncomingStock, error)
+	ExpireLots(ctx context.Context, day time.Time) ([]*models.Lot, error)
+	ListLots(ctx context.Context, q models.LotQuery) ([]*models.Lot, error)
+	RecordSales(ctx context.Context, orderID int64, sales []models.DailySales) (bool, error)
+	ListDailySales(ctx context.Context, productIDs []string, since time.Time) ([]models.DailySales, error)
+	ProductSuppliers(ctx context.Context, productIDs []string) ([]models.ProductSupplier, error)
+	CreateFlashSale(ctx context.Context, sale *models.FlashSale) error
+	GetFlashSale(ctx context.Context, id int64) (*models.FlashSale, error)
+	ListFlashSales(ctx context.Context, q models.FlashSaleQuery) ([]*models.FlashSale, error)
+	StartFlashSale(ctx context.Context, id int64) (*models.FlashSale, error)
+	MarkFlashSaleLoaded(ctx context.Context, id int64) error
+	ReconcileFlashSale(ctx context.Context, id int64, fetch func(offset int64) ([]models.FlashSaleEntry, error)) (*models.FlashSale, int, error)
+	RefundFlashSalePurchase(ctx context.Context, id int64, requestID string) (*models.FlashSale, bool, error)
+	EndFlashSale(ctx context.Context, id int64) (*models.FlashSale, error)
 }
 
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// ErrAdjustmentRejected is returned, together with the per-line results, when
+// at least one line of a batch could not be applied and the batch was rolled
+// back.
+var ErrAdjustmentRejected = errors.New("stock adjustment rejected")
+
+var ErrRequestIDReused = errors.New("request_id was already used for a different request")
+
+var (
+	ErrLocationExists   = errors.New("location code already exists")
+	ErrLocationNotFound = errors.New("location not found")
+)
+
+// ErrStockArchived means the product's stock row is soft-deleted, so it can't
+// be changed until the product is restored.
+var ErrStockArchived = errors.New("product stock is archived")
+
 type postgresRepo struct {
 	db *gorm.DB
 }
@@ -30,29 +113,796 @@ func (r *postgresRepo) GetStock(ctx context.Context, productID string) (*models.
 	return &inventory, nil
 }
 
-func (r *postgresRepo) UpdateStock(ctx context.Context, productID string, change int32) (*models.Inventory, error) {
-	var inventory models.Inventory
+func (r *postgresRepo) GetStocks(ctx context.Context, productIDs []string) ([]*models.Inventory, error) {
+	var inventories []*models.Inventory
+	if err := r.db.WithContext(ctx).Where("product_id IN ?", productIDs).Find(&inventories).Error; err != nil {
+		return nil, err
+	}
+	return inventories, nil
+}
+
+// GetLocationStocks returns the per-location rows of productIDs, leaving out
+// archived products.
+func (r *postgresRepo) GetLocationStocks(ctx context.Context, productIDs []string) ([]*models.LocationStock, error) {
+	var stocks []*models.LocationStock
+	if err := r.db.WithContext(ctx).
+		Where("product_id IN ?", productIDs).
+		Where("product_id IN (?)", r.db.Model(&models.Inventory{}).Select("product_id")).
+		Order("product_id, location_code").
+		Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// stockUpdate is what UpdateStock stores for replaying a request id.
+type stockUpdate struct {
+	Inventory models.Inventory
+	Location  models.LocationStock
+}
+
+// UpdateStock applies change to the product's total and to one location in
+// the same transaction. The total is changed first with a single conditional
+// UPDATE, so concurrent callers are serialized by its row lock and neither
+// quantity can go below zero. The first write for a product inserts the row;
+// if another caller wins that insert, the change is retried as an update
+// against their row. The ledger row and the request id are written in the
+// same transaction.
+func (r *postgresRepo) UpdateStock(ctx context.Context, productID, locationCode string, change int32, info models.MovementInfo, requestID string) (*models.Inventory, *models.LocationStock, error) {
+	var update stockUpdate
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		prior, err := claimRequest(tx, requestID, "UpdateStock", productID, locationCode, change, info)
+		if err != nil {
+			return err
+		}
+		if prior != nil {
+			return json.Unmarshal(prior.Response, &update)
+		}
+
+		if err := updateStock(tx, &update.Inventory, productID, change); err != nil {
+			return err
+		}
+		if err := updateLocationStock(tx, &update.Location, productID, locationCode, change); err != nil {
+			return err
+		}
+		if err := recordMovement(tx, productID, locationCode, change, update.Inventory.Quantity, info); err != nil {
+			return err
+		}
+		if _, err := trackLots(tx, productID, locationCode, change, update.Location.Quantity, info.Lot); err != nil {
+			return err
+		}
+		return completeRequest(tx, requestID, update)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &update.Inventory, &update.Location, nil
+}
+
+func updateStock(tx *gorm.DB, inventory *models.Inventory, productID string, change int32) error {
+	for attempt := 0; attempt < 2; attempt++ {
+		*inventory = models.Inventory{}
+		result := tx.Model(inventory).Clauses(clause.Returning{}).
+			Where("product_id = ? AND quantity + ? >= 0", productID, change).
+			Update("quantity", gorm.Expr("quantity + ?", change))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			return nil
+		}
+
+		// No row was updated: either it doesn't exist yet or the change
+		// would take it negative
+		var count int64
+		if err := tx.Model(&models.Inventory{}).Where("product_id = ?", productID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 || change < 0 {
+			return ErrInsufficientStock
+		}
+
+		*inventory = models.Inventory{ProductID: productID, Quantity: change}
+		result = tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "product_id"}}, DoNothing: true}).
+			Create(inventory)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			return nil
+		}
+	}
+	// The conflicting row is soft-deleted, so the update can't see it
+	return ErrStockArchived
+}
+
+// updateLocationStock applies change to one location's row, creating it on
+// the first delivery there. Callers must already hold the product's
+// inventories row lock.
+func updateLocationStock(tx *gorm.DB, stock *models.LocationStock, productID, locationCode string, change int32) error {
+	if change < 0 {
+		result := tx.Model(&models.LocationStock{}).
+			Where("product_id = ? AND location_code = ? AND quantity + ? >= 0", productID, locationCode, change).
+			Update("quantity", gorm.Expr("quantity + ?", change))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientStock
+		}
+	} else {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "product_id"}, {Name: "location_code"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"quantity":   gorm.Expr("location_stocks.quantity + EXCLUDED.quantity"),
+				"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+			}),
+		}).Create(&models.LocationStock{ProductID: productID, LocationCode: locationCode, Quantity: change}).Error; err != nil {
+			return err
+		}
+	}
+	*stock = models.LocationStock{}
+	return tx.Where("product_id = ? AND location_code = ?", productID, locationCode).First(stock).Error
+}
+
+// claimRequest registers requestID for this transaction. It returns the
+// stored key when the request was already completed, in which case the caller
+// must answer with its Response and change nothing. A concurrent call with the
+// same id blocks here until the first transaction finishes. Failed requests
+// roll back their key, so they can be retried.
+func claimRequest(tx *gorm.DB, requestID, method string, args ...any) (*models.IdempotencyKey, error) {
+	if requestID == "" {
+		return nil, nil
+	}
+	b, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	key := models.IdempotencyKey{RequestID: requestID, Method: method, RequestHash: hex.EncodeToString(sum[:])}
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&key)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var prior models.IdempotencyKey
+	if err := tx.Where("request_id = ?", requestID).First(&prior).Error; err != nil {
+		return nil, err
+	}
+	if prior.Method != key.Method || prior.RequestHash != key.RequestHash {
+		return nil, ErrRequestIDReused
+	}
+	return &prior, nil
+}
+
+func completeRequest(tx *gorm.DB, requestID string, response any) error {
+	if requestID == "" {
+		return nil
+	}
+	b, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.IdempotencyKey{}).Where("request_id = ?", requestID).Update("response", b).Error
+}
+
+func recordMovement(tx *gorm.DB, productID, locationCode string, delta, quantityAfter int32, info models.MovementInfo) error {
+	movement := models.StockMovement{
+		ProductID:     productID,
+		LocationCode:  locationCode,
+		Delta:         delta,
+		QuantityAfter: quantityAfter,
+		Reason:        info.Reason,
+		ReferenceID:   info.ReferenceID,
+		Actor:         info.Actor,
+	}
+	if info.Lot != nil && delta > 0 {
+		movement.LotCode = info.Lot.Code
+	}
+	return tx.Create(&movement).Error
+}
+
+// AdjustStockBatch applies every adjustment in a single transaction. Product
+// rows are locked in product_id order, then location rows in (product_id,
+// location_code) order, whatever the request order, so two batches that
+// touch the same products can't deadlock. Lines for the same product are
+// applied cumulatively in request order.
+func (r *postgresRepo) AdjustStockBatch(ctx context.Context, adjustments []models.StockAdjustment, info models.MovementInfo, requestID string) ([]models.StockAdjustmentResult, error) {
+	var ids []string
+	for _, adj := range adjustments {
+		ids = append(ids, adj.ProductID)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	results := make([]models.StockAdjustmentResult, len(adjustments))
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		prior, err := claimRequest(tx, requestID, "AdjustStockBatch", adjustments, info)
+		if err != nil {
+			return err
+		}
+		if prior != nil {
+			return json.Unmarshal(prior.Response, &results)
+		}
+
+		// Make sure every product and location has a row to lock. Rows
+		// another transaction inserts first are left alone; ours are rolled
+		// back with the batch.
+		missing := make([]models.Inventory, len(ids))
+		for i, id := range ids {
+			missing[i] = models.Inventory{ProductID: id}
+		}
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "product_id"}}, DoNothing: true}).
+			Create(&missing).Error; err != nil {
+			return err
+		}
+		missingAt := make([]models.LocationStock, len(adjustments))
+		for i, adj := range adjustments {
+			missingAt[i] = models.LocationStock{ProductID: adj.ProductID, LocationCode: adj.LocationCode}
+		}
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "product_id"}, {Name: "location_code"}}, DoNothing: true}).
+			Create(&missingAt).Error; err != nil {
+			return err
+		}
+
+		byProduct, byLocation, err := lockStock(tx, ids)
+		if err != nil {
+			return err
+		}
+
+		rejected := false
+		for i, adj := range adjustments {
+			results[i] = models.StockAdjustmentResult{ProductID: adj.ProductID, Change: adj.Change, LocationCode: adj.LocationCode}
+			inv, ok := byProduct[adj.ProductID]
+			if !ok {
+				// Only a soft-deleted row can hide from the locking read
+				results[i].Message = ErrStockArchived.Error()
+				results[i].Reason = "STOCK_ARCHIVED"
+				rejected = true
+				continue
+			}
+			at := byLocation[locationKey{adj.ProductID, adj.LocationCode}]
+			if at.Quantity+adj.Change < 0 || inv.Quantity+adj.Change < 0 {
+				results[i].Message = ErrInsufficientStock.Error()
+				results[i].Reason = "INSUFFICIENT_STOCK"
+				rejected = true
+				continue
+			}
+			inv.Quantity += adj.Change
+			at.Quantity += adj.Change
+			results[i].NewQuantity = inv.Quantity
+			results[i].LocationQuantity = at.Quantity
+		}
+		if rejected {
+			return ErrAdjustmentRejected
+		}
+
+		if err := saveStock(tx, byProduct, byLocation); err != nil {
+			return err
+		}
+		for _, res := range results {
+			if err := recordMovement(tx, res.ProductID, res.LocationCode, res.Change, res.NewQuantity, info); err != nil {
+				return err
+			}
+			if _, err := trackLots(tx, res.ProductID, res.LocationCode, res.Change, res.LocationQuantity, info.Lot); err != nil {
+				return err
+			}
+		}
+		return completeRequest(tx, requestID, results)
+	})
+	if errors.Is(err, ErrAdjustmentRejected) {
+		return results, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+type locationKey struct {
+	productID    string
+	locationCode string
+}
+
+// lockStock locks the inventories rows of productIDs and then all of their
+// location rows, in the fixed order every multi-product write uses.
+// Archived products are left out of both maps.
+func lockStock(tx *gorm.DB, productIDs []string) (map[string]*models.Inventory, map[locationKey]*models.LocationStock, error) {
+	var rows []*models.Inventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id IN ?", productIDs).
+		Order("product_id").
+		Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	byProduct := make(map[string]*models.Inventory, len(rows))
+	for _, inv := range rows {
+		byProduct[inv.ProductID] = inv
+	}
+
+	var stocks []*models.LocationStock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id IN ?", productIDs).
+		Order("product_id, location_code").
+		Find(&stocks).Error; err != nil {
+		return nil, nil, err
+	}
+	byLocation := make(map[locationKey]*models.LocationStock, len(stocks))
+	for _, ls := range stocks {
+		if _, ok := byProduct[ls.ProductID]; ok {
+			byLocation[locationKey{ls.ProductID, ls.LocationCode}] = ls
+		}
+	}
+	return byProduct, byLocation, nil
+}
+
+// saveStock writes back the quantities of rows returned by lockStock.
+func saveStock(tx *gorm.DB, byProduct map[string]*models.Inventory, byLocation map[locationKey]*models.LocationStock) error {
+	for _, inv := range byProduct {
+		if err := tx.Model(inv).Updates(map[string]any{"quantity": inv.Quantity, "backordered": inv.Backordered}).Error; err != nil {
+			return err
+		}
+	}
+	for _, ls := range byLocation {
+		if err := tx.Model(ls).Update("quantity", ls.Quantity).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReserveStock locks the stock of every requested product, asks plan where
+// to take it from, and deducts the planned allocations in the same
+// transaction. With req.AllowBackorder, shortfalls the product's availability
+// allows are opened as backorders instead. If the plan still has shortfalls
+// nothing is deducted and the plan is returned with ErrAdjustmentRejected.
+// plan sees only unarchived products, no stock in expired lots, and no stock
+// of products with open backorders.
+func (r *postgresRepo) ReserveStock(ctx context.Context, req models.AllocationRequest, plan func([]*models.LocationStock) *models.AllocationPlan, info models.MovementInfo, requestID string) (*models.AllocationPlan, error) {
+	var ids []string
+	for _, item := range req.Items {
+		ids = append(ids, item.ProductID)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	var result *models.AllocationPlan
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		prior, err := claimRequest(tx, requestID, "ReserveStock", req, info)
+		if err != nil {
+			return err
+		}
+		if prior != nil {
+			return json.Unmarshal(prior.Response, &result)
+		}
+
+		byProduct, byLocation, err := lockStock(tx, ids)
+		if err != nil {
+			return err
+		}
+		expired, err := expiredLotStock(tx, ids)
+		if err != nil {
+			return err
+		}
+		// plan gets copies, so it can't change what is written back
+		stocks := make([]*models.LocationStock, 0, len(byLocation))
+		for key, ls := range byLocation {
+			shown := *ls
+			shown.Quantity = max(ls.Quantity-expired[key], 0)
+			if byProduct[ls.ProductID].Backordered > 0 {
+				// Held for the open backorders
+				shown.Quantity = 0
+			}
+			stocks = append(stocks, &shown)
+		}
+		slices.SortFunc(stocks, func(a, b *models.LocationStock) int {
+			if c := strings.Compare(a.ProductID, b.ProductID); c != 0 {
+				return c
+			}
+			return strings.Compare(a.LocationCode, b.LocationCode)
+		})
+
+		result = plan(stocks)
+		if req.AllowBackorder {
+			backorderShortfalls(result, byProduct, time.Now())
+		}
+		if !result.Fulfillable() {
+			return ErrAdjustmentRejected
+		}
+
+		for i, a := range result.Allocations {
+			key := locationKey{a.ProductID, a.LocationCode}
+			ls, ok := byLocation[key]
+			if !ok || a.Quantity <= 0 || a.Quantity > ls.Quantity-expired[key] {
+				return ErrInsufficientStock
+			}
+			inv := byProduct[a.ProductID]
+			ls.Quantity -= a.Quantity
+			inv.Quantity -= a.Quantity
+			if inv.Quantity < 0 {
+				return ErrInsufficientStock
+			}
+			if err := recordMovement(tx, a.ProductID, a.LocationCode, -a.Quantity, inv.Quantity, info); err != nil {
+				return err
+			}
+			if result.Allocations[i].Lots, err = trackLots(tx, a.ProductID, a.LocationCode, -a.Quantity, ls.Quantity, nil); err != nil {
+				return err
+			}
+		}
+		for _, line := range result.Backorders {
+			byProduct[line.ProductID].Backordered += line.Quantity
+			if err := tx.Create(&models.Backorder{
+				ProductID:   line.ProductID,
+				Status:      models.BackorderOpen,
+				ReferenceID: info.ReferenceID,
+				RequestID:   requestID,
+				Quantity:    line.Quantity,
+				Actor:       info.Actor,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if err := saveStock(tx, byProduct, byLocation); err != nil {
+			return err
+		}
+		return completeRequest(tx, requestID, result)
+	})
+	if errors.Is(err, ErrAdjustmentRejected) {
+		return result, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// releasedRequestHash replaces the request hash of a released reservation,
+// so the request id can't be used again.
+const releasedRequestHash = "released"
+
+// ReleaseStock undoes the reservation ReserveStock made with requestID: the
+// allocated stock goes back to its locations and lots, open backorders are
+// cancelled and filled ones give back the stock they took. It reports false
+// when no reservation had committed. The request id is then taken all the
+// same, so a reservation still on its way fails with ErrRequestIDReused
+// instead of deducting stock after all. Releasing twice reports false the
+// second time.
+func (r *postgresRepo) ReleaseStock(ctx context.Context, requestID string, info models.MovementInfo) (bool, []models.Allocation, error) {
+	var released bool
+	var restored []models.Allocation
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("product_id = ?", productID).First(&inventory).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				if change < 0 {
-					return errors.New("insufficient stock") // Cannot deduct from 0
+		released, restored = false, nil
+		// Waits for a reservation in flight with the same id to finish
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.IdempotencyKey{RequestID: requestID, Method: "ReserveStock", RequestHash: releasedRequestHash})
+		if result.Error != nil || result.RowsAffected == 1 {
+			return result.Error
+		}
+		var key models.IdempotencyKey
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("request_id = ?", requestID).
+			First(&key).Error; err != nil {
+			return err
+		}
+		if key.Method != "ReserveStock" {
+			return ErrRequestIDReused
+		}
+		if key.RequestHash == releasedRequestHash {
+			return nil
+		}
+		var plan models.AllocationPlan
+		if err := json.Unmarshal(key.Response, &plan); err != nil {
+			return err
+		}
+		var backorders []models.Backorder
+		if err := tx.Where("request_id = ? AND status <> ?", requestID, models.BackorderCancelled).
+			Order("id").
+			Find(&backorders).Error; err != nil {
+			return err
+		}
+
+		var ids []string
+		for _, a := range plan.Allocations {
+			ids = append(ids, a.ProductID)
+		}
+		for _, bo := range backorders {
+			ids = append(ids, bo.ProductID)
+		}
+		slices.Sort(ids)
+		ids = slices.Compact(ids)
+		byProduct, byLocation, err := lockStock(tx, ids)
+		if err != nil {
+			return err
+		}
+		restore := func(a models.Allocation) error {
+			inv, ok := byProduct[a.ProductID]
+			if !ok {
+				return ErrStockArchived
+			}
+			ls, ok := byLocation[locationKey{a.ProductID, a.LocationCode}]
+			if !ok {
+				return ErrLocationNotFound
+			}
+			ls.Quantity += a.Quantity
+			inv.Quantity += a.Quantity
+			restored = append(restored, a)
+			if err := recordMovement(tx, a.ProductID, a.LocationCode, a.Quantity, inv.Quantity, info); err != nil {
+				return err
+			}
+			return addLots(tx, a.ProductID, a.LocationCode, a.Lots)
+		}
+
+		for _, a := range plan.Allocations {
+			if err := restore(a); err != nil {
+				return err
+			}
+		}
+		for _, bo := range backorders {
+			if bo.Status == models.BackorderOpen {
+				if inv, ok := byProduct[bo.ProductID]; ok {
+					inv.Backordered -= bo.Quantity
 				}
-				inventory = models.Inventory{ProductID: productID, Quantity: change}
-				return tx.Create(&inventory).Error
 			}
+			for _, a := range bo.Allocations {
+				if err := restore(a); err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&models.Backorder{ID: bo.ID}).Update("status", models.BackorderCancelled).Error; err != nil {
+				return err
+			}
+		}
+		if err := saveStock(tx, byProduct, byLocation); err != nil {
 			return err
 		}
+		released = true
+		return tx.Model(&key).Update("request_hash", releasedRequestHash).Error
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	return released, restored, nil
+}
 
-		newQty := inventory.Quantity + change
-		if newQty < 0 {
-			return errors.New("insufficient stock")
+// InitializeStock creates the row for a new product, holding all of quantity
+// at locationCode. A row that already exists (redelivered event, or stock
+// added before the event arrived) is left untouched and no movement is
+// recorded.
+func (r *postgresRepo) InitializeStock(ctx context.Context, productID, locationCode string, quantity int32, info models.MovementInfo) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inventory := models.Inventory{ProductID: productID, Quantity: quantity}
+		result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "product_id"}}, DoNothing: true}).
+			Create(&inventory)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
 		}
-		inventory.Quantity = newQty
-		return tx.Save(&inventory).Error
+		if err := tx.Create(&models.LocationStock{ProductID: productID, LocationCode: locationCode, Quantity: quantity}).Error; err != nil {
+			return err
+		}
+		return recordMovement(tx, productID, locationCode, quantity, quantity, info)
+	})
+}
+
+// ArchiveStock soft-deletes the row so it drops out of stock lookups but is
+// kept for history.
+func (r *postgresRepo) ArchiveStock(ctx context.Context, productID string) error {
+	return r.db.WithContext(ctx).Where("product_id = ?", productID).Delete(&models.Inventory{}).Error
+}
+
+func (r *postgresRepo) RestoreStock(ctx context.Context, productID string) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.Inventory{}).
+		Where("product_id = ?", productID).
+		Update("deleted_at", nil).Error
+}
+
+func (r *postgresRepo) ListMovements(ctx context.Context, q models.MovementQuery) ([]*models.StockMovement, error) {
+	query := r.db.WithContext(ctx).Model(&models.StockMovement{})
+	if q.ProductID != "" {
+		query = query.Where("product_id = ?", q.ProductID)
+	}
+	if q.ReferenceID != "" {
+		query = query.Where("reference_id = ?", q.ReferenceID)
+	}
+	if q.BeforeID > 0 {
+		query = query.Where("id < ?", q.BeforeID)
+	}
+
+	var movements []*models.StockMovement
+	if err := query.Order("id DESC").Limit(q.Limit).Find(&movements).Error; err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+// ReconcileStock compares the stored quantity and the sum of the location
+// rows with the ledger total while holding the row lock. With apply set it
+// overwrites the stored quantity and moves the location rows by their
+// difference from the ledger: stock found goes to locationCode, stock lost
+// comes out of locationCode first and then the other locations by code,
+// expired lots first.
+func (r *postgresRepo) ReconcileStock(ctx context.Context, productID, locationCode string, apply bool) (*models.Reconciliation, error) {
+	var rec *models.Reconciliation
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var inventory models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ?", productID).
+			First(&inventory).Error; err != nil {
+			return err
+		}
+
+		var ledger int32
+		if err := tx.Model(&models.StockMovement{}).
+			Where("product_id = ?", productID).
+			Select("COALESCE(SUM(delta), 0)").
+			Scan(&ledger).Error; err != nil {
+			return err
+		}
+		var stocks []*models.LocationStock
+		if err := tx.Where("product_id = ?", productID).Order("location_code").Find(&stocks).Error; err != nil {
+			return err
+		}
+		var located int32
+		for _, ls := range stocks {
+			located += ls.Quantity
+		}
+
+		rec = &models.Reconciliation{ProductID: productID, StoredQuantity: inventory.Quantity, LedgerQuantity: ledger, LocationQuantity: located}
+		if !apply || ledger == inventory.Quantity && ledger == located {
+			return nil
+		}
+		if ledger > located {
+			var ls models.LocationStock
+			if err := updateLocationStock(tx, &ls, productID, locationCode, ledger-located); err != nil {
+				return err
+			}
+		}
+		if ledger < located {
+			if i := slices.IndexFunc(stocks, func(ls *models.LocationStock) bool { return ls.LocationCode == locationCode }); i > 0 {
+				first := stocks[i]
+				stocks = slices.Insert(slices.Delete(stocks, i, i+1), 0, first)
+			}
+			need := located - ledger
+			for _, ls := range stocks {
+				take := min(need, ls.Quantity)
+				if take == 0 {
+					continue
+				}
+				if err := updateLocationStock(tx, ls, productID, ls.LocationCode, -take); err != nil {
+					return err
+				}
+				if err := countLots(tx, productID, ls.LocationCode, -take, ls.Quantity); err != nil {
+					return err
+				}
+				need -= take
+			}
+			if need > 0 {
+				return ErrInsufficientStock
+			}
+		}
+		if err := tx.Model(&inventory).Update("quantity", ledger).Error; err != nil {
+			return err
+		}
+		rec.Applied = true
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &inventory, nil
+	return rec, nil
+}
+
+// BackfillOpeningBalances gives every row that predates the ledger a single
+// opening-balance movement, so ledger totals match stored quantities. It is
+// safe to run repeatedly.
+func (r *postgresRepo) BackfillOpeningBalances(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		INSERT INTO stock_movements (product_id, delta, quantity_after, reason, reference_id, actor, created_at)
+		SELECT i.product_id, i.quantity, i.quantity, ?, '', 'system', NOW()
+		FROM inventories i
+		WHERE i.quantity <> 0
+		  AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = i.product_id)`,
+		models.ReasonOpeningBalance)
+	return result.RowsAffected, result.Error
+}
+
+func (r *postgresRepo) DeleteIdempotencyKeysBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", before).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
+
+// CreateLocation inserts a new location; an existing code is
+// ErrLocationExists.
+func (r *postgresRepo) CreateLocation(ctx context.Context, location *models.Location) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).
+		Create(location)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLocationExists
+	}
+	return nil
+}
+
+// EnsureLocation creates location unless its code already exists, in which
+// case the stored row is left as it is.
+func (r *postgresRepo) EnsureLocation(ctx context.Context, location *models.Location) error {
+	if err := r.CreateLocation(ctx, location); err != nil && !errors.Is(err, ErrLocationExists) {
+		return err
+	}
+	return nil
+}
+
+func (r *postgresRepo) GetLocation(ctx context.Context, code string) (*models.Location, error) {
+	var location models.Location
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&location).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrLocationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (r *postgresRepo) ListLocations(ctx context.Context, includeInactive bool) ([]*models.Location, error) {
+	query := r.db.WithContext(ctx).Order("code")
+	if !includeInactive {
+		query = query.Where("active")
+	}
+	var locations []*models.Location
+	if err := query.Find(&locations).Error; err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+// MoveStockToLocation puts the whole quantity of every product that has no
+// location rows yet at locationCode. It migrates stock that predates
+// locations and is safe to run repeatedly.
+func (r *postgresRepo) MoveStockToLocation(ctx context.Context, locationCode string) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		INSERT INTO location_stocks (product_id, location_code, quantity, updated_at)
+		SELECT i.product_id, ?, i.quantity, NOW()
+		FROM inventories i
+		WHERE NOT EXISTS (SELECT 1 FROM location_stocks ls WHERE ls.product_id = i.product_id)`,
+		locationCode)
+	return result.RowsAffected, result.Error
+}
+
+// ListInventory returns one page of stock rows in q's sort order, resuming
+// after q.AfterValue and q.AfterID. q.Sort must be one of the models.Sort*
+// keys; the service checks it.
+func (r *postgresRepo) ListInventory(ctx context.Context, q models.InventoryQuery) ([]*models.Inventory, error) {
+	query := r.db.WithContext(ctx).Model(&models.Inventory{})
+	if q.WithArchived {
+		query = query.Unscoped()
+	}
+	switch q.Filter {
+	case models.FilterBelowThreshold:
+		query = query.Where("reorder_point > 0 AND quantity <= reorder_point")
+	case models.FilterZeroStock:
+		query = query.Where("quantity = 0")
+	}
+	if !q.UpdatedSince.IsZero() {
+		query = query.Where("updated_at >= ?", q.UpdatedSince)
+	}
+
+	dir, cmp := "ASC", ">"
+	if q.Descending {
+		dir, cmp = "DESC", "<"
+	}
+	if q.AfterID > 0 {
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", q.Sort, cmp), q.AfterValue, q.AfterID)
+	}
+
+	var inventories []*models.Inventory
+	if err := query.Order(fmt.Sprintf("%s %s, id %s", q.Sort, dir, dir)).Limit(q.Limit).Find(&inventories).Error; err != nil {
+		return nil, err
+	}
+	return inventories, nil
 }
@@ -0,0 +1,936 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var testMovement = models.MovementInfo{Reason: models.ReasonAdjustment, Actor: "test"}
+
+const testLocation = "TEST"
+
+// Set INVENTORY_TEST_DB_DSN to a disposable Postgres database to run these.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("INVENTORY_TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("INVENTORY_TEST_DB_DSN not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Inventory{}, &models.StockMovement{}, &models.IdempotencyKey{}, &models.LocationStock{},
+		&models.StockTransfer{}, &models.StockTransferLine{}, &models.StockAlertLog{}, &models.Backorder{},
+		&models.Supplier{}, &models.PurchaseOrder{}, &models.PurchaseOrderLine{},
+		&models.DailySales{}, &models.RecordedOrder{}, &models.Lot{},
+		&models.FlashSale{}, &models.FlashSalePurchase{}, &models.OutboxEvent{}, &models.ScheduledRun{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func TestUpdateStockConcurrent(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+	})
+
+	const workers = 50
+	const stock = 100
+
+	// Every goroutine races to create the row for a brand new product
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := repo.UpdateStock(ctx, productID, testLocation, stock/workers, testMovement, ""); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("initial stock: %v", err)
+	}
+
+	inv, err := repo.GetStock(ctx, productID)
+	if err != nil {
+		t.Fatalf("get stock: %v", err)
+	}
+	if inv.Quantity != stock {
+		t.Fatalf("quantity after concurrent inserts = %d, want %d", inv.Quantity, stock)
+	}
+
+	// Twice as many checkouts as there is stock; exactly half may succeed
+	var sold, rejected atomic.Int32
+	for i := 0; i < stock*2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := repo.UpdateStock(ctx, productID, testLocation, -1, testMovement, "")
+			switch {
+			case err == nil:
+				sold.Add(1)
+			case errors.Is(err, ErrInsufficientStock):
+				rejected.Add(1)
+			default:
+				t.Errorf("deduct: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if sold.Load() != stock || rejected.Load() != stock {
+		t.Fatalf("sold %d, rejected %d; want %d each", sold.Load(), rejected.Load(), stock)
+	}
+	inv, err = repo.GetStock(ctx, productID)
+	if err != nil {
+		t.Fatalf("get stock: %v", err)
+	}
+	if inv.Quantity != 0 {
+		t.Fatalf("final quantity = %d, want 0", inv.Quantity)
+	}
+
+	rec, err := repo.ReconcileStock(ctx, productID, testLocation, false)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if rec.LedgerQuantity != rec.StoredQuantity {
+		t.Fatalf("ledger total = %d, stored = %d", rec.LedgerQuantity, rec.StoredQuantity)
+	}
+}
+
+func TestAdjustStockBatchConcurrent(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	a, b := fmt.Sprintf("test-a-%d", suffix), fmt.Sprintf("test-b-%d", suffix)
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id IN ?", []string{a, b}).Delete(&models.Inventory{})
+		db.Where("product_id IN ?", []string{a, b}).Delete(&models.StockMovement{})
+		db.Where("product_id IN ?", []string{a, b}).Delete(&models.LocationStock{})
+	})
+
+	const stock = 40
+	for _, id := range []string{a, b} {
+		if _, _, err := repo.UpdateStock(ctx, id, testLocation, stock, testMovement, ""); err != nil {
+			t.Fatalf("seed %s: %v", id, err)
+		}
+	}
+
+	// Opposite line orders would deadlock without a fixed lock order
+	var wg sync.WaitGroup
+	var applied atomic.Int32
+	for i := 0; i < stock*2; i++ {
+		lines := []models.StockAdjustment{{ProductID: a, LocationCode: testLocation, Change: -1}, {ProductID: b, LocationCode: testLocation, Change: -1}}
+		if i%2 == 1 {
+			lines[0], lines[1] = lines[1], lines[0]
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.AdjustStockBatch(ctx, lines, testMovement, "")
+			switch {
+			case err == nil:
+				applied.Add(1)
+			case errors.Is(err, ErrAdjustmentRejected):
+			default:
+				t.Errorf("adjust: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if applied.Load() != stock {
+		t.Fatalf("applied %d batches, want %d", applied.Load(), stock)
+	}
+	for _, id := range []string{a, b} {
+		inv, err := repo.GetStock(ctx, id)
+		if err != nil {
+			t.Fatalf("get stock: %v", err)
+		}
+		if inv.Quantity != 0 {
+			t.Fatalf("%s quantity = %d, want 0", id, inv.Quantity)
+		}
+	}
+}
+
+func TestUpdateStockIdempotent(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	requestID := "req-" + productID
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+		db.Where("request_id = ?", requestID).Delete(&models.IdempotencyKey{})
+	})
+
+	if _, _, err := repo.UpdateStock(ctx, productID, testLocation, 10, testMovement, ""); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	// Concurrent retries of one request must apply it exactly once
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			inv, _, err := repo.UpdateStock(ctx, productID, testLocation, -3, testMovement, requestID)
+			if err != nil {
+				t.Errorf("deduct: %v", err)
+				return
+			}
+			if inv.Quantity != 7 {
+				t.Errorf("replayed quantity = %d, want 7", inv.Quantity)
+			}
+		}()
+	}
+	wg.Wait()
+
+	inv, err := repo.GetStock(ctx, productID)
+	if err != nil {
+		t.Fatalf("get stock: %v", err)
+	}
+	if inv.Quantity != 7 {
+		t.Fatalf("quantity = %d, want 7", inv.Quantity)
+	}
+
+	if _, _, err := repo.UpdateStock(ctx, productID, testLocation, -4, testMovement, requestID); !errors.Is(err, ErrRequestIDReused) {
+		t.Fatalf("reused request id with other arguments: err = %v, want ErrRequestIDReused", err)
+	}
+}
+
+func TestReserveStockConcurrent(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+	})
+
+	const perLocation = 20
+	locations := []string{testLocation, testLocation + "-2"}
+	for _, loc := range locations {
+		if _, _, err := repo.UpdateStock(ctx, productID, loc, perLocation, testMovement, ""); err != nil {
+			t.Fatalf("seed %s: %v", loc, err)
+		}
+	}
+
+	// Take one unit from whichever location has most; each plan must see
+	// the quantities left by the reservations before it
+	req := models.AllocationRequest{Items: []models.AllocationItem{{ProductID: productID, Quantity: 1}}}
+	plan := func(stocks []*models.LocationStock) *models.AllocationPlan {
+		var best *models.LocationStock
+		for _, ls := range stocks {
+			if best == nil || ls.Quantity > best.Quantity {
+				best = ls
+			}
+		}
+		if best == nil || best.Quantity == 0 {
+			return &models.AllocationPlan{Shortfalls: []models.Shortfall{{ProductID: productID, Missing: 1}}}
+		}
+		return &models.AllocationPlan{Allocations: []models.Allocation{{ProductID: productID, LocationCode: best.LocationCode, Quantity: 1}}}
+	}
+
+	var wg sync.WaitGroup
+	var reserved atomic.Int32
+	for i := 0; i < perLocation*3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.ReserveStock(ctx, req, plan, testMovement, "")
+			switch {
+			case err == nil:
+				reserved.Add(1)
+			case errors.Is(err, ErrAdjustmentRejected):
+			default:
+				t.Errorf("reserve: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved.Load() != perLocation*2 {
+		t.Fatalf("reserved %d, want %d", reserved.Load(), perLocation*2)
+	}
+	inv, err := repo.GetStock(ctx, productID)
+	if err != nil {
+		t.Fatalf("get stock: %v", err)
+	}
+	stocks, err := repo.GetLocationStocks(ctx, []string{productID})
+	if err != nil {
+		t.Fatalf("get location stocks: %v", err)
+	}
+	if inv.Quantity != 0 {
+		t.Fatalf("total = %d, want 0", inv.Quantity)
+	}
+	for _, ls := range stocks {
+		if ls.Quantity != 0 {
+			t.Fatalf("%s quantity = %d, want 0", ls.LocationCode, ls.Quantity)
+		}
+	}
+}
+
+func TestTransferShipAndReceive(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	from, to := testLocation, testLocation+"-2"
+	transfer := &models.StockTransfer{
+		FromLocation: from,
+		ToLocation:   to,
+		Status:       models.TransferPending,
+		CreatedBy:    "test",
+		Lines:        []models.StockTransferLine{{ProductID: productID, Quantity: 8}},
+	}
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+		db.Where("product_id = ?", productID).Delete(&models.Lot{})
+		db.Where("transfer_id = ?", transfer.ID).Delete(&models.StockTransferLine{})
+		db.Where("id = ?", transfer.ID).Delete(&models.StockTransfer{})
+	})
+
+	// 6 of the 10 units are in a lot, and ship first
+	lotted := testMovement
+	lotted.Lot = &models.LotInfo{Code: "L1", ExpiresOn: time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 30)}
+	if _, _, err := repo.UpdateStock(ctx, productID, from, 6, lotted, ""); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if _, _, err := repo.UpdateStock(ctx, productID, from, 4, testMovement, ""); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := repo.CreateTransfer(ctx, transfer, ""); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := repo.ReceiveTransfer(ctx, transfer.ID, nil, "test"); !errors.Is(err, ErrTransferState) {
+		t.Fatalf("receive before ship: err = %v, want ErrTransferState", err)
+	}
+	if _, err := repo.ShipTransfer(ctx, transfer.ID, "test"); err != nil {
+		t.Fatalf("ship: %v", err)
+	}
+
+	// Two units went missing on the way
+	received := []models.ReceivedLine{{ProductID: productID, Quantity: 6, Note: "damaged"}}
+	got, err := repo.ReceiveTransfer(ctx, transfer.ID, received, "test")
+	if err != nil {
+		t.Fatalf("receive: %v", err)
+	}
+	if got.Status != models.TransferReceived || got.Lines[0].Discrepancy() != 2 {
+		t.Fatalf("status %s, discrepancy %d; want received, 2", got.Status, got.Lines[0].Discrepancy())
+	}
+
+	stocks, err := repo.GetLocationStocks(ctx, []string{productID})
+	if err != nil {
+		t.Fatalf("get location stocks: %v", err)
+	}
+	want := map[string]int32{from: 2, to: 6}
+	for _, ls := range stocks {
+		if ls.Quantity != want[ls.LocationCode] {
+			t.Fatalf("%s quantity = %d, want %d", ls.LocationCode, ls.Quantity, want[ls.LocationCode])
+		}
+	}
+	lots, err := repo.ListLots(ctx, models.LotQuery{ProductID: productID})
+	if err != nil {
+		t.Fatalf("list lots: %v", err)
+	}
+	if len(lots) != 1 || lots[0].LocationCode != to || lots[0].Code != "L1" || lots[0].Quantity != 6 {
+		t.Fatalf("lots = %+v, want L1 with 6 at %s", lots, to)
+	}
+	rec, err := repo.ReconcileStock(ctx, productID, testLocation, false)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if rec.StoredQuantity != 8 || rec.LedgerQuantity != 8 {
+		t.Fatalf("stored %d, ledger %d; want 8 each", rec.StoredQuantity, rec.LedgerQuantity)
+	}
+}
+
+func TestEvaluateStockAlertsDebounced(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+		db.Where("product_id = ?", productID).Delete(&models.StockAlertLog{})
+		db.Where("event_id = ?", productID).Delete(&models.OutboxEvent{})
+	})
+	announce := func(alert models.StockAlert) (*models.OutboxEvent, error) {
+		return models.NewOutboxEvent(alert.ProductID, alert.Type, time.Now(), alert)
+	}
+
+	evaluate := func(change int32) []string {
+		t.Helper()
+		if _, _, err := repo.UpdateStock(ctx, productID, testLocation, change, testMovement, ""); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		alerts, err := repo.EvaluateStockAlerts(ctx, []string{productID}, time.Hour, announce)
+		if err != nil {
+			t.Fatalf("evaluate: %v", err)
+		}
+		var types []string
+		for _, a := range alerts {
+			types = append(types, a.Type)
+		}
+		return types
+	}
+
+	if got := evaluate(10); len(got) != 0 {
+		t.Fatalf("first evaluation alerted %v", got)
+	}
+	if _, err := repo.SetReorderPoint(ctx, productID, 5); err != nil {
+		t.Fatalf("set reorder point: %v", err)
+	}
+	if got := evaluate(-6); len(got) != 1 || got[0] != models.StockLow {
+		t.Fatalf("crossing the reorder point alerted %v, want [%s]", got, models.StockLow)
+	}
+	if got := evaluate(+6); len(got) != 0 {
+		t.Fatalf("recovering alerted %v", got)
+	}
+	// Dropping again inside the window is not reported twice
+	if got := evaluate(-6); len(got) != 0 {
+		t.Fatalf("flapping alerted %v", got)
+	}
+	var saved int64
+	if err := db.Model(&models.OutboxEvent{}).Where("event_id = ?", productID).Count(&saved).Error; err != nil {
+		t.Fatalf("count outbox: %v", err)
+	}
+	if saved != 1 {
+		t.Fatalf("outbox holds %d alerts, want 1", saved)
+	}
+}
+
+func TestImportStockCounts(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	other := testLocation + "-2"
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+	})
+
+	if _, _, err := repo.UpdateStock(ctx, productID, testLocation, 10, testMovement, ""); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	counts := []models.StockCount{
+		{ProductID: productID, LocationCode: testLocation, Counted: 7},
+		{ProductID: productID, LocationCode: other, Counted: 4},
+	}
+	info := models.MovementInfo{Reason: models.ReasonCycleCount, Actor: "test"}
+
+	// A dry run writes no rows, not even for a new product or its request id
+	fresh := productID + "-new"
+	preview, err := repo.ImportStockCounts(ctx, []models.StockCount{{ProductID: fresh, LocationCode: testLocation, Counted: 3}}, info, "req-"+fresh, true)
+	if err != nil || !preview[0].NewProduct || preview[0].Delta != 3 {
+		t.Fatalf("preview = %+v, err = %v; want a new product gaining 3", preview, err)
+	}
+	var inventories, keys int64
+	db.Unscoped().Model(&models.Inventory{}).Where("product_id = ?", fresh).Count(&inventories)
+	db.Model(&models.IdempotencyKey{}).Where("request_id = ?", "req-"+fresh).Count(&keys)
+	if inventories != 0 || keys != 0 {
+		t.Fatalf("dry run wrote %d inventory and %d idempotency rows", inventories, keys)
+	}
+
+	// A dry run reports the same differences but changes nothing
+	for _, dryRun := range []bool{true, false} {
+		results, err := repo.ImportStockCounts(ctx, counts, info, "", dryRun)
+		if err != nil {
+			t.Fatalf("import (dry run %v): %v", dryRun, err)
+		}
+		if results[0].Delta != -3 || results[1].Delta != 4 || results[1].Previous != 0 {
+			t.Fatalf("dry run %v: deltas %d, %d; want -3, 4", dryRun, results[0].Delta, results[1].Delta)
+		}
+		inv, err := repo.GetStock(ctx, productID)
+		if err != nil {
+			t.Fatalf("get stock: %v", err)
+		}
+		want := int32(11)
+		if dryRun {
+			want = 10
+		}
+		if inv.Quantity != want {
+			t.Fatalf("dry run %v: quantity = %d, want %d", dryRun, inv.Quantity, want)
+		}
+	}
+
+	rec, err := repo.ReconcileStock(ctx, productID, testLocation, false)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if rec.StoredQuantity != 11 || rec.LedgerQuantity != 11 {
+		t.Fatalf("stored %d, ledger %d; want 11 each", rec.StoredQuantity, rec.LedgerQuantity)
+	}
+}
+
+func TestBackorderFilledWhenStockArrives(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+		db.Where("product_id = ?", productID).Delete(&models.Backorder{})
+		db.Where("event_id = ?", productID).Delete(&models.OutboxEvent{})
+	})
+
+	if _, _, err := repo.UpdateStock(ctx, productID, testLocation, 2, testMovement, ""); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := repo.SetAvailability(ctx, productID, models.AvailabilityBackorder, 5, nil); err != nil {
+		t.Fatalf("set availability: %v", err)
+	}
+	reserve := func(quantity int32, reference string) (*models.AllocationPlan, error) {
+		req := models.AllocationRequest{Items: []models.AllocationItem{{ProductID: productID, Quantity: quantity}}, AllowBackorder: true}
+		return repo.ReserveStock(ctx, req, func(stocks []*models.LocationStock) *models.AllocationPlan {
+			plan := &models.AllocationPlan{}
+			for _, ls := range stocks {
+				if take := min(quantity, ls.Quantity); take > 0 {
+					plan.Allocations = append(plan.Allocations, models.Allocation{ProductID: productID, LocationCode: ls.LocationCode, Quantity: take})
+					quantity -= take
+				}
+			}
+			if quantity > 0 {
+				plan.Shortfalls = append(plan.Shortfalls, models.Shortfall{ProductID: productID, Missing: quantity})
+			}
+			return plan
+		}, models.MovementInfo{Reason: models.ReasonSale, ReferenceID: reference, Actor: "test"}, "")
+	}
+
+	// 2 ship now, 4 wait; a further 2 would pass the limit of 5
+	plan, err := reserve(6, "order-1")
+	if err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if len(plan.Backorders) != 1 || plan.Backorders[0].Quantity != 4 {
+		t.Fatalf("backorders = %+v, want 4 units", plan.Backorders)
+	}
+	if _, err := reserve(2, "order-2"); !errors.Is(err, ErrAdjustmentRejected) {
+		t.Fatalf("reserve past limit: err = %v, want ErrAdjustmentRejected", err)
+	}
+
+	// 3 units don't cover the backorder, so they are held rather than sold
+	if _, _, err := repo.UpdateStock(ctx, productID, testLocation, 3, testMovement, ""); err != nil {
+		t.Fatalf("restock: %v", err)
+	}
+	announce := func(fill models.BackorderFill) (*models.OutboxEvent, error) {
+		return models.NewOutboxEvent(productID, models.BackorderFilledEvent, time.Now(), fill)
+	}
+	if fills, err := repo.FillBackorders(ctx, []string{productID}, announce); err != nil || len(fills) != 0 {
+		t.Fatalf("fill with 3 in hand: %d fills, err %v; want none", len(fills), err)
+	}
+	if _, _, err := repo.UpdateStock(ctx, productID, testLocation+"-2", 2, testMovement, ""); err != nil {
+		t.Fatalf("restock: %v", err)
+	}
+	fills, err := repo.FillBackorders(ctx, []string{productID}, announce)
+	if err != nil || len(fills) != 1 || fills[0].Backorder.ReferenceID != "order-1" || len(fills[0].Allocations) != 2 {
+		t.Fatalf("fills = %+v, err %v; want order-1 filled from two locations", fills, err)
+	}
+	var events int64
+	if err := db.Model(&models.OutboxEvent{}).Where("event_id = ?", productID).Count(&events).Error; err != nil || events != 1 {
+		t.Fatalf("outbox events = %d, err %v; want the fill announced once", events, err)
+	}
+
+	inv, err := repo.GetStock(ctx, productID)
+	if err != nil {
+		t.Fatalf("get stock: %v", err)
+	}
+	if inv.Quantity != 1 || inv.Backordered != 0 {
+		t.Fatalf("quantity %d, backordered %d; want 1, 0", inv.Quantity, inv.Backordered)
+	}
+}
+
+func TestReleaseStock(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	requestID := "release-" + productID
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+		db.Where("product_id = ?", productID).Delete(&models.Backorder{})
+		db.Where("request_id IN ?", []string{requestID, requestID + "-late"}).Delete(&models.IdempotencyKey{})
+	})
+
+	if _, _, err := repo.UpdateStock(ctx, productID, testLocation, 2, testMovement, ""); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := repo.SetAvailability(ctx, productID, models.AvailabilityBackorder, 5, nil); err != nil {
+		t.Fatalf("set availability: %v", err)
+	}
+	info := models.MovementInfo{Reason: models.ReasonSale, ReferenceID: "order-1", Actor: "test"}
+	req := models.AllocationRequest{Items: []models.AllocationItem{{ProductID: productID, Quantity: 5}}, AllowBackorder: true}
+	plan := func(stocks []*models.LocationStock) *models.AllocationPlan {
+		return &models.AllocationPlan{
+			Allocations: []models.Allocation{{ProductID: productID, LocationCode: testLocation, Quantity: 2}},
+			Shortfalls:  []models.Shortfall{{ProductID: productID, Missing: 3}},
+		}
+	}
+	if _, err := repo.ReserveStock(ctx, req, plan, info, requestID); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+
+	release := models.MovementInfo{Reason: models.ReasonRelease, ReferenceID: "order-1", Actor: "test"}
+	released, restored, err := repo.ReleaseStock(ctx, requestID, release)
+	if err != nil || !released || len(restored) != 1 || restored[0].Quantity != 2 {
+		t.Fatalf("release = %v, %+v, err %v; want 2 units put back", released, restored, err)
+	}
+	inv, err := repo.GetStock(ctx, productID)
+	if err != nil {
+		t.Fatalf("get stock: %v", err)
+	}
+	if inv.Quantity != 2 || inv.Backordered != 0 {
+		t.Fatalf("quantity %d, backordered %d; want 2, 0", inv.Quantity, inv.Backordered)
+	}
+	if released, _, err := repo.ReleaseStock(ctx, requestID, release); err != nil || released {
+		t.Fatalf("second release = %v, err %v; want nothing released", released, err)
+	}
+
+	// Released before it arrived, a reservation can't go through any more
+	if released, _, err := repo.ReleaseStock(ctx, requestID+"-late", release); err != nil || released {
+		t.Fatalf("release of unknown request = %v, err %v; want nothing released", released, err)
+	}
+	if _, err := repo.ReserveStock(ctx, req, plan, info, requestID+"-late"); !errors.Is(err, ErrRequestIDReused) {
+		t.Fatalf("late reserve: err = %v, want ErrRequestIDReused", err)
+	}
+}
+
+func TestPurchaseOrderPartialReceive(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	expected := time.Now().UTC().AddDate(0, 0, 7).Truncate(time.Second)
+	po := &models.PurchaseOrder{
+		SupplierCode: "test-supplier",
+		LocationCode: testLocation,
+		Status:       models.PurchaseOrderOpen,
+		ExpectedAt:   &expected,
+		CreatedBy:    "test",
+		Lines:        []models.PurchaseOrderLine{{ProductID: productID, Quantity: 10, UnitCost: 2.5}},
+	}
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+		db.Where("purchase_order_id = ?", po.ID).Delete(&models.PurchaseOrderLine{})
+		db.Where("id = ?", po.ID).Delete(&models.PurchaseOrder{})
+		db.Where("request_id = ?", "receive-"+productID).Delete(&models.IdempotencyKey{})
+	})
+
+	if err := repo.CreatePurchaseOrder(ctx, po, ""); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// The retried delivery is only added once
+	var got *models.PurchaseOrder
+	for range 2 {
+		var err error
+		got, err = repo.ReceivePurchaseOrder(ctx, po.ID, []models.Delivery{{ProductID: productID, Quantity: 4}}, "test", "receive-"+productID)
+		if err != nil {
+			t.Fatalf("receive: %v", err)
+		}
+	}
+	if got.Status != models.PurchaseOrderPartiallyReceived || got.Lines[0].Outstanding() != 6 {
+		t.Fatalf("status %s, outstanding %d; want partially_received, 6", got.Status, got.Lines[0].Outstanding())
+	}
+	incoming, err := repo.IncomingStock(ctx, []string{productID})
+	if err != nil {
+		t.Fatalf("incoming: %v", err)
+	}
+	if len(incoming) != 1 || incoming[0].Quantity != 6 || !incoming[0].NextExpectedAt.Equal(expected) {
+		t.Fatalf("incoming = %+v, want 6 expected at %s", incoming, expected)
+	}
+
+	if _, err := repo.ReceivePurchaseOrder(ctx, po.ID, []models.Delivery{{ProductID: productID, Quantity: 7}}, "test", ""); !errors.Is(err, ErrOverReceived) {
+		t.Fatalf("over-receive: err = %v, want ErrOverReceived", err)
+	}
+	got, err = repo.ReceivePurchaseOrder(ctx, po.ID, []models.Delivery{{ProductID: productID, Quantity: 6}}, "test", "")
+	if err != nil {
+		t.Fatalf("receive rest: %v", err)
+	}
+	if got.Status != models.PurchaseOrderReceived || got.ReceivedAt == nil {
+		t.Fatalf("status %s, received at %v; want received", got.Status, got.ReceivedAt)
+	}
+	if _, err := repo.CancelPurchaseOrder(ctx, po.ID); !errors.Is(err, ErrPurchaseOrderState) {
+		t.Fatalf("cancel received: err = %v, want ErrPurchaseOrderState", err)
+	}
+
+	inv, err := repo.GetStock(ctx, productID)
+	if err != nil {
+		t.Fatalf("get stock: %v", err)
+	}
+	if inv.Quantity != 10 {
+		t.Fatalf("quantity = %d, want 10", inv.Quantity)
+	}
+}
+
+func TestRecordSalesCountsOrderOnce(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	orderIDs := []int64{time.Now().UnixNano(), time.Now().UnixNano() + 1}
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	t.Cleanup(func() {
+		db.Where("product_id = ?", productID).Delete(&models.DailySales{})
+		db.Where("order_id IN ?", orderIDs).Delete(&models.RecordedOrder{})
+	})
+
+	for _, orderID := range []int64{orderIDs[0], orderIDs[1], orderIDs[0]} {
+		if _, err := repo.RecordSales(ctx, orderID, []models.DailySales{{ProductID: productID, Day: day, Quantity: 3}}); err != nil {
+			t.Fatalf("record order %d: %v", orderID, err)
+		}
+	}
+
+	sales, err := repo.ListDailySales(ctx, []string{productID}, day)
+	if err != nil {
+		t.Fatalf("list sales: %v", err)
+	}
+	if len(sales) != 1 || sales[0].Quantity != 6 || !sales[0].Day.Equal(day) {
+		t.Fatalf("sales = %+v, want 6 on %s", sales, day)
+	}
+}
+
+func TestLotsFirstExpiredFirstOut(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+		db.Where("product_id = ?", productID).Delete(&models.Lot{})
+	})
+
+	receive := func(quantity int32, lot *models.LotInfo) {
+		t.Helper()
+		info := testMovement
+		info.Lot = lot
+		if _, _, err := repo.UpdateStock(ctx, productID, testLocation, quantity, info, ""); err != nil {
+			t.Fatalf("receive: %v", err)
+		}
+	}
+	receive(5, &models.LotInfo{Code: "LATE", ExpiresOn: today.AddDate(0, 0, 10)})
+	receive(5, &models.LotInfo{Code: "EARLY", ExpiresOn: today.AddDate(0, 0, 5)})
+	receive(3, nil)
+
+	if _, _, err := repo.UpdateStock(ctx, productID, testLocation, -7, testMovement, ""); err != nil {
+		t.Fatalf("deduct: %v", err)
+	}
+	lots, err := repo.ListLots(ctx, models.LotQuery{ProductID: productID})
+	if err != nil {
+		t.Fatalf("list lots: %v", err)
+	}
+	if len(lots) != 1 || lots[0].Code != "LATE" || lots[0].Quantity != 3 {
+		t.Fatalf("lots = %+v, want only LATE with 3 left", lots)
+	}
+
+	// 2 of the 3 unlotted units turn out to be an expired lot
+	if err := db.Create(&models.Lot{ProductID: productID, LocationCode: testLocation, Code: "OLD",
+		ExpiresOn: today.AddDate(0, 0, -1), Quantity: 2, Status: models.LotActive}).Error; err != nil {
+		t.Fatalf("seed expired lot: %v", err)
+	}
+	if _, _, err := repo.UpdateStock(ctx, productID, testLocation, -5, testMovement, ""); !errors.Is(err, ErrLotExpired) {
+		t.Fatalf("deduct into expired lot: err = %v, want ErrLotExpired", err)
+	}
+	unsellable, err := repo.ExpiredLotStock(ctx, []string{productID})
+	if err != nil {
+		t.Fatalf("expired lot stock: %v", err)
+	}
+	if len(unsellable) != 1 || unsellable[0].LocationCode != testLocation || unsellable[0].Quantity != 2 {
+		t.Fatalf("expired lot stock = %+v, want 2 at %s", unsellable, testLocation)
+	}
+	if _, _, err := repo.UpdateStock(ctx, productID, testLocation, -4, testMovement, ""); err != nil {
+		t.Fatalf("deduct: %v", err)
+	}
+
+	expired, err := repo.ExpireLots(ctx, today)
+	if err != nil {
+		t.Fatalf("expire lots: %v", err)
+	}
+	if len(expired) != 1 || expired[0].Code != "OLD" || expired[0].Quantity != 2 {
+		t.Fatalf("expired = %+v, want OLD with 2 written off", expired)
+	}
+	inv, err := repo.GetStock(ctx, productID)
+	if err != nil {
+		t.Fatalf("get stock: %v", err)
+	}
+	if inv.Quantity != 0 {
+		t.Fatalf("quantity = %d, want 0", inv.Quantity)
+	}
+}
+
+func TestFlashSaleReconcileAndEnd(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	sale := &models.FlashSale{ProductID: productID, LocationCode: testLocation, Quantity: 10, Status: models.FlashSaleScheduled,
+		StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour), CreatedBy: "test"}
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+		db.Where("flash_sale_id = ?", sale.ID).Delete(&models.FlashSalePurchase{})
+		db.Delete(sale)
+	})
+
+	if _, _, err := repo.UpdateStock(ctx, productID, testLocation, 15, testMovement, ""); err != nil {
+		t.Fatalf("seed stock: %v", err)
+	}
+	if err := repo.CreateFlashSale(ctx, sale); err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+	if _, err := repo.StartFlashSale(ctx, sale.ID); err != nil {
+		t.Fatalf("start sale: %v", err)
+	}
+
+	// r1 is logged twice, as happens when a reconcile is retried; r2 is
+	// refunded and bought again under the same request id
+	log := []models.FlashSaleEntry{
+		{RequestID: "r1", UserID: "u1", Quantity: 2},
+		{RequestID: "r2", UserID: "u2", Quantity: 3},
+		{RequestID: "r1", UserID: "u1", Quantity: 2},
+		{Refund: true, RequestID: "r2", UserID: "u2"},
+		{RequestID: "r2", UserID: "u2", Quantity: 1},
+	}
+	fetch := func(offset int64) ([]models.FlashSaleEntry, error) {
+		return log[min(int(offset), len(log)):], nil
+	}
+	got, n, err := repo.ReconcileFlashSale(ctx, sale.ID, fetch)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if n != len(log) || got.Sold != 3 {
+		t.Fatalf("applied %d with %d sold, want %d with 3 sold", n, got.Sold, len(log))
+	}
+	if _, n, err = repo.ReconcileFlashSale(ctx, sale.ID, fetch); err != nil || n != 0 {
+		t.Fatalf("second reconcile applied %d, err = %v; want nothing", n, err)
+	}
+
+	if _, err := repo.EndFlashSale(ctx, sale.ID); err != nil {
+		t.Fatalf("end sale: %v", err)
+	}
+	stocks, err := repo.GetLocationStocks(ctx, []string{productID})
+	if err != nil {
+		t.Fatalf("location stocks: %v", err)
+	}
+	for _, ls := range stocks {
+		want := int32(12)
+		if models.IsFlashSaleHold(ls.LocationCode) {
+			want = 0
+		}
+		if ls.Quantity != want {
+			t.Fatalf("%s holds %d, want %d", ls.LocationCode, ls.Quantity, want)
+		}
+	}
+}
+
+func TestReconcileStockKeepsLocationsInStep(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPostgresRepository(db)
+	ctx := context.Background()
+	productID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	other := testLocation + "-B"
+	t.Cleanup(func() {
+		db.Unscoped().Where("product_id = ?", productID).Delete(&models.Inventory{})
+		db.Where("product_id = ?", productID).Delete(&models.StockMovement{})
+		db.Where("product_id = ?", productID).Delete(&models.LocationStock{})
+	})
+	for _, loc := range []string{testLocation, other} {
+		if _, _, err := repo.UpdateStock(ctx, productID, loc, 5, testMovement, ""); err != nil {
+			t.Fatalf("seed %s: %v", loc, err)
+		}
+	}
+
+	locations := func() map[string]int32 {
+		t.Helper()
+		stocks, err := repo.GetLocationStocks(ctx, []string{productID})
+		if err != nil {
+			t.Fatalf("location stocks: %v", err)
+		}
+		byCode := make(map[string]int32)
+		for _, ls := range stocks {
+			byCode[ls.LocationCode] = ls.Quantity
+		}
+		return byCode
+	}
+
+	// Rows edited by hand past the ledger: 3 too many at the default
+	// location, and a total that agrees with neither
+	db.Model(&models.LocationStock{}).Where("product_id = ? AND location_code = ?", productID, testLocation).Update("quantity", 8)
+	db.Model(&models.Inventory{}).Where("product_id = ?", productID).Update("quantity", 20)
+	rec, err := repo.ReconcileStock(ctx, productID, testLocation, true)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if !rec.Applied || rec.StoredQuantity != 20 || rec.LedgerQuantity != 10 || rec.LocationQuantity != 13 {
+		t.Fatalf("rec = %+v, want stored 20, ledger 10, locations 13, applied", rec)
+	}
+	if got := locations(); got[testLocation] != 5 || got[other] != 5 {
+		t.Fatalf("locations = %v, want 5 at each", got)
+	}
+
+	// More missing than the default location holds comes from the others
+	db.Model(&models.LocationStock{}).Where("product_id = ? AND location_code = ?", productID, testLocation).Update("quantity", 0)
+	db.Model(&models.LocationStock{}).Where("product_id = ? AND location_code = ?", productID, other).Update("quantity", 12)
+	if _, err := repo.ReconcileStock(ctx, productID, testLocation, true); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if got := locations(); got[testLocation] != 0 || got[other] != 10 {
+		t.Fatalf("locations = %v, want 10 at %s only", got, other)
+	}
+
+	// Stock the ledger has but no location does lands at the default one
+	db.Model(&models.LocationStock{}).Where("product_id = ? AND location_code = ?", productID, other).Update("quantity", 6)
+	if _, err := repo.ReconcileStock(ctx, productID, testLocation, true); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if got := locations(); got[testLocation] != 4 || got[other] != 6 {
+		t.Fatalf("locations = %v, want 4 and 6", got)
+	}
+	inv, err := repo.GetStock(ctx, productID)
+	if err != nil || inv.Quantity != 10 {
+		t.Fatalf("stock = %+v, err = %v; want 10", inv, err)
+	}
+}
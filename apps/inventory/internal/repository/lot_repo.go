@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLotExpired means a deduction could only be met from lots past their
+// expiry date, which are blocked until they are written off.
+var ErrLotExpired = errors.New("remaining stock is in expired lots")
+
+// ErrLotMismatch means stock was added to a lot code already received with a
+// different expiry date, or already expired.
+var ErrLotMismatch = errors.New("lot was received with a different expiry date or has expired")
+
+// trackLots keeps a location's lots in step with a change of delta that
+// left locationAfter there. Added stock goes into lot, if given. Removed
+// stock comes out of the active lots that expire first; what they can't
+// cover comes from unlotted stock, which must not leave lots past their
+// expiry holding more than is at the location. The lots removed stock came
+// out of are returned, for addLots wherever it arrives. Callers must hold
+// the product's inventories row lock.
+func trackLots(tx *gorm.DB, productID, locationCode string, delta, locationAfter int32, lot *models.LotInfo) ([]models.LotPortion, error) {
+	if delta > 0 {
+		if lot == nil {
+			return nil, nil
+		}
+		result := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "product_id"}, {Name: "location_code"}, {Name: "code"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"quantity":   gorm.Expr("lots.quantity + EXCLUDED.quantity"),
+				"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+			}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "lots.expires_on = EXCLUDED.expires_on AND lots.status = ?", Vars: []any{models.LotActive}},
+			}},
+		}).Create(&models.Lot{
+			ProductID:    productID,
+			LocationCode: locationCode,
+			Code:         lot.Code,
+			ExpiresOn:    lot.ExpiresOn,
+			Quantity:     delta,
+			Status:       models.LotActive,
+		})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, fmt.Errorf("%w: lot %s of product %s at %s", ErrLotMismatch, lot.Code, productID, locationCode)
+		}
+		return nil, nil
+	}
+	if delta == 0 {
+		return nil, nil
+	}
+
+	var lots []*models.Lot
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND location_code = ? AND status = ? AND quantity > 0", productID, locationCode, models.LotActive).
+		Order("expires_on, id").
+		Find(&lots).Error; err != nil {
+		return nil, err
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	need := -delta
+	var held, expired int32
+	var taken []models.LotPortion
+	for _, l := range lots {
+		if l.Expired(today) {
+			held += l.Quantity
+			expired += l.Quantity
+			continue
+		}
+		if take := min(need, l.Quantity); take > 0 {
+			l.Quantity -= take
+			need -= take
+			taken = append(taken, models.LotPortion{Code: l.Code, ExpiresOn: l.ExpiresOn, Quantity: take})
+			if err := tx.Model(l).Update("quantity", l.Quantity).Error; err != nil {
+				return nil, err
+			}
+		}
+		held += l.Quantity
+	}
+	if expired > 0 && held > locationAfter {
+		return nil, fmt.Errorf("%w: product %s at %s", ErrLotExpired, productID, locationCode)
+	}
+	return taken, nil
+}
+
+// expiredLotStock sums what active lots past their expiry date still hold
+// of productIDs at each location. That stock can't be sold and waits to be
+// written off by ExpireLots.
+func expiredLotStock(tx *gorm.DB, productIDs []string) (map[locationKey]int32, error) {
+	var rows []struct {
+		ProductID    string
+		LocationCode string
+		Quantity     int32
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if err := tx.Model(&models.Lot{}).
+		Select("product_id, location_code, SUM(quantity) AS quantity").
+		Where("product_id IN ? AND status = ? AND quantity > 0 AND expires_on < ?", productIDs, models.LotActive, today).
+		Group("product_id, location_code").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	expired := make(map[locationKey]int32, len(rows))
+	for _, row := range rows {
+		expired[locationKey{row.ProductID, row.LocationCode}] = row.Quantity
+	}
+	return expired, nil
+}
+
+// ExpiredLotStock returns the stock of productIDs in expired lots awaiting
+// write-off, one row per product and location.
+func (r *postgresRepo) ExpiredLotStock(ctx context.Context, productIDs []string) ([]*models.LocationStock, error) {
+	byLocation, err := expiredLotStock(r.db.WithContext(ctx), productIDs)
+	if err != nil {
+		return nil, err
+	}
+	stocks := make([]*models.LocationStock, 0, len(byLocation))
+	for key, quantity := range byLocation {
+		stocks = append(stocks, &models.LocationStock{ProductID: key.productID, LocationCode: key.locationCode, Quantity: quantity})
+	}
+	return stocks, nil
+}
+
+// countLots is trackLots for a stock count that left counted at the
+// location: stock found missing is taken to be expired lots thrown away
+// first, then comes out of lots as usual.
+func countLots(tx *gorm.DB, productID, locationCode string, delta, counted int32) error {
+	if delta < 0 {
+		var lots []*models.Lot
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ? AND location_code = ? AND status = ? AND quantity > 0 AND expires_on < ?", productID, locationCode, models.LotActive, today).
+			Order("expires_on, id").
+			Find(&lots).Error; err != nil {
+			return err
+		}
+		for _, l := range lots {
+			take := min(-delta, l.Quantity)
+			if take == 0 {
+				break
+			}
+			l.Quantity -= take
+			delta += take
+			if err := tx.Model(l).Update("quantity", l.Quantity).Error; err != nil {
+				return err
+			}
+		}
+	}
+	_, err := trackLots(tx, productID, locationCode, delta, counted, nil)
+	return err
+}
+
+// addLots puts stock that arrived at a location into the lots trackLots
+// took it out of elsewhere; the rest of it arrives unlotted. Callers must
+// hold the product's inventories row lock.
+func addLots(tx *gorm.DB, productID, locationCode string, lots []models.LotPortion) error {
+	for _, l := range lots {
+		if _, err := trackLots(tx, productID, locationCode, l.Quantity, 0, &models.LotInfo{Code: l.Code, ExpiresOn: l.ExpiresOn}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstLots returns the first quantity units of lots, for when only part of
+// some stock arrived.
+func firstLots(lots []models.LotPortion, quantity int32) []models.LotPortion {
+	var first []models.LotPortion
+	for _, l := range lots {
+		if quantity <= 0 {
+			break
+		}
+		l.Quantity = min(l.Quantity, quantity)
+		quantity -= l.Quantity
+		first = append(first, l)
+	}
+	return first
+}
+
+// ExpireLots blocks every active lot whose expiry date is before day,
+// writing its stock off with an "expired" ledger entry. A lot is written off
+// only as far as its location still holds stock. Lots of archived products
+// are left until the product is restored.
+func (r *postgresRepo) ExpireLots(ctx context.Context, day time.Time) ([]*models.Lot, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).Model(&models.Lot{}).
+		Where("status = ? AND expires_on < ?", models.LotActive, day).
+		Distinct().
+		Order("product_id").
+		Pluck("product_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var expired []*models.Lot
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired = nil
+		byProduct, byLocation, err := lockStock(tx, ids)
+		if err != nil {
+			return err
+		}
+		var lots []*models.Lot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id IN ? AND status = ? AND expires_on < ?", ids, models.LotActive, day).
+			Order("product_id, location_code, id").
+			Find(&lots).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, l := range lots {
+			inv, ok := byProduct[l.ProductID]
+			if !ok {
+				continue
+			}
+			var writeOff int32
+			if ls, ok := byLocation[locationKey{l.ProductID, l.LocationCode}]; ok {
+				writeOff = min(l.Quantity, ls.Quantity)
+				ls.Quantity -= writeOff
+				inv.Quantity -= writeOff
+			}
+			if writeOff > 0 {
+				info := models.MovementInfo{Reason: models.ReasonExpired, ReferenceID: "lot-" + l.Code, Actor: "system"}
+				if err := recordMovement(tx, l.ProductID, l.LocationCode, -writeOff, inv.Quantity, info); err != nil {
+					return err
+				}
+			}
+			l.Quantity, l.Status, l.ExpiredAt = writeOff, models.LotExpired, &now
+			if err := tx.Model(l).Select("quantity", "status", "expired_at").Updates(l).Error; err != nil {
+				return err
+			}
+			expired = append(expired, l)
+		}
+		return saveStock(tx, byProduct, byLocation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// ListLots returns the lots matching q: by expiry date with ExpiresBefore,
+// otherwise by location and expiry date. Emptied active lots are left out.
+func (r *postgresRepo) ListLots(ctx context.Context, q models.LotQuery) ([]*models.Lot, error) {
+	query := r.db.WithContext(ctx).Model(&models.Lot{})
+	if q.ProductID != "" {
+		query = query.Where("product_id = ?", q.ProductID)
+	}
+	if q.LocationCode != "" {
+		query = query.Where("location_code = ?", q.LocationCode)
+	}
+	if q.IncludeExpired {
+		query = query.Where("(status = ? AND quantity > 0) OR status = ?", models.LotActive, models.LotExpired)
+	} else {
+		query = query.Where("status = ? AND quantity > 0", models.LotActive)
+	}
+	if q.ExpiresBefore != nil {
+		query = query.Where("expires_on < ?", *q.ExpiresBefore).Order("expires_on, product_id, location_code")
+	} else {
+		query = query.Order("location_code, expires_on, id")
+	}
+
+	var lots []*models.Lot
+	if err := query.Find(&lots).Error; err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PublishOutboxEvents hands up to limit saved events to publish, oldest
+// first, and deletes those it accepted. It stops at the first failure and
+// returns it, leaving that event and the ones after it for the next call.
+// Events are locked while being published, so replicas relaying at the same
+// time skip each other's.
+func (r *postgresRepo) PublishOutboxEvents(ctx context.Context, limit int, publish func(*models.OutboxEvent) error) (int, error) {
+	var published int
+	var publishErr error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		published, publishErr = 0, nil
+		var events []*models.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Order("id").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		var done []int64
+		for _, event := range events {
+			if publishErr = publish(event); publishErr != nil {
+				break
+			}
+			done = append(done, event.ID)
+		}
+		if len(done) == 0 {
+			return nil
+		}
+		published = len(done)
+		return tx.Delete(&models.OutboxEvent{}, done).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, publishErr
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSupplierExists   = errors.New("supplier code already exists")
+	ErrSupplierNotFound = errors.New("supplier not found")
+)
+
+var ErrPurchaseOrderNotFound = errors.New("purchase order not found")
+
+// ErrPurchaseOrderState means the purchase order's status doesn't allow the
+// requested step, e.g. receiving a cancelled one.
+var ErrPurchaseOrderState = errors.New("purchase order is not in a state that allows this")
+
+// ErrOverReceived means a delivery brought more of a product than the
+// purchase order still expects.
+var ErrOverReceived = errors.New("delivery exceeds the outstanding quantity")
+
+// CreateSupplier inserts a new supplier; an existing code is
+// ErrSupplierExists.
+func (r *postgresRepo) CreateSupplier(ctx context.Context, supplier *models.Supplier) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).
+		Create(supplier)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSupplierExists
+	}
+	return nil
+}
+
+// UpdateSupplier overwrites every detail of the supplier with supplier.Code,
+// empty and false values included, then reloads supplier from the stored row
+// for its id and timestamps.
+func (r *postgresRepo) UpdateSupplier(ctx context.Context, supplier *models.Supplier) error {
+	result := r.db.WithContext(ctx).Model(&models.Supplier{}).
+		Where("code = ?", supplier.Code).
+		Select("name", "email", "phone", "lead_time_days", "active").
+		Updates(supplier)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSupplierNotFound
+	}
+	return r.db.WithContext(ctx).Where("code = ?", supplier.Code).First(supplier).Error
+}
+
+func (r *postgresRepo) GetSupplier(ctx context.Context, code string) (*models.Supplier, error) {
+	var supplier models.Supplier
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&supplier).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSupplierNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+func (r *postgresRepo) ListSuppliers(ctx context.Context, includeInactive bool) ([]*models.Supplier, error) {
+	query := r.db.WithContext(ctx).Order("code")
+	if !includeInactive {
+		query = query.Where("active")
+	}
+	var suppliers []*models.Supplier
+	if err := query.Find(&suppliers).Error; err != nil {
+		return nil, err
+	}
+	return suppliers, nil
+}
+
+func (r *postgresRepo) CreatePurchaseOrder(ctx context.Context, po *models.PurchaseOrder, requestID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		prior, err := claimRequest(tx, requestID, "CreatePurchaseOrder", po)
+		if err != nil {
+			return err
+		}
+		if prior != nil {
+			return json.Unmarshal(prior.Response, po)
+		}
+		if err := tx.Create(po).Error; err != nil {
+			return err
+		}
+		return completeRequest(tx, requestID, po)
+	})
+}
+
+func (r *postgresRepo) GetPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error) {
+	return getPurchaseOrder(r.db.WithContext(ctx), id, false)
+}
+
+// getPurchaseOrder loads a purchase order with its lines, optionally locking
+// it so only one delivery or cancellation can run at a time.
+func getPurchaseOrder(tx *gorm.DB, id int64, lock bool) (*models.PurchaseOrder, error) {
+	query := tx
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var po models.PurchaseOrder
+	err := query.Where("id = ?", id).First(&po).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPurchaseOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Where("purchase_order_id = ?", id).Order("product_id").Find(&po.Lines).Error; err != nil {
+		return nil, err
+	}
+	return &po, nil
+}
+
+func (r *postgresRepo) ListPurchaseOrders(ctx context.Context, q models.PurchaseOrderQuery) ([]*models.PurchaseOrder, error) {
+	query := r.db.WithContext(ctx).Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("product_id") })
+	if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+	if q.SupplierCode != "" {
+		query = query.Where("supplier_code = ?", q.SupplierCode)
+	}
+	if q.BeforeID > 0 {
+		query = query.Where("id < ?", q.BeforeID)
+	}
+
+	var orders []*models.PurchaseOrder
+	if err := query.Order("id DESC").Limit(q.Limit).Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// ReceivePurchaseOrder adds a delivery to the purchase order's location,
+// with ledger entries referencing the order. Products not in deliveries
+// didn't arrive this time. The order is received once nothing is
+// outstanding. A non-empty requestID makes the call idempotent, so a
+// retried delivery is only added once.
+func (r *postgresRepo) ReceivePurchaseOrder(ctx context.Context, id int64, deliveries []models.Delivery, actor, requestID string) (*models.PurchaseOrder, error) {
+	var po *models.PurchaseOrder
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		prior, err := claimRequest(tx, requestID, "ReceivePurchaseOrder", id, deliveries, actor)
+		if err != nil {
+			return err
+		}
+		if prior != nil {
+			return json.Unmarshal(prior.Response, &po)
+		}
+		if po, err = getPurchaseOrder(tx, id, true); err != nil {
+			return err
+		}
+		if po.Status != models.PurchaseOrderOpen && po.Status != models.PurchaseOrderPartiallyReceived {
+			return ErrPurchaseOrderState
+		}
+
+		info := models.MovementInfo{Reason: models.ReasonPurchase, ReferenceID: fmt.Sprintf("purchase-order-%d", po.ID), Actor: actor}
+		// Lines are in product_id order, the order every multi-product write locks in
+		for i := range po.Lines {
+			line := &po.Lines[i]
+			j := slices.IndexFunc(deliveries, func(d models.Delivery) bool { return d.ProductID == line.ProductID })
+			if j < 0 || deliveries[j].Quantity == 0 {
+				continue
+			}
+			if deliveries[j].Quantity > line.Outstanding() {
+				return fmt.Errorf("%w: %d of product %s outstanding", ErrOverReceived, line.Outstanding(), line.ProductID)
+			}
+			info.Lot = deliveries[j].Lot
+			if _, err := moveTransferStock(tx, line.ProductID, po.LocationCode, deliveries[j].Quantity, info); err != nil {
+				return err
+			}
+			line.ReceivedQuantity += deliveries[j].Quantity
+			if err := tx.Model(line).Update("received_quantity", line.ReceivedQuantity).Error; err != nil {
+				return err
+			}
+		}
+
+		po.Status = models.PurchaseOrderReceived
+		if slices.ContainsFunc(po.Lines, func(l models.PurchaseOrderLine) bool { return l.Outstanding() > 0 }) {
+			po.Status = models.PurchaseOrderPartiallyReceived
+		} else {
+			now := time.Now()
+			po.ReceivedAt = &now
+		}
+		if err := tx.Model(po).Select("status", "received_at").Updates(po).Error; err != nil {
+			return err
+		}
+		return completeRequest(tx, requestID, po)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+// CancelPurchaseOrder gives up on whatever the purchase order still
+// expects. Stock already received stays.
+func (r *postgresRepo) CancelPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error) {
+	var po *models.PurchaseOrder
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if po, err = getPurchaseOrder(tx, id, true); err != nil {
+			return err
+		}
+		if po.Status != models.PurchaseOrderOpen && po.Status != models.PurchaseOrderPartiallyReceived {
+			return ErrPurchaseOrderState
+		}
+		now := time.Now()
+		po.Status, po.CancelledAt = models.PurchaseOrderCancelled, &now
+		return tx.Model(po).Select("status", "cancelled_at").Updates(po).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+// IncomingStock sums what open and partially received purchase orders still
+// expect of productIDs. Products with nothing incoming are left out.
+func (r *postgresRepo) IncomingStock(ctx context.Context, productIDs []string) ([]models.IncomingStock, error) {
+	var incoming []models.IncomingStock
+	err := r.db.WithContext(ctx).Table("purchase_order_lines AS l").
+		Select("l.product_id, SUM(l.quantity - l.received_quantity) AS quantity, MIN(po.expected_at) AS next_expected_at").
+		Joins("JOIN purchase_orders po ON po.id = l.purchase_order_id").
+		Where("l.product_id IN ? AND po.status IN ? AND l.quantity > l.received_quantity", productIDs,
+			[]string{models.PurchaseOrderOpen, models.PurchaseOrderPartiallyReceived}).
+		Group("l.product_id").
+		Order("l.product_id").
+		Scan(&incoming).Error
+	if err != nil {
+		return nil, err
+	}
+	return incoming, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *postgresRepo) SetReorderPoint(ctx context.Context, productID string, reorderPoint int32) (*models.Inventory, error) {
+	var inventory models.Inventory
+	result := r.db.WithContext(ctx).Model(&inventory).Clauses(clause.Returning{}).
+		Where("product_id = ?", productID).
+		Update("reorder_point", reorderPoint)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &inventory, nil
+}
+
+// EvaluateStockAlerts re-classifies the stock of productIDs and returns the
+// alerts for those whose state changed. An alert of a type already sent for
+// the product within debounce is dropped, though the new state is still
+// stored. A product's first evaluation only records its state. The event
+// announce returns for an alert is saved to the outbox in the same
+// transaction as the alert's debounce log, so an alert that is never
+// published doesn't hold back the next one.
+func (r *postgresRepo) EvaluateStockAlerts(ctx context.Context, productIDs []string, debounce time.Duration, announce func(models.StockAlert) (*models.OutboxEvent, error)) ([]models.StockAlert, error) {
+	ids := slices.Clone(productIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	var alerts []models.StockAlert
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		alerts = nil
+		var rows []*models.Inventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id IN ?", ids).
+			Order("product_id").
+			Find(&rows).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, inv := range rows {
+			state := models.StockStateOf(inv.Quantity, inv.ReorderPoint)
+			if state == inv.StockState {
+				continue
+			}
+			if err := tx.Model(inv).Update("stock_state", state).Error; err != nil {
+				return err
+			}
+
+			for _, alertType := range alertTypes(inv.StockState, state) {
+				var last models.StockAlertLog
+				err := tx.Where("product_id = ? AND type = ?", inv.ProductID, alertType).Limit(1).Find(&last).Error
+				if err != nil {
+					return err
+				}
+				if now.Sub(last.LastSentAt) < debounce {
+					continue
+				}
+				if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).
+					Create(&models.StockAlertLog{ProductID: inv.ProductID, Type: alertType, LastSentAt: now}).Error; err != nil {
+					return err
+				}
+				alert := models.StockAlert{
+					Type:         alertType,
+					ProductID:    inv.ProductID,
+					Quantity:     inv.Quantity,
+					ReorderPoint: inv.ReorderPoint,
+				}
+				if announce != nil {
+					event, err := announce(alert)
+					if err != nil {
+						return err
+					}
+					if err := tx.Create(event).Error; err != nil {
+						return err
+					}
+				}
+				alerts = append(alerts, alert)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// alertTypes lists the events a change of stock state calls for.
+func alertTypes(from, to string) []string {
+	if from == "" {
+		return nil
+	}
+	switch to {
+	case models.StockStateOut:
+		return []string{models.StockOut}
+	case models.StockStateLow:
+		if from == models.StockStateOut {
+			return []string{models.StockBackIn, models.StockLow}
+		}
+		return []string{models.StockLow}
+	case models.StockStateOK:
+		if from == models.StockStateOut {
+			return []string{models.StockBackIn}
+		}
+	}
+	return nil
+}
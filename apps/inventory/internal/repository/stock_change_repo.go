@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"gorm.io/gorm"
+)
+
+// InstallStockChangeTrigger makes every committed change to a product's
+// total quantity or reorder point send a models.StockChange on
+// models.StockChangeChannel. NOTIFY is only delivered on commit, so
+// listeners never see rolled-back changes. It is safe to run repeatedly,
+// and replicas starting together take turns rather than fail on each other's
+// catalog changes.
+func (r *postgresRepo) InstallStockChangeTrigger(ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('inventory-stock-change-trigger'))").Error; err != nil {
+			return err
+		}
+		for _, stmt := range []string{
+			fmt.Sprintf(`
+			CREATE OR REPLACE FUNCTION notify_stock_change() RETURNS trigger AS $$
+			BEGIN
+				IF TG_OP = 'UPDATE' AND OLD.quantity = NEW.quantity AND OLD.reorder_point = NEW.reorder_point THEN
+					RETURN NULL;
+				END IF;
+				PERFORM pg_notify('%s', json_build_object(
+					'product_id', NEW.product_id,
+					'quantity', NEW.quantity,
+					'reorder_point', NEW.reorder_point,
+					'updated_at', NEW.updated_at
+				)::text);
+				RETURN NULL;
+			END;
+			$$ LANGUAGE plpgsql`, models.StockChangeChannel),
+			`CREATE OR REPLACE TRIGGER inventories_notify_stock_change
+			AFTER INSERT OR UPDATE OF quantity, reorder_point ON inventories
+			FOR EACH ROW EXECUTE FUNCTION notify_stock_change()`,
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
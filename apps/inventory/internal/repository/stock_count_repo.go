@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ImportStockCounts sets each product's quantity at a location to the counted
+// one, in a single transaction, recording the difference as a movement with
+// info. Rows are locked in the same order as AdjustStockBatch. If any line
+// can't be applied nothing is changed and the results are returned with
+// ErrAdjustmentRejected. With dryRun the results are only read from a
+// snapshot, taking no locks and writing nothing; requestID is ignored.
+func (r *postgresRepo) ImportStockCounts(ctx context.Context, counts []models.StockCount, info models.MovementInfo, requestID string, dryRun bool) ([]models.StockCountResult, error) {
+	var ids []string
+	for _, c := range counts {
+		ids = append(ids, c.ProductID)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	if dryRun {
+		var results []models.StockCountResult
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			results, err = previewStockCounts(tx, counts, ids)
+			return err
+		}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+		if err != nil && !errors.Is(err, ErrAdjustmentRejected) {
+			return nil, err
+		}
+		return results, err
+	}
+
+	results := make([]models.StockCountResult, len(counts))
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		prior, err := claimRequest(tx, requestID, "ImportStockCounts", counts, info)
+		if err != nil {
+			return err
+		}
+		if prior != nil {
+			return json.Unmarshal(prior.Response, &results)
+		}
+
+		var known []string
+		if err := tx.Unscoped().Model(&models.Inventory{}).
+			Where("product_id IN ?", ids).
+			Pluck("product_id", &known).Error; err != nil {
+			return err
+		}
+
+		missing := make([]models.Inventory, len(ids))
+		for i, id := range ids {
+			missing[i] = models.Inventory{ProductID: id}
+		}
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "product_id"}}, DoNothing: true}).
+			Create(&missing).Error; err != nil {
+			return err
+		}
+		missingAt := make([]models.LocationStock, len(counts))
+		for i, c := range counts {
+			missingAt[i] = models.LocationStock{ProductID: c.ProductID, LocationCode: c.LocationCode}
+		}
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "product_id"}, {Name: "location_code"}}, DoNothing: true}).
+			Create(&missingAt).Error; err != nil {
+			return err
+		}
+
+		byProduct, byLocation, err := lockStock(tx, ids)
+		if err != nil {
+			return err
+		}
+
+		// Total quantity after each line, for the ledger
+		after := make([]int32, len(counts))
+		rejected := false
+		for i, c := range counts {
+			results[i] = models.StockCountResult{
+				ProductID:    c.ProductID,
+				LocationCode: c.LocationCode,
+				Counted:      c.Counted,
+				NewProduct:   !slices.Contains(known, c.ProductID),
+			}
+			inv, ok := byProduct[c.ProductID]
+			if !ok {
+				results[i].Message = ErrStockArchived.Error()
+				results[i].Reason = "STOCK_ARCHIVED"
+				rejected = true
+				continue
+			}
+			at := byLocation[locationKey{c.ProductID, c.LocationCode}]
+			results[i].Previous = at.Quantity
+			results[i].Delta = c.Counted - at.Quantity
+			inv.Quantity += results[i].Delta
+			at.Quantity = c.Counted
+			after[i] = inv.Quantity
+		}
+		if rejected {
+			return ErrAdjustmentRejected
+		}
+
+		if err := saveStock(tx, byProduct, byLocation); err != nil {
+			return err
+		}
+		for i, res := range results {
+			if res.Delta == 0 {
+				continue
+			}
+			if err := recordMovement(tx, res.ProductID, res.LocationCode, res.Delta, after[i], info); err != nil {
+				return err
+			}
+			if err := countLots(tx, res.ProductID, res.LocationCode, res.Delta, res.Counted); err != nil {
+				return err
+			}
+		}
+		return completeRequest(tx, requestID, results)
+	})
+	if errors.Is(err, ErrAdjustmentRejected) {
+		return results, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// previewStockCounts works out the results of ImportStockCounts from the
+// rows as they are, without creating or locking any.
+func previewStockCounts(tx *gorm.DB, counts []models.StockCount, ids []string) ([]models.StockCountResult, error) {
+	var inventories []*models.Inventory
+	if err := tx.Unscoped().Where("product_id IN ?", ids).Find(&inventories).Error; err != nil {
+		return nil, err
+	}
+	byProduct := make(map[string]*models.Inventory, len(inventories))
+	for _, inv := range inventories {
+		byProduct[inv.ProductID] = inv
+	}
+	var stocks []*models.LocationStock
+	if err := tx.Where("product_id IN ?", ids).Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	byLocation := make(map[locationKey]int32, len(stocks))
+	for _, ls := range stocks {
+		byLocation[locationKey{ls.ProductID, ls.LocationCode}] = ls.Quantity
+	}
+
+	results := make([]models.StockCountResult, len(counts))
+	rejected := false
+	for i, c := range counts {
+		inv, known := byProduct[c.ProductID]
+		results[i] = models.StockCountResult{
+			ProductID:    c.ProductID,
+			LocationCode: c.LocationCode,
+			Counted:      c.Counted,
+			NewProduct:   !known,
+		}
+		if known && inv.DeletedAt.Valid {
+			results[i].Message = ErrStockArchived.Error()
+			results[i].Reason = "STOCK_ARCHIVED"
+			rejected = true
+			continue
+		}
+		results[i].Previous = byLocation[locationKey{c.ProductID, c.LocationCode}]
+		results[i].Delta = c.Counted - results[i].Previous
+	}
+	if rejected {
+		return results, ErrAdjustmentRejected
+	}
+	return results, nil
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrTransferNotFound = errors.New("stock transfer not found")
+
+// ErrTransferState means the transfer's status doesn't allow the requested
+// step, e.g. receiving a transfer that was never shipped.
+var ErrTransferState = errors.New("stock transfer is not in a state that allows this")
+
+func (r *postgresRepo) CreateTransfer(ctx context.Context, transfer *models.StockTransfer, requestID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		prior, err := claimRequest(tx, requestID, "CreateTransfer", transfer)
+		if err != nil {
+			return err
+		}
+		if prior != nil {
+			return json.Unmarshal(prior.Response, transfer)
+		}
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+		return completeRequest(tx, requestID, transfer)
+	})
+}
+
+func (r *postgresRepo) GetTransfer(ctx context.Context, id int64) (*models.StockTransfer, error) {
+	return getTransfer(r.db.WithContext(ctx), id, false)
+}
+
+// getTransfer loads a transfer with its lines, optionally locking it so
+// only one status change can run at a time.
+func getTransfer(tx *gorm.DB, id int64, lock bool) (*models.StockTransfer, error) {
+	query := tx
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var transfer models.StockTransfer
+	err := query.Where("id = ?", id).First(&transfer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Where("transfer_id = ?", id).Order("id").Find(&transfer.Lines).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *postgresRepo) ListTransfers(ctx context.Context, q models.TransferQuery) ([]*models.StockTransfer, error) {
+	query := r.db.WithContext(ctx).Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") })
+	if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+	if q.Location != "" {
+		query = query.Where("from_location = ? OR to_location = ?", q.Location, q.Location)
+	}
+	if q.BeforeID > 0 {
+		query = query.Where("id < ?", q.BeforeID)
+	}
+
+	var transfers []*models.StockTransfer
+	if err := query.Order("id DESC").Limit(q.Limit).Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// ShipTransfer takes every line out of the source location. Either all lines
+// ship or, when one is short, none do. Lines are stored in product_id order,
+// so rows are locked in the same order as AdjustStockBatch locks them.
+func (r *postgresRepo) ShipTransfer(ctx context.Context, id int64, actor string) (*models.StockTransfer, error) {
+	var transfer *models.StockTransfer
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if transfer, err = getTransfer(tx, id, true); err != nil {
+			return err
+		}
+		if transfer.Status != models.TransferPending {
+			return ErrTransferState
+		}
+
+		info := transferMovement(transfer, models.ReasonTransferOut, actor)
+		for i := range transfer.Lines {
+			line := &transfer.Lines[i]
+			if line.Lots, err = moveTransferStock(tx, line.ProductID, transfer.FromLocation, -line.Quantity, info); err != nil {
+				return err
+			}
+			if err := tx.Model(line).Select("lots").Updates(line).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		transfer.Status, transfer.ShippedAt = models.TransferInTransit, &now
+		return tx.Model(transfer).Select("status", "shipped_at").Updates(transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// ReceiveTransfer adds what arrived to the destination location. Lines
+// missing from received are taken to have arrived in full; the difference
+// between shipped and received quantities is kept on each line.
+func (r *postgresRepo) ReceiveTransfer(ctx context.Context, id int64, received []models.ReceivedLine, actor string) (*models.StockTransfer, error) {
+	var transfer *models.StockTransfer
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if transfer, err = getTransfer(tx, id, true); err != nil {
+			return err
+		}
+		if transfer.Status != models.TransferInTransit {
+			return ErrTransferState
+		}
+
+		info := transferMovement(transfer, models.ReasonTransferIn, actor)
+		for i := range transfer.Lines {
+			line := &transfer.Lines[i]
+			line.ReceivedQuantity = line.Quantity
+			if j := slices.IndexFunc(received, func(rl models.ReceivedLine) bool { return rl.ProductID == line.ProductID }); j >= 0 {
+				line.ReceivedQuantity, line.Note = received[j].Quantity, received[j].Note
+			}
+			if line.ReceivedQuantity > 0 {
+				if _, err := moveTransferStock(tx, line.ProductID, transfer.ToLocation, line.ReceivedQuantity, info); err != nil {
+					return err
+				}
+				// What went missing is taken to be the stock that expires last
+				if err := addLots(tx, line.ProductID, transfer.ToLocation, firstLots(line.Lots, line.ReceivedQuantity)); err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(line).Select("received_quantity", "note").Updates(line).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		transfer.Status, transfer.ReceivedAt = models.TransferReceived, &now
+		return tx.Model(transfer).Select("status", "received_at").Updates(transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// CancelTransfer abandons a transfer that hasn't been received. Stock that
+// already shipped goes back to the source location.
+func (r *postgresRepo) CancelTransfer(ctx context.Context, id int64, actor string) (*models.StockTransfer, error) {
+	var transfer *models.StockTransfer
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if transfer, err = getTransfer(tx, id, true); err != nil {
+			return err
+		}
+		switch transfer.Status {
+		case models.TransferPending:
+		case models.TransferInTransit:
+			info := transferMovement(transfer, models.ReasonTransferIn, actor)
+			for _, line := range transfer.Lines {
+				if _, err := moveTransferStock(tx, line.ProductID, transfer.FromLocation, line.Quantity, info); err != nil {
+					return err
+				}
+				if err := addLots(tx, line.ProductID, transfer.FromLocation, line.Lots); err != nil {
+					return err
+				}
+			}
+		default:
+			return ErrTransferState
+		}
+
+		now := time.Now()
+		transfer.Status, transfer.CancelledAt = models.TransferCancelled, &now
+		return tx.Model(transfer).Select("status", "cancelled_at").Updates(transfer).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func transferMovement(transfer *models.StockTransfer, reason, actor string) models.MovementInfo {
+	return models.MovementInfo{Reason: reason, ReferenceID: fmt.Sprintf("transfer-%d", transfer.ID), Actor: actor}
+}
+
+// moveTransferStock applies one line of a transfer step or purchase order
+// delivery at a location, with the same row locking, ledger entry and lot
+// tracking as UpdateStock, and returns the lots removed stock came out of.
+// Archived products can't be moved.
+func moveTransferStock(tx *gorm.DB, productID, locationCode string, change int32, info models.MovementInfo) ([]models.LotPortion, error) {
+	var inventory models.Inventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ?", productID).
+		First(&inventory).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrStockArchived, productID)
+		}
+		return nil, err
+	}
+	if inventory.Quantity+change < 0 {
+		return nil, fmt.Errorf("%w for product %s at %s", ErrInsufficientStock, productID, locationCode)
+	}
+
+	var at models.LocationStock
+	if err := updateLocationStock(tx, &at, productID, locationCode, change); err != nil {
+		if errors.Is(err, ErrInsufficientStock) {
+			return nil, fmt.Errorf("%w for product %s at %s", ErrInsufficientStock, productID, locationCode)
+		}
+		return nil, err
+	}
+	inventory.Quantity += change
+	if err := tx.Model(&inventory).Update("quantity", inventory.Quantity).Error; err != nil {
+		return nil, err
+	}
+	if err := recordMovement(tx, productID, locationCode, change, inventory.Quantity, info); err != nil {
+		return nil, err
+	}
+	return trackLots(tx, productID, locationCode, change, at.Quantity, info.Lot)
+}
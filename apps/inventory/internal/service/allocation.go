@@ -0,0 +1,150 @@
+package service
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+// rankLocations orders locations from best to worst for shipping to
+// postcode: a location whose service area covers the postcode (longest
+// prefix first) beats one that doesn't, then the closer of the two-digit
+// postcode regions wins, then the lower Priority, then the code.
+func rankLocations(locations []*models.Location, postcode string) []*models.Location {
+	type ranked struct {
+		loc      *models.Location
+		match    int
+		distance int
+	}
+	rs := make([]ranked, len(locations))
+	for i, loc := range locations {
+		rs[i] = ranked{loc: loc, match: serviceAreaMatch(loc.ServiceArea, postcode), distance: regionDistance(loc.Postcode, postcode)}
+	}
+	slices.SortStableFunc(rs, func(a, b ranked) int {
+		return cmp.Or(
+			cmp.Compare(b.match, a.match),
+			cmp.Compare(a.distance, b.distance),
+			cmp.Compare(a.loc.Priority, b.loc.Priority),
+			strings.Compare(a.loc.Code, b.loc.Code),
+		)
+	})
+	out := make([]*models.Location, len(rs))
+	for i, r := range rs {
+		out[i] = r.loc
+	}
+	return out
+}
+
+// serviceAreaMatch returns the length of the longest prefix in area that
+// postcode starts with, or 0.
+func serviceAreaMatch(area []string, postcode string) int {
+	best := 0
+	for _, prefix := range area {
+		if prefix != "" && strings.HasPrefix(postcode, prefix) {
+			best = max(best, len(prefix))
+		}
+	}
+	return best
+}
+
+// unknownDistance ranks locations without a usable postcode after all others.
+const unknownDistance = 1000
+
+// regionDistance is a rough distance between two postcodes: how far apart
+// their first two digits are. Thai postcodes are grouped by province that
+// way, so nearby numbers are mostly nearby places.
+func regionDistance(a, b string) int {
+	if len(a) < 2 || len(b) < 2 {
+		return unknownDistance
+	}
+	ra, errA := strconv.Atoi(a[:2])
+	rb, errB := strconv.Atoi(b[:2])
+	if errA != nil || errB != nil {
+		return unknownDistance
+	}
+	if ra > rb {
+		return ra - rb
+	}
+	return rb - ra
+}
+
+// allocate plans where req's items ship from, using only stock at ranked
+// locations. Quantities for the same product are combined.
+func allocate(req models.AllocationRequest, ranked []*models.Location, stocks []*models.LocationStock) *models.AllocationPlan {
+	available := make(map[string]map[string]int32)
+	for _, ls := range stocks {
+		if ls.Quantity <= 0 {
+			continue
+		}
+		if available[ls.ProductID] == nil {
+			available[ls.ProductID] = make(map[string]int32)
+		}
+		available[ls.ProductID][ls.LocationCode] = ls.Quantity
+	}
+
+	var products []string
+	remaining := make(map[string]int32)
+	for _, item := range req.Items {
+		if _, ok := remaining[item.ProductID]; !ok {
+			products = append(products, item.ProductID)
+		}
+		remaining[item.ProductID] += item.Quantity
+	}
+
+	taken := make(map[string][]models.Allocation)
+	take := func(productID, code string, qty int32) {
+		taken[productID] = append(taken[productID], models.Allocation{ProductID: productID, LocationCode: code, Quantity: qty})
+		available[productID][code] -= qty
+		remaining[productID] -= qty
+	}
+
+	switch req.Strategy {
+	case models.StrategyFewestSplits:
+		// Greedily ship from whichever location covers the most of what is
+		// still missing, so a location that has everything is used alone
+		for {
+			var best *models.Location
+			var bestUnits int32
+			for _, loc := range ranked {
+				var units int32
+				for _, p := range products {
+					units += min(remaining[p], available[p][loc.Code])
+				}
+				if units > bestUnits {
+					best, bestUnits = loc, units
+				}
+			}
+			if best == nil {
+				break
+			}
+			for _, p := range products {
+				if qty := min(remaining[p], available[p][best.Code]); qty > 0 {
+					take(p, best.Code, qty)
+				}
+			}
+		}
+	default:
+		for _, p := range products {
+			for _, loc := range ranked {
+				if remaining[p] == 0 {
+					break
+				}
+				if qty := min(remaining[p], available[p][loc.Code]); qty > 0 {
+					take(p, loc.Code, qty)
+				}
+			}
+		}
+	}
+
+	plan := &models.AllocationPlan{}
+	for _, p := range products {
+		plan.Allocations = append(plan.Allocations, taken[p]...)
+		if remaining[p] > 0 {
+			plan.Shortfalls = append(plan.Shortfalls, models.Shortfall{ProductID: p, Missing: remaining[p]})
+		}
+	}
+	return plan
+}
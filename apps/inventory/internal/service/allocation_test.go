@@ -0,0 +1,136 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+func TestRegionDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"10110", "10400", 0},
+		{"10110", "50200", 40},
+		{"50200", "10110", 40},
+		{"9", "10110", unknownDistance},
+		{"", "10110", unknownDistance},
+		{"AB123", "10110", unknownDistance},
+		{"10110", "1x000", unknownDistance},
+	}
+	for _, tt := range tests {
+		if got := regionDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("regionDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func codes(locations []*models.Location) []string {
+	out := make([]string, len(locations))
+	for i, loc := range locations {
+		out[i] = loc.Code
+	}
+	return out
+}
+
+func TestRankLocations(t *testing.T) {
+	locations := []*models.Location{
+		{Code: "FAR", Postcode: "90110"},
+		{Code: "NEAR-B", Postcode: "12000", Priority: 1},
+		{Code: "NEAR-A", Postcode: "12000", Priority: 1},
+		{Code: "NEAR-FIRST", Postcode: "12000"},
+		{Code: "AREA", Postcode: "50200", ServiceArea: []string{"1"}},
+		{Code: "AREA-CLOSER", Postcode: "50200", ServiceArea: []string{"1", "101"}},
+		{Code: "NOWHERE"},
+	}
+	got := codes(rankLocations(locations, "10110"))
+	want := []string{"AREA-CLOSER", "AREA", "NEAR-FIRST", "NEAR-A", "NEAR-B", "FAR", "NOWHERE"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("ranked = %v, want %v", got, want)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	a := &models.Location{Code: "A"}
+	b := &models.Location{Code: "B"}
+	c := &models.Location{Code: "C"}
+	ranked := []*models.Location{a, b, c}
+	stocks := []*models.LocationStock{
+		{ProductID: "p1", LocationCode: "A", Quantity: 2},
+		{ProductID: "p1", LocationCode: "B", Quantity: 5},
+		{ProductID: "p2", LocationCode: "B", Quantity: 1},
+		{ProductID: "p1", LocationCode: "C", Quantity: 5},
+		{ProductID: "p2", LocationCode: "C", Quantity: 3},
+		{ProductID: "p3", LocationCode: "A", Quantity: -1},
+	}
+	items := []models.AllocationItem{{ProductID: "p1", Quantity: 3}, {ProductID: "p2", Quantity: 2}, {ProductID: "p1", Quantity: 1}}
+
+	tests := []struct {
+		name       string
+		strategy   string
+		items      []models.AllocationItem
+		want       []models.Allocation
+		shortfalls []models.Shortfall
+	}{
+		{
+			name:     "nearest takes each product from the best location first",
+			strategy: models.StrategyNearest,
+			items:    items,
+			want: []models.Allocation{
+				{ProductID: "p1", LocationCode: "A", Quantity: 2},
+				{ProductID: "p1", LocationCode: "B", Quantity: 2},
+				{ProductID: "p2", LocationCode: "B", Quantity: 1},
+				{ProductID: "p2", LocationCode: "C", Quantity: 1},
+			},
+		},
+		{
+			name:     "fewest splits ships from the location holding everything",
+			strategy: models.StrategyFewestSplits,
+			items:    items,
+			want: []models.Allocation{
+				{ProductID: "p1", LocationCode: "C", Quantity: 4},
+				{ProductID: "p2", LocationCode: "C", Quantity: 2},
+			},
+		},
+		{
+			name:     "shortfalls report what no location has",
+			strategy: models.StrategyNearest,
+			items:    []models.AllocationItem{{ProductID: "p2", Quantity: 6}, {ProductID: "p3", Quantity: 1}},
+			want: []models.Allocation{
+				{ProductID: "p2", LocationCode: "B", Quantity: 1},
+				{ProductID: "p2", LocationCode: "C", Quantity: 3},
+			},
+			shortfalls: []models.Shortfall{{ProductID: "p2", Missing: 2}, {ProductID: "p3", Missing: 1}},
+		},
+		{
+			name:       "fewest splits with a shortfall",
+			strategy:   models.StrategyFewestSplits,
+			items:      []models.AllocationItem{{ProductID: "p2", Quantity: 5}},
+			want:       []models.Allocation{{ProductID: "p2", LocationCode: "C", Quantity: 3}, {ProductID: "p2", LocationCode: "B", Quantity: 1}},
+			shortfalls: []models.Shortfall{{ProductID: "p2", Missing: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := allocate(models.AllocationRequest{Items: tt.items, Strategy: tt.strategy}, ranked, stocks)
+			if !slices.EqualFunc(plan.Allocations, tt.want, func(x, y models.Allocation) bool {
+				return x.ProductID == y.ProductID && x.LocationCode == y.LocationCode && x.Quantity == y.Quantity
+			}) {
+				t.Fatalf("allocations = %+v, want %+v", plan.Allocations, tt.want)
+			}
+			if !slices.Equal(plan.Shortfalls, tt.shortfalls) {
+				t.Fatalf("shortfalls = %+v, want %+v", plan.Shortfalls, tt.shortfalls)
+			}
+		})
+	}
+}
+
+func TestAllocateUsesOnlyRankedLocations(t *testing.T) {
+	stocks := []*models.LocationStock{{ProductID: "p1", LocationCode: "CLOSED", Quantity: 9}}
+	plan := allocate(models.AllocationRequest{Items: []models.AllocationItem{{ProductID: "p1", Quantity: 1}}}, []*models.Location{{Code: "A"}}, stocks)
+	if len(plan.Allocations) != 0 || plan.Fulfillable() {
+		t.Fatalf("plan = %+v, want a shortfall", plan)
+	}
+}
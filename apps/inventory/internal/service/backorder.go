@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+// SetAvailability copies the product's backorder and pre-order settings
+// from the product service.
+func (s *InventoryService) SetAvailability(ctx context.Context, productID, availability string, backorderLimit int32, releaseDate *time.Time) error {
+	if backorderLimit < 0 {
+		return newError(ErrInvalidArgument, "INVALID_BACKORDER_LIMIT", "backorder_limit cannot be negative")
+	}
+	return s.repo.SetAvailability(ctx, productID, availability, backorderLimit, releaseDate)
+}
+
+// queueBackorderFill has RunBackorderFiller look at the backorders and
+// stock alerts of productIDs, whose stock just changed.
+func (s *InventoryService) queueBackorderFill(productIDs []string) {
+	s.fillMu.Lock()
+	for _, id := range productIDs {
+		s.fillQueue[id] = true
+	}
+	s.fillMu.Unlock()
+	select {
+	case s.fillReady <- struct{}{}:
+	default:
+	}
+}
+
+// RunBackorderFiller fills backorders in the background until ctx is
+// cancelled, so stock changes don't wait for it: those of products queued by
+// this replica as they come in, and those of every product each interval,
+// covering queues lost with a replica that stopped. Queued products and
+// those a fill took stock of have their stock alerts evaluated afterwards.
+func (s *InventoryService) RunBackorderFiller(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.alertStockChange(ctx, s.fillBackorders(ctx, nil))
+		case <-s.fillReady:
+			s.fillMu.Lock()
+			ids := slices.Sorted(maps.Keys(s.fillQueue))
+			clear(s.fillQueue)
+			s.fillMu.Unlock()
+			if len(ids) > 0 {
+				s.fillBackorders(ctx, ids)
+				s.alertStockChange(ctx, ids)
+			}
+		}
+	}
+}
+
+// fillBackorders fills what open backorders of productIDs (all products when
+// empty) the stock in hand now covers. Each fill is announced through the
+// outbox, so the order waiting on it goes ahead even if the broker is down
+// right now. The products stock was taken of are returned.
+func (s *InventoryService) fillBackorders(ctx context.Context, productIDs []string) []string {
+	var announce func(models.BackorderFill) (*models.OutboxEvent, error)
+	if s.publisher != nil {
+		announce = backorderFilledEvent
+	}
+	fills, err := s.repo.FillBackorders(ctx, productIDs, announce)
+	if err != nil {
+		slog.Error("Failed to fill backorders", "products", len(productIDs), "error", err)
+		return nil
+	}
+	for _, fill := range fills {
+		slog.Info("Filled backorder", "product_id", fill.Backorder.ProductID, "reference_id", fill.Backorder.ReferenceID, "quantity", fill.Backorder.Quantity)
+	}
+	if len(fills) > 0 && announce != nil {
+		s.outboxSaved()
+	}
+	ids := make([]string, len(fills))
+	for i, fill := range fills {
+		ids[i] = fill.Backorder.ProductID
+	}
+	return ids
+}
+
+func backorderFilledEvent(fill models.BackorderFill) (*models.OutboxEvent, error) {
+	data := &models.BackorderEventData{
+		ReferenceID: fill.Backorder.ReferenceID,
+		ProductID:   fill.Backorder.ProductID,
+		Quantity:    fill.Backorder.Quantity,
+	}
+	for _, a := range fill.Allocations {
+		data.Allocations = append(data.Allocations, models.BackorderAllocation{Location: a.LocationCode, Quantity: a.Quantity})
+	}
+	event := &models.BackorderEvent{
+		ID:         newEventID(),
+		Type:       models.BackorderFilledEvent,
+		Version:    models.BackorderEventVersion,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+	return models.NewOutboxEvent(event.ID, event.Type, event.OccurredAt, event)
+}
@@ -0,0 +1,13 @@
+package service
+
+import "github.com/thapakon-thai/eshop-microservices/proto/grpcerr"
+
+// Failure kinds this service reports; handlers map them with grpcerr.Code.
+var (
+	ErrInvalidArgument    = grpcerr.InvalidArgument
+	ErrFailedPrecondition = grpcerr.FailedPrecondition
+)
+
+func newError(kind error, reason, format string, args ...any) error {
+	return grpcerr.New(kind, reason, format, args...)
+}
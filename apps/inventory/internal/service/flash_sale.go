@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/repository"
+)
+
+// FlashSaleStore keeps the counters of running flash sales outside
+// Postgres, so their purchases don't all wait on the product's inventories
+// row. Every purchase and refund is appended to the sale's log, which is
+// reconciled into the ledger in the background.
+type FlashSaleStore interface {
+	// Load sets up the sale's counters with what it has left to sell, unless
+	// they are set up already. The sale's log continues from
+	// sale.Reconciled. When sale.Loaded the counters are never set up again:
+	// sales still in the lost log would be sold twice, so Load fails with
+	// ErrFlashSaleLost instead.
+	Load(ctx context.Context, sale *models.FlashSale) error
+	// Purchase takes every item for user, or nothing. Purchasing again under
+	// a requestID that already took succeeds without taking more. When the
+	// sales can't cover the items the error is a *FlashSaleRejection.
+	Purchase(ctx context.Context, requestID, user, referenceID string, items []models.FlashSaleItem) error
+	// Refund gives back what requestID took from sales for user. Sales that
+	// are sealed or gone take no refund; their ids are returned, for the
+	// refund to be made in the ledger.
+	Refund(ctx context.Context, requestID, user string, sales []*models.FlashSale) ([]int64, error)
+	// Stop stops the sale selling. Refunds are still logged.
+	Stop(ctx context.Context, saleID int64) error
+	// Seal stops the sale logging refunds too, so its log can be read to
+	// the end.
+	Seal(ctx context.Context, saleID int64) error
+	// Entries returns up to limit entries of the sale's log from offset on.
+	Entries(ctx context.Context, saleID, offset int64, limit int) ([]models.FlashSaleEntry, error)
+	// Remove deletes the sale's counters and log.
+	Remove(ctx context.Context, saleID int64) error
+}
+
+// Reasons a FlashSaleStore rejects a purchase.
+const (
+	FlashSaleNotSelling   = "not_selling"
+	FlashSaleSoldOut      = "sold_out"
+	FlashSaleLimitReached = "limit_reached"
+)
+
+// ErrFlashSaleLost means the counters and log of a running flash sale are
+// gone from the store. The sale stops selling until it is ended.
+var ErrFlashSaleLost = errors.New("flash sale counters are lost")
+
+// FlashSaleRejection says why a flash sale purchase took nothing.
+type FlashSaleRejection struct {
+	SaleID    int64
+	Reason    string
+	Remaining int32 // Left to sell, or left for the user to buy
+}
+
+func (e *FlashSaleRejection) Error() string {
+	return fmt.Sprintf("flash sale %d rejected the purchase: %s", e.SaleID, e.Reason)
+}
+
+// flashSaleReconcileBatch caps how many log entries one transaction applies.
+const flashSaleReconcileBatch = 500
+
+// CreateFlashSale schedules a flash sale of the product at a location,
+// starting it right away when its start time has come. Sales of one product
+// can't overlap.
+func (s *InventoryService) CreateFlashSale(ctx context.Context, sale *models.FlashSale) (*models.FlashSale, error) {
+	if s.flashSales == nil {
+		return nil, newError(ErrFailedPrecondition, "FLASH_SALES_DISABLED", "flash sales are not enabled")
+	}
+	if sale.ProductID == "" {
+		return nil, newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+	}
+	if sale.Quantity <= 0 {
+		return nil, newError(ErrInvalidArgument, "INVALID_QUANTITY", "quantity must be positive")
+	}
+	if sale.PerUserLimit < 0 {
+		return nil, newError(ErrInvalidArgument, "INVALID_PER_USER_LIMIT", "per_user_limit cannot be negative")
+	}
+	now := time.Now()
+	if sale.StartsAt.IsZero() {
+		sale.StartsAt = now
+	}
+	if !sale.EndsAt.After(sale.StartsAt) || !sale.EndsAt.After(now) {
+		return nil, newError(ErrInvalidArgument, "INVALID_ENDS_AT", "ends_at must be in the future and after starts_at")
+	}
+	var err error
+	if sale.LocationCode, err = s.resolveLocation(ctx, sale.LocationCode); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetStock(ctx, sale.ProductID); err != nil {
+		return nil, err
+	}
+
+	others, err := s.repo.ListFlashSales(ctx, models.FlashSaleQuery{ProductID: sale.ProductID})
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range others {
+		if other.Status != models.FlashSaleEnded && other.StartsAt.Before(sale.EndsAt) && sale.StartsAt.Before(other.EndsAt) {
+			return nil, newError(ErrFailedPrecondition, "FLASH_SALE_OVERLAP", "product %s already has flash sale %d in that time", sale.ProductID, other.ID)
+		}
+	}
+
+	sale.Status, sale.Sold, sale.Reconciled = models.FlashSaleScheduled, 0, 0
+	if sale.CreatedBy == "" {
+		sale.CreatedBy = "unknown"
+	}
+	if err := s.repo.CreateFlashSale(ctx, sale); err != nil {
+		return nil, err
+	}
+	if sale.StartsAt.After(now) {
+		return sale, nil
+	}
+	return s.startFlashSale(ctx, sale.ID)
+}
+
+func (s *InventoryService) GetFlashSale(ctx context.Context, id int64) (*models.FlashSale, error) {
+	return s.repo.GetFlashSale(ctx, id)
+}
+
+func (s *InventoryService) ListFlashSales(ctx context.Context, q models.FlashSaleQuery) ([]*models.FlashSale, error) {
+	switch q.Status {
+	case "", models.FlashSaleScheduled, models.FlashSaleActive, models.FlashSaleEnded:
+	default:
+		return nil, newError(ErrInvalidArgument, "INVALID_STATUS", "unknown flash sale status %q", q.Status)
+	}
+	return s.repo.ListFlashSales(ctx, q)
+}
+
+// EndFlashSale ends a sale before its end time. What it hasn't sold goes
+// back to its location.
+func (s *InventoryService) EndFlashSale(ctx context.Context, id int64) (*models.FlashSale, error) {
+	sale, err := s.repo.GetFlashSale(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.endFlashSale(ctx, sale)
+}
+
+// startFlashSale sets the sale's quantity aside and loads it into the store.
+func (s *InventoryService) startFlashSale(ctx context.Context, id int64) (*models.FlashSale, error) {
+	sale, err := s.repo.StartFlashSale(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Flash sale started", "id", sale.ID, "product_id", sale.ProductID, "quantity", sale.Quantity)
+	if err := s.loadFlashSale(ctx, sale); err != nil {
+		// Loaded on the next sync; until then the product can't be reserved
+		slog.Error("Failed to load flash sale", "id", sale.ID, "error", err)
+	} else {
+		s.setActiveSale(sale)
+	}
+	s.notifyStockChange(ctx, sale.ProductID)
+	return sale, nil
+}
+
+// endFlashSale stops the sale selling, writes its last sales to the ledger
+// and returns the rest of its stock. The log is read once more after it is
+// sealed, for refunds logged while the sales were written; refunds after
+// that are made in the ledger.
+func (s *InventoryService) endFlashSale(ctx context.Context, sale *models.FlashSale) (*models.FlashSale, error) {
+	if sale.Status == models.FlashSaleActive && s.flashSales != nil {
+		if err := s.flashSales.Stop(ctx, sale.ID); err != nil {
+			return nil, err
+		}
+		s.dropActiveSale(sale)
+		if err := s.reconcileFlashSale(ctx, sale.ID); err != nil {
+			return nil, err
+		}
+		if err := s.flashSales.Seal(ctx, sale.ID); err != nil {
+			return nil, err
+		}
+		if err := s.reconcileFlashSale(ctx, sale.ID); err != nil {
+			return nil, err
+		}
+	}
+	ended, err := s.repo.EndFlashSale(ctx, sale.ID)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Flash sale ended", "id", ended.ID, "product_id", ended.ProductID, "sold", ended.Sold)
+	if s.flashSales != nil {
+		if err := s.flashSales.Remove(ctx, ended.ID); err != nil {
+			// The keys expire on their own a day after the sale's end time
+			slog.Error("Failed to remove flash sale counters", "id", ended.ID, "error", err)
+		}
+	}
+	s.notifyStockChange(ctx, ended.ProductID)
+	return ended, nil
+}
+
+// reconcileFlashSale writes everything in the sale's log to the ledger.
+func (s *InventoryService) reconcileFlashSale(ctx context.Context, id int64) error {
+	var sale *models.FlashSale
+	var total int
+	for {
+		var n int
+		var err error
+		sale, n, err = s.repo.ReconcileFlashSale(ctx, id, func(offset int64) ([]models.FlashSaleEntry, error) {
+			return s.flashSales.Entries(ctx, id, offset, flashSaleReconcileBatch)
+		})
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < flashSaleReconcileBatch {
+			break
+		}
+	}
+	if total > 0 {
+		s.notifyStockChange(ctx, sale.ProductID)
+	}
+	return nil
+}
+
+// RunFlashSales starts and ends flash sales on time and reconciles their
+// sales into the ledger every interval, until ctx is done.
+func (s *InventoryService) RunFlashSales(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.syncFlashSales(ctx); err != nil {
+			slog.Error("Flash sale sync failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *InventoryService) syncFlashSales(ctx context.Context) error {
+	now := time.Now()
+	scheduled, err := s.repo.ListFlashSales(ctx, models.FlashSaleQuery{Status: models.FlashSaleScheduled})
+	if err != nil {
+		return err
+	}
+	for _, sale := range scheduled {
+		switch {
+		case !sale.EndsAt.After(now):
+			_, err = s.endFlashSale(ctx, sale)
+		case !sale.StartsAt.After(now):
+			_, err = s.startFlashSale(ctx, sale.ID)
+		default:
+			continue
+		}
+		// Another replica may have got there first
+		if err != nil && !errors.Is(err, repository.ErrFlashSaleState) {
+			slog.Error("Failed to start flash sale", "id", sale.ID, "error", err)
+		}
+	}
+
+	active, err := s.repo.ListFlashSales(ctx, models.FlashSaleQuery{Status: models.FlashSaleActive})
+	if err != nil {
+		return err
+	}
+	running := make(map[string]*models.FlashSale, len(active))
+	for _, sale := range active {
+		if !sale.EndsAt.After(now) {
+			if _, err := s.endFlashSale(ctx, sale); err != nil && !errors.Is(err, repository.ErrFlashSaleState) {
+				slog.Error("Failed to end flash sale", "id", sale.ID, "error", err)
+			}
+			continue
+		}
+		// Sales started by other replicas
+		if err := s.loadFlashSale(ctx, sale); err != nil {
+			slog.Error("Failed to load flash sale", "id", sale.ID, "error", err)
+			continue
+		}
+		if err := s.reconcileFlashSale(ctx, sale.ID); err != nil {
+			slog.Error("Failed to reconcile flash sale", "id", sale.ID, "error", err)
+		}
+		running[sale.ProductID] = sale
+	}
+	s.activeMu.Lock()
+	s.activeSales = running
+	s.activeMu.Unlock()
+	return nil
+}
+
+// loadFlashSale loads the sale into the store and records that it was, so
+// no replica sells from it before that is recorded.
+func (s *InventoryService) loadFlashSale(ctx context.Context, sale *models.FlashSale) error {
+	if err := s.flashSales.Load(ctx, sale); err != nil {
+		return err
+	}
+	if sale.Loaded {
+		return nil
+	}
+	if err := s.repo.MarkFlashSaleLoaded(ctx, sale.ID); err != nil {
+		return err
+	}
+	sale.Loaded = true
+	return nil
+}
+
+func (s *InventoryService) setActiveSale(sale *models.FlashSale) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	if s.activeSales == nil {
+		s.activeSales = make(map[string]*models.FlashSale)
+	}
+	s.activeSales[sale.ProductID] = sale
+}
+
+func (s *InventoryService) dropActiveSale(sale *models.FlashSale) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	if running, ok := s.activeSales[sale.ProductID]; ok && running.ID == sale.ID {
+		delete(s.activeSales, sale.ProductID)
+	}
+}
+
+// takeFlashSaleItems removes the items of products in a running flash sale
+// from req and returns them, one per sale.
+func (s *InventoryService) takeFlashSaleItems(req *models.AllocationRequest) []models.FlashSaleItem {
+	if s.flashSales == nil {
+		return nil
+	}
+	s.activeMu.RLock()
+	defer s.activeMu.RUnlock()
+	if len(s.activeSales) == 0 {
+		return nil
+	}
+
+	var held []models.FlashSaleItem
+	rest := make([]models.AllocationItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		sale, ok := s.activeSales[item.ProductID]
+		if !ok {
+			rest = append(rest, item)
+			continue
+		}
+		if i := slices.IndexFunc(held, func(h models.FlashSaleItem) bool { return h.Sale.ID == sale.ID }); i >= 0 {
+			held[i].Quantity += item.Quantity
+			continue
+		}
+		held = append(held, models.FlashSaleItem{Sale: sale, Quantity: item.Quantity})
+	}
+	req.Items = rest
+	return held
+}
+
+// purchaseFlashSale takes items from their sales' counters and returns
+// where they ship from. A sale that can't cover its item fails the whole
+// request: with a shortfall when it is sold out, with
+// PURCHASE_LIMIT_EXCEEDED when the user would go over the sale's limit,
+// and with the *FlashSaleRejection when it has stopped selling, e.g. ended
+// by another replica. That sale is dropped from the running ones.
+func (s *InventoryService) purchaseFlashSale(ctx context.Context, items []models.FlashSaleItem, anonymous bool, info models.MovementInfo, requestID string) (*models.AllocationPlan, error) {
+	for _, item := range items {
+		if item.Sale.PerUserLimit > 0 && anonymous {
+			return nil, newError(ErrInvalidArgument, "ACTOR_REQUIRED", "product %s is in a flash sale limited per customer; actor is required", item.Sale.ProductID)
+		}
+	}
+
+	err := s.flashSales.Purchase(ctx, requestID, info.Actor, info.ReferenceID, items)
+	var rejected *FlashSaleRejection
+	if errors.As(err, &rejected) {
+		i := slices.IndexFunc(items, func(item models.FlashSaleItem) bool { return item.Sale.ID == rejected.SaleID })
+		if i < 0 {
+			return nil, err
+		}
+		item := items[i]
+		switch rejected.Reason {
+		case FlashSaleNotSelling:
+			s.dropActiveSale(item.Sale)
+			return nil, err
+		case FlashSaleLimitReached:
+			return nil, newError(ErrFailedPrecondition, "PURCHASE_LIMIT_EXCEEDED",
+				"product %s is limited to %d per customer in this flash sale; %d more allowed",
+				item.Sale.ProductID, item.Sale.PerUserLimit, max(rejected.Remaining, 0))
+		}
+		plan := &models.AllocationPlan{Shortfalls: []models.Shortfall{{ProductID: item.Sale.ProductID, Missing: item.Quantity - max(rejected.Remaining, 0)}}}
+		return plan, repository.ErrAdjustmentRejected
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &models.AllocationPlan{}
+	for _, item := range items {
+		plan.Allocations = append(plan.Allocations, models.Allocation{ProductID: item.Sale.ProductID, LocationCode: item.Sale.LocationCode, Quantity: item.Quantity})
+	}
+	return plan, nil
+}
+
+// refundFlashSale gives back what requestID took from the items' sales,
+// in the ledger for sales whose log is sealed.
+func (s *InventoryService) refundFlashSale(ctx context.Context, items []models.FlashSaleItem, requestID, user string) {
+	ctx = context.WithoutCancel(ctx)
+	sales := make([]*models.FlashSale, len(items))
+	for i, item := range items {
+		sales[i] = item.Sale
+	}
+	sealed, err := s.flashSales.Refund(ctx, requestID, user, sales)
+	if err != nil {
+		slog.Error("Failed to refund flash sale purchase", "request_id", requestID, "error", err)
+		return
+	}
+	for _, id := range sealed {
+		sale, refunded, err := s.repo.RefundFlashSalePurchase(ctx, id, requestID)
+		if err != nil {
+			slog.Error("Failed to refund flash sale purchase", "id", id, "request_id", requestID, "error", err)
+			continue
+		}
+		if refunded {
+			s.notifyStockChange(ctx, sale.ProductID)
+		}
+	}
+}
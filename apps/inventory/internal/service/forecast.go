@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+const (
+	defaultForecastWindow = 28
+	maxForecastWindow     = 365
+
+	// smoothingAlpha is the weight exponential smoothing gives the latest day
+	smoothingAlpha = 0.3
+	// Safety stock covers demand up to this many standard deviations above
+	// the forecast: about 95% of lead times end without a stockout.
+	serviceLevelZ = 1.65
+	// Lead time for products never bought on a purchase order
+	defaultLeadTimeDays = 7
+	// Suggestions cover demand until the next weekly report
+	reviewPeriodDays = 7
+)
+
+// RecordOrder adds an order.created event's items to daily sales. A
+// redelivered event is ignored.
+func (s *InventoryService) RecordOrder(ctx context.Context, event models.OrderEvent) error {
+	if event.OrderID == 0 {
+		return fmt.Errorf("%s has no order_id", models.OrderCreated)
+	}
+	at := time.Now()
+	if event.CreatedAt != nil {
+		at = *event.CreatedAt
+	}
+	day := startOfDay(at)
+
+	var sales []models.DailySales
+	for _, item := range event.Items {
+		if item.ProductID == "" || item.Quantity <= 0 {
+			continue
+		}
+		i := slices.IndexFunc(sales, func(d models.DailySales) bool { return d.ProductID == item.ProductID })
+		if i < 0 {
+			sales = append(sales, models.DailySales{ProductID: item.ProductID, Day: day})
+			i = len(sales) - 1
+		}
+		sales[i].Quantity += item.Quantity
+	}
+
+	recorded, err := s.repo.RecordSales(ctx, event.OrderID, sales)
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		slog.Info("Order already counted in sales", "order_id", event.OrderID)
+	}
+	return nil
+}
+
+// ReorderSuggestions forecasts each product's daily demand from the sales of
+// the last q.WindowDays full days and suggests how much to order. q's
+// defaults are filled in place.
+func (s *InventoryService) ReorderSuggestions(ctx context.Context, q *models.ForecastQuery) ([]models.ReorderSuggestion, error) {
+	switch q.Method {
+	case "":
+		q.Method = models.ForecastMovingAverage
+	case models.ForecastMovingAverage, models.ForecastExponentialSmoothing:
+	default:
+		return nil, newError(ErrInvalidArgument, "INVALID_METHOD", "unknown forecast method %q", q.Method)
+	}
+	switch {
+	case q.WindowDays == 0:
+		q.WindowDays = defaultForecastWindow
+	case q.WindowDays < 0 || q.WindowDays > maxForecastWindow:
+		return nil, newError(ErrInvalidArgument, "INVALID_WINDOW", "window_days must be between 1 and %d", maxForecastWindow)
+	}
+	if len(q.ProductIDs) > MaxBatchSize {
+		return nil, newError(ErrInvalidArgument, "BATCH_TOO_LARGE", "at most %d product ids per request", MaxBatchSize)
+	}
+	if slices.Contains(q.ProductIDs, "") {
+		return nil, newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_ids cannot contain empty ids")
+	}
+
+	today := startOfDay(time.Now())
+	since := today.AddDate(0, 0, -q.WindowDays)
+	sales, err := s.repo.ListDailySales(ctx, q.ProductIDs, since)
+	if err != nil {
+		return nil, err
+	}
+	// Today is left out until it is over, so it doesn't drag the average down
+	series := make(map[string][]float64)
+	for _, d := range sales {
+		day := int(d.Day.Sub(since).Hours() / 24)
+		if day < 0 || day >= q.WindowDays {
+			continue
+		}
+		if series[d.ProductID] == nil {
+			series[d.ProductID] = make([]float64, q.WindowDays)
+		}
+		series[d.ProductID][day] += float64(d.Quantity)
+	}
+
+	ids := slices.Clone(q.ProductIDs)
+	if len(ids) == 0 {
+		for id := range series {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	var suggestions []models.ReorderSuggestion
+	for chunk := range slices.Chunk(ids, reportPageSize) {
+		page, err := s.suggestReorders(ctx, chunk, series, q)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, page...)
+	}
+	return suggestions, nil
+}
+
+func (s *InventoryService) suggestReorders(ctx context.Context, ids []string, series map[string][]float64, q *models.ForecastQuery) ([]models.ReorderSuggestion, error) {
+	stocks, err := s.GetStocks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	incoming, err := s.repo.IncomingStock(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	suppliers, err := s.repo.ProductSuppliers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]models.ReorderSuggestion, 0, len(ids))
+	for _, id := range ids {
+		sg := models.ReorderSuggestion{ProductID: id, LeadTimeDays: defaultLeadTimeDays}
+		if inv, ok := stocks[id]; ok {
+			sg.OnHand, sg.Backordered = inv.Quantity, inv.Backordered
+		}
+		if i := slices.IndexFunc(incoming, func(in models.IncomingStock) bool { return in.ProductID == id }); i >= 0 {
+			sg.Incoming = incoming[i].Quantity
+		}
+		if i := slices.IndexFunc(suppliers, func(ps models.ProductSupplier) bool { return ps.ProductID == id }); i >= 0 {
+			sg.SupplierCode = suppliers[i].SupplierCode
+			if suppliers[i].LeadTimeDays > 0 {
+				sg.LeadTimeDays = suppliers[i].LeadTimeDays
+			}
+		}
+
+		demand := series[id]
+		if demand == nil {
+			demand = make([]float64, q.WindowDays)
+		}
+		planReorder(&sg, demand, q.Method)
+		if q.OnlyNeeded && sg.Suggested == 0 {
+			continue
+		}
+		suggestions = append(suggestions, sg)
+	}
+	return suggestions, nil
+}
+
+// planReorder fills in sg's forecast, safety stock, reorder point and
+// suggested quantity from its daily demand, oldest first. The stock
+// position and lead time must already be set.
+func planReorder(sg *models.ReorderSuggestion, demand []float64, method string) {
+	sg.DailyDemand = forecastDemand(demand, method)
+	lead := float64(sg.LeadTimeDays)
+	sg.SafetyStock = int32(math.Ceil(serviceLevelZ * stdDev(demand) * math.Sqrt(lead)))
+	sg.ReorderPoint = int32(math.Ceil(sg.DailyDemand*lead)) + sg.SafetyStock
+	sg.Suggested = 0
+	if sg.Position() <= sg.ReorderPoint {
+		target := int32(math.Ceil(sg.DailyDemand*(lead+reviewPeriodDays))) + sg.SafetyStock
+		sg.Suggested = max(target-sg.Position(), 0)
+	}
+}
+
+// forecastDemand predicts tomorrow's demand from daily quantities, oldest
+// first.
+func forecastDemand(daily []float64, method string) float64 {
+	if len(daily) == 0 {
+		return 0
+	}
+	if method == models.ForecastExponentialSmoothing {
+		level := daily[0]
+		for _, x := range daily[1:] {
+			level = smoothingAlpha*x + (1-smoothingAlpha)*level
+		}
+		return level
+	}
+	var sum float64
+	for _, x := range daily {
+		sum += x
+	}
+	return sum / float64(len(daily))
+}
+
+func stdDev(daily []float64) float64 {
+	if len(daily) < 2 {
+		return 0
+	}
+	mean := forecastDemand(daily, models.ForecastMovingAverage)
+	var sq float64
+	for _, x := range daily {
+		sq += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(sq / float64(len(daily)-1))
+}
+
+// scheduleCheckInterval is how often RunReorderReport looks whether a
+// report is due.
+const scheduleCheckInterval = time.Minute
+
+// RunReorderReport publishes an inventory.reorder_report event with the
+// products that need ordering once every interval of wall-clock time, until
+// ctx is done. The last run is kept in the database, so restarts don't push
+// the report back and only one replica sends each one.
+func (s *InventoryService) RunReorderReport(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.reorderReportIfDue(ctx, interval, time.Now().UTC()); err != nil {
+			slog.Error("Reorder report failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *InventoryService) reorderReportIfDue(ctx context.Context, interval time.Duration, now time.Time) error {
+	last, err := s.repo.LastScheduledRun(ctx, models.JobReorderReport)
+	if err != nil {
+		return err
+	}
+	if !last.IsZero() && now.Sub(last) < interval {
+		return nil
+	}
+
+	q := models.ForecastQuery{OnlyNeeded: true}
+	suggestions, err := s.ReorderSuggestions(ctx, &q)
+	if err != nil {
+		return err
+	}
+	event := &models.ReorderReportEvent{
+		ID:         "reorder-report-" + now.Format(time.RFC3339),
+		Type:       models.ReorderReport,
+		Version:    models.ReorderReportVersion,
+		OccurredAt: now,
+		Data:       &models.ReorderReportData{Method: q.Method, WindowDays: q.WindowDays, Suggestions: []models.ReorderSuggestionRow{}},
+	}
+	for _, sg := range suggestions {
+		event.Data.Suggestions = append(event.Data.Suggestions, models.ReorderSuggestionRow{
+			ProductID:         sg.ProductID,
+			SupplierCode:      sg.SupplierCode,
+			DailyDemand:       sg.DailyDemand,
+			ReorderPoint:      sg.ReorderPoint,
+			Position:          sg.Position(),
+			SuggestedQuantity: sg.Suggested,
+		})
+	}
+	outbox, err := models.NewOutboxEvent(event.ID, event.Type, event.OccurredAt, event)
+	if err != nil {
+		return err
+	}
+	claimed, err := s.repo.ClaimScheduledRun(ctx, models.JobReorderReport, last, now, outbox)
+	if err != nil {
+		return err
+	}
+	if claimed {
+		slog.Info("Reorder report saved", "products", len(event.Data.Suggestions))
+		s.outboxSaved()
+	}
+	return nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/repository"
+)
+
+func TestForecastDemand(t *testing.T) {
+	tests := []struct {
+		name   string
+		daily  []float64
+		method string
+		want   float64
+	}{
+		{"no days", nil, models.ForecastMovingAverage, 0},
+		{"moving average", []float64{1, 2, 3}, models.ForecastMovingAverage, 2},
+		{"smoothing weights recent days", []float64{10, 0, 0}, models.ForecastExponentialSmoothing, 4.9},
+		{"smoothing one day", []float64{3}, models.ForecastExponentialSmoothing, 3},
+	}
+	for _, tt := range tests {
+		if got := forecastDemand(tt.daily, tt.method); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: forecastDemand = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStdDev(t *testing.T) {
+	tests := []struct {
+		daily []float64
+		want  float64
+	}{
+		{nil, 0},
+		{[]float64{5}, 0},
+		{[]float64{3, 3, 3}, 0},
+		{[]float64{2, 4, 4, 4, 5, 5, 7, 9}, math.Sqrt(32.0 / 7)},
+	}
+	for _, tt := range tests {
+		if got := stdDev(tt.daily); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("stdDev(%v) = %v, want %v", tt.daily, got, tt.want)
+		}
+	}
+}
+
+func TestPlanReorder(t *testing.T) {
+	steady := make([]float64, 28)
+	for i := range steady {
+		steady[i] = 2
+	}
+	tests := []struct {
+		name         string
+		sg           models.ReorderSuggestion
+		demand       []float64
+		safety       int32
+		reorderPoint int32
+		suggested    int32
+	}{
+		{"below the reorder point", models.ReorderSuggestion{OnHand: 10, LeadTimeDays: 7}, steady, 0, 14, 18},
+		{"above the reorder point", models.ReorderSuggestion{OnHand: 20, LeadTimeDays: 7}, steady, 0, 14, 0},
+		{"backorders lower the position", models.ReorderSuggestion{OnHand: 20, Backordered: 8, LeadTimeDays: 7}, steady, 0, 14, 16},
+		// Mean 2, sample deviation 4/sqrt(3): safety ceil(1.65 * 2.309 * 2) = 8
+		{"variable demand adds safety stock", models.ReorderSuggestion{Incoming: 5, LeadTimeDays: 4}, []float64{0, 4, 0, 4}, 8, 16, 25},
+		{"no demand", models.ReorderSuggestion{LeadTimeDays: 7}, make([]float64, 28), 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sg := tt.sg
+			planReorder(&sg, tt.demand, models.ForecastMovingAverage)
+			if sg.SafetyStock != tt.safety || sg.ReorderPoint != tt.reorderPoint || sg.Suggested != tt.suggested {
+				t.Fatalf("safety %d, reorder point %d, suggested %d; want %d, %d, %d",
+					sg.SafetyStock, sg.ReorderPoint, sg.Suggested, tt.safety, tt.reorderPoint, tt.suggested)
+			}
+		})
+	}
+}
+
+// scheduleRepo keeps a scheduled run in memory; the embedded interface
+// panics on anything else.
+type scheduleRepo struct {
+	repository.InventoryRepository
+	last  time.Time
+	saved []*models.OutboxEvent
+}
+
+func (r *scheduleRepo) LastScheduledRun(context.Context, string) (time.Time, error) {
+	return r.last, nil
+}
+
+func (r *scheduleRepo) ClaimScheduledRun(_ context.Context, _ string, last, now time.Time, event *models.OutboxEvent) (bool, error) {
+	if !last.Equal(r.last) {
+		return false, nil
+	}
+	r.last = now
+	r.saved = append(r.saved, event)
+	return true, nil
+}
+
+func (r *scheduleRepo) ListDailySales(context.Context, []string, time.Time) ([]models.DailySales, error) {
+	return nil, nil
+}
+
+func TestReorderReportRunsOnWallClock(t *testing.T) {
+	repo := &scheduleRepo{}
+	s := &InventoryService{repo: repo, outboxReady: make(chan struct{}, 1)}
+	ctx := context.Background()
+	start := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+
+	for _, step := range []struct {
+		at    time.Time
+		saved int
+	}{
+		{start, 1}, // Never ran
+		{start.Add(6 * 24 * time.Hour), 1},
+		{start.Add(7 * 24 * time.Hour), 2},
+		{start.Add(7*24*time.Hour + time.Minute), 2},
+	} {
+		if err := s.reorderReportIfDue(ctx, 7*24*time.Hour, step.at); err != nil {
+			t.Fatalf("at %v: %v", step.at, err)
+		}
+		if len(repo.saved) != step.saved {
+			t.Fatalf("at %v: %d reports saved, want %d", step.at, len(repo.saved), step.saved)
+		}
+	}
+	if len(s.outboxReady) != 1 {
+		t.Fatal("outbox relay not woken")
+	}
+
+	var event models.ReorderReportEvent
+	if err := json.Unmarshal(repo.saved[1].Payload, &event); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if repo.saved[1].Type != models.ReorderReport || event.ID != repo.saved[1].EventID || event.ID == repo.saved[0].EventID {
+		t.Fatalf("event = %+v, want a reorder report with its own id", event)
+	}
+}
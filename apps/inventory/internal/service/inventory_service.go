@@ -2,23 +2,538 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
 	"github.com/thapakon-thai/eshop-microservices/inventory/internal/repository"
 )
 
 type InventoryService struct {
-	repo repository.InventoryRepository
+	repo            repository.InventoryRepository
+	defaultLocation string // Where changes that name no location are applied
+	publisher       EventPublisher
+	alertDebounce   time.Duration // Minimum gap between two alerts of one type for a product
+	catalog         ProductCatalog
+	watchers        *stockBroadcaster
+	flashSales      FlashSaleStore // nil when flash sales are off
+	outboxReady     chan struct{}  // Wakes RunOutboxRelay
+
+	fillMu    sync.Mutex
+	fillQueue map[string]bool // Products RunBackorderFiller should look at
+	fillReady chan struct{}
+
+	activeMu    sync.RWMutex
+	activeSales map[string]*models.FlashSale // Running flash sales by product id
+}
+
+func NewInventoryService(repo repository.InventoryRepository, defaultLocation string, publisher EventPublisher, alertDebounce time.Duration, catalog ProductCatalog, flashSales FlashSaleStore) *InventoryService {
+	return &InventoryService{
+		repo:            repo,
+		defaultLocation: defaultLocation,
+		publisher:       publisher,
+		alertDebounce:   alertDebounce,
+		catalog:         catalog,
+		watchers:        newStockBroadcaster(),
+		flashSales:      flashSales,
+		outboxReady:     make(chan struct{}, 1),
+		fillQueue:       make(map[string]bool),
+		fillReady:       make(chan struct{}, 1),
+	}
+}
+
+// GetStock returns the product's total and its per-location breakdown,
+// leaving out stock in expired lots. With locationCode set, the breakdown
+// holds only that location. A product never stocked reads as zero rather
+// than NotFound.
+func (s *InventoryService) GetStock(ctx context.Context, productID, locationCode string) (*models.Inventory, []*models.LocationStock, error) {
+	if locationCode != "" {
+		if _, err := s.resolveLocation(ctx, locationCode); err != nil {
+			return nil, nil, err
+		}
+	}
+	inventories, err := s.repo.GetStocks(ctx, []string{productID})
+	if err != nil {
+		return nil, nil, err
+	}
+	inv := &models.Inventory{ProductID: productID}
+	if len(inventories) > 0 {
+		inv = inventories[0]
+	}
+	stocks, err := s.repo.GetLocationStocks(ctx, []string{productID})
+	if err != nil {
+		return nil, nil, err
+	}
+	expired, err := s.repo.ExpiredLotStock(ctx, []string{productID})
+	if err != nil {
+		return nil, nil, err
+	}
+	withoutExpiredLots(expired, []*models.Inventory{inv}, stocks)
+	if locationCode != "" {
+		stocks = slices.DeleteFunc(stocks, func(ls *models.LocationStock) bool { return ls.LocationCode != locationCode })
+	}
+	return inv, stocks, nil
+}
+
+// resolveLocation maps an empty code to the default location and checks
+// that any other code exists.
+func (s *InventoryService) resolveLocation(ctx context.Context, code string) (string, error) {
+	if code == "" {
+		return s.defaultLocation, nil
+	}
+	if _, err := s.repo.GetLocation(ctx, code); err != nil {
+		if errors.Is(err, repository.ErrLocationNotFound) {
+			return "", newError(ErrInvalidArgument, "UNKNOWN_LOCATION", "unknown location %q", code)
+		}
+		return "", err
+	}
+	return code, nil
+}
+
+// MaxBatchSize caps how many products one batch lookup may ask for.
+const MaxBatchSize = 500
+
+// GetStocks returns the rows that exist for productIDs, keyed by product id.
+// Quantity leaves out stock in expired lots, which can't be sold.
+func (s *InventoryService) GetStocks(ctx context.Context, productIDs []string) (map[string]*models.Inventory, error) {
+	if len(productIDs) > MaxBatchSize {
+		return nil, newError(ErrInvalidArgument, "BATCH_TOO_LARGE", "at most %d products per batch", MaxBatchSize)
+	}
+	inventories, err := s.repo.GetStocks(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	expired, err := s.repo.ExpiredLotStock(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	withoutExpiredLots(expired, inventories, nil)
+	byProduct := make(map[string]*models.Inventory, len(inventories))
+	for _, inv := range inventories {
+		byProduct[inv.ProductID] = inv
+	}
+	return byProduct, nil
+}
+
+// withoutExpiredLots takes the stock in expired lots out of inventories and
+// stocks. ExpireLots hasn't written it off yet, but it can't be sold.
+func withoutExpiredLots(expired []*models.LocationStock, inventories []*models.Inventory, stocks []*models.LocationStock) {
+	type key struct{ productID, locationCode string }
+	byProduct := make(map[string]int32, len(expired))
+	byLocation := make(map[key]int32, len(expired))
+	for _, e := range expired {
+		byProduct[e.ProductID] += e.Quantity
+		byLocation[key{e.ProductID, e.LocationCode}] += e.Quantity
+	}
+	for _, inv := range inventories {
+		inv.Quantity = max(inv.Quantity-byProduct[inv.ProductID], 0)
+	}
+	for _, ls := range stocks {
+		ls.Quantity = max(ls.Quantity-byLocation[key{ls.ProductID, ls.LocationCode}], 0)
+	}
+}
+
+// UpdateStock applies one change at locationCode, or at the default location
+// when it is empty. A non-empty requestID makes the call idempotent:
+// repeating it returns the first result.
+func (s *InventoryService) UpdateStock(ctx context.Context, productID, locationCode string, change int32, info models.MovementInfo, requestID string) (*models.Inventory, *models.LocationStock, error) {
+	if productID == "" {
+		return nil, nil, newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+	}
+	info, err := normalizeMovementInfo(info)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateLot(info.Lot, change); err != nil {
+		return nil, nil, err
+	}
+	if locationCode, err = s.resolveLocation(ctx, locationCode); err != nil {
+		return nil, nil, err
+	}
+	inv, at, err := s.repo.UpdateStock(ctx, productID, locationCode, change, info, requestID)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.notifyStockChange(ctx, productID)
+	return inv, at, nil
+}
+
+// normalizeMovementInfo checks a caller-supplied reason and fills defaults.
+func normalizeMovementInfo(info models.MovementInfo) (models.MovementInfo, error) {
+	switch info.Reason {
+	case "":
+		info.Reason = models.ReasonAdjustment
+	case models.ReasonSale, models.ReasonReturn, models.ReasonRestock, models.ReasonAdjustment, models.ReasonCycleCount:
+	default:
+		return info, newError(ErrInvalidArgument, "INVALID_REASON", "unknown stock movement reason %q", info.Reason)
+	}
+	if info.Actor == "" {
+		info.Actor = "unknown"
+	}
+	return info, nil
+}
+
+// AdjustStockBatch applies all adjustments atomically. When any line fails
+// the results explain which, and repository.ErrAdjustmentRejected is returned.
+func (s *InventoryService) AdjustStockBatch(ctx context.Context, adjustments []models.StockAdjustment, info models.MovementInfo, requestID string) ([]models.StockAdjustmentResult, error) {
+	if len(adjustments) == 0 {
+		return nil, newError(ErrInvalidArgument, "EMPTY_BATCH", "adjustments cannot be empty")
+	}
+	if len(adjustments) > MaxBatchSize {
+		return nil, newError(ErrInvalidArgument, "BATCH_TOO_LARGE", "at most %d adjustments per batch", MaxBatchSize)
+	}
+	resolved := make(map[string]string)
+	adjustments = slices.Clone(adjustments)
+	for i, adj := range adjustments {
+		if adj.ProductID == "" {
+			return nil, newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+		}
+		code, ok := resolved[adj.LocationCode]
+		if !ok {
+			var err error
+			if code, err = s.resolveLocation(ctx, adj.LocationCode); err != nil {
+				return nil, err
+			}
+			resolved[adj.LocationCode] = code
+		}
+		adjustments[i].LocationCode = code
+	}
+	info, err := normalizeMovementInfo(info)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.repo.AdjustStockBatch(ctx, adjustments, info, requestID)
+	if err == nil {
+		ids := make([]string, len(adjustments))
+		for i, adj := range adjustments {
+			ids[i] = adj.ProductID
+		}
+		s.notifyStockChange(ctx, ids...)
+	}
+	return results, err
+}
+
+// InitializeStock seeds stock for a new product at the default location;
+// eventID is recorded as the movement's reference.
+func (s *InventoryService) InitializeStock(ctx context.Context, productID string, quantity int32, eventID string) error {
+	if quantity < 0 {
+		return newError(ErrInvalidArgument, "NEGATIVE_STOCK", "initial stock cannot be negative")
+	}
+	if err := s.repo.InitializeStock(ctx, productID, s.defaultLocation, quantity, models.MovementInfo{
+		Reason:      models.ReasonInitial,
+		ReferenceID: eventID,
+		Actor:       "product-service",
+	}); err != nil {
+		return err
+	}
+	s.notifyStockChange(ctx, productID)
+	return nil
+}
+
+func (s *InventoryService) ArchiveStock(ctx context.Context, productID string) error {
+	return s.repo.ArchiveStock(ctx, productID)
+}
+
+func (s *InventoryService) RestoreStock(ctx context.Context, productID string) error {
+	return s.repo.RestoreStock(ctx, productID)
+}
+
+const maxPageSize = 100
+
+// ListMovements returns one page of the ledger, newest first, and the token
+// for the next page (empty on the last page). Tokens are opaque to clients;
+// today they carry the last movement id seen.
+func (s *InventoryService) ListMovements(ctx context.Context, q models.MovementQuery) ([]*models.StockMovement, string, error) {
+	if q.ProductID == "" && q.ReferenceID == "" {
+		return nil, "", newError(ErrInvalidArgument, "FILTER_REQUIRED", "product_id or reference_id is required")
+	}
+	var err error
+	if q.BeforeID, err = decodePageToken(q.PageToken); err != nil {
+		return nil, "", err
+	}
+	limit := pageSize(q.Limit)
+	q.Limit = limit + 1
+
+	movements, err := s.repo.ListMovements(ctx, q)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(movements) <= limit {
+		return movements, "", nil
+	}
+	movements = movements[:limit]
+	return movements, encodePageToken(movements[limit-1].ID), nil
 }
 
-func NewInventoryService(repo repository.InventoryRepository) *InventoryService {
-	return &InventoryService{repo: repo}
+// pageSize applies the default and maximum to a requested page size.
+func pageSize(limit int) int {
+	if limit <= 0 {
+		return 20
+	}
+	return min(limit, maxPageSize)
 }
 
-func (s *InventoryService) GetStock(ctx context.Context, productID string) (*models.Inventory, error) {
-	return s.repo.GetStock(ctx, productID)
+// decodePageToken returns the id a newest-first listing continues below;
+// 0 for an empty token.
+func decodePageToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, newError(ErrInvalidArgument, "INVALID_PAGE_TOKEN", "invalid page_token")
+	}
+	id, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, newError(ErrInvalidArgument, "INVALID_PAGE_TOKEN", "invalid page_token")
+	}
+	return id, nil
 }
 
-func (s *InventoryService) UpdateStock(ctx context.Context, productID string, change int32) (*models.Inventory, error) {
-	return s.repo.UpdateStock(ctx, productID, change)
+func encodePageToken(lastID int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(lastID, 10)))
+}
+
+func (s *InventoryService) ReconcileStock(ctx context.Context, productID string, apply bool) (*models.Reconciliation, error) {
+	if productID == "" {
+		return nil, newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+	}
+	rec, err := s.repo.ReconcileStock(ctx, productID, s.defaultLocation, apply)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Applied {
+		s.notifyStockChange(ctx, productID)
+	}
+	return rec, nil
+}
+
+func (s *InventoryService) CreateLocation(ctx context.Context, location *models.Location) error {
+	location.Code = strings.TrimSpace(location.Code)
+	if location.Code == "" {
+		return newError(ErrInvalidArgument, "LOCATION_CODE_REQUIRED", "code is required")
+	}
+	if location.Name == "" {
+		return newError(ErrInvalidArgument, "LOCATION_NAME_REQUIRED", "name is required")
+	}
+	return s.repo.CreateLocation(ctx, location)
+}
+
+func (s *InventoryService) ListLocations(ctx context.Context, includeInactive bool) ([]*models.Location, error) {
+	return s.repo.ListLocations(ctx, includeInactive)
+}
+
+// AllocateStock plans where req's items would ship from without reserving
+// anything, so the plan can be stale by the time it is used.
+func (s *InventoryService) AllocateStock(ctx context.Context, req models.AllocationRequest) (*models.AllocationPlan, error) {
+	ranked, err := s.prepareAllocation(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(req.Items))
+	for i, item := range req.Items {
+		ids[i] = item.ProductID
+	}
+	stocks, err := s.repo.GetLocationStocks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return allocate(req, ranked, stocks), nil
+}
+
+// ReserveStock allocates req's items and deducts them in one transaction.
+// When some item can't be covered nothing is deducted, and the plan's
+// shortfalls are returned with repository.ErrAdjustmentRejected. Products
+// in a running flash sale are taken from the sale's counter instead.
+func (s *InventoryService) ReserveStock(ctx context.Context, req models.AllocationRequest, info models.MovementInfo, requestID string) (*models.AllocationPlan, error) {
+	ranked, err := s.prepareAllocation(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	if info.Reason == "" {
+		info.Reason = models.ReasonSale
+	}
+	anonymous := info.Actor == ""
+	if info, err = normalizeMovementInfo(info); err != nil {
+		return nil, err
+	}
+
+	plan, err := s.reserveStock(ctx, req, ranked, anonymous, info, requestID)
+	// A sale that stopped selling was dropped; its items are tried again
+	// from the locations
+	var rejected *FlashSaleRejection
+	for tries := len(req.Items); errors.As(err, &rejected) && tries > 0; tries-- {
+		plan, err = s.reserveStock(ctx, req, ranked, anonymous, info, requestID)
+	}
+	if errors.As(err, &rejected) {
+		return nil, newError(ErrFailedPrecondition, "FLASH_SALE_ENDED", "flash sale %d has stopped selling", rejected.SaleID)
+	}
+	return plan, err
+}
+
+func (s *InventoryService) reserveStock(ctx context.Context, req models.AllocationRequest, ranked []*models.Location, anonymous bool, info models.MovementInfo, requestID string) (*models.AllocationPlan, error) {
+	var err error
+	held := s.takeFlashSaleItems(&req)
+	var flashPlan *models.AllocationPlan
+	flashKey := requestID
+	if len(held) > 0 {
+		if flashKey == "" {
+			flashKey = newEventID()
+		}
+		if flashPlan, err = s.purchaseFlashSale(ctx, held, anonymous, info, flashKey); err != nil {
+			return flashPlan, err
+		}
+		if len(req.Items) == 0 {
+			return flashPlan, nil
+		}
+	}
+
+	plan, err := s.repo.ReserveStock(ctx, req, func(stocks []*models.LocationStock) *models.AllocationPlan {
+		return allocate(req, ranked, stocks)
+	}, info, requestID)
+	if len(held) > 0 {
+		if err != nil {
+			s.refundFlashSale(ctx, held, flashKey, info.Actor)
+		} else {
+			plan.Allocations = append(flashPlan.Allocations, plan.Allocations...)
+		}
+	}
+	if err == nil {
+		ids := make([]string, len(req.Items))
+		for i, item := range req.Items {
+			ids[i] = item.ProductID
+		}
+		s.notifyStockChange(ctx, ids...)
+	}
+	return plan, err
+}
+
+// ReleaseStock undoes the reservation made with requestID, e.g. for an order
+// that could not be saved, refunding what it bought in flash sales of
+// productIDs. It reports false when no reservation had committed; that
+// reservation then can't commit any more.
+func (s *InventoryService) ReleaseStock(ctx context.Context, requestID string, productIDs []string, info models.MovementInfo) (bool, []models.Allocation, error) {
+	if requestID == "" {
+		return false, nil, newError(ErrInvalidArgument, "REQUEST_ID_REQUIRED", "request_id is required")
+	}
+	info.Reason = models.ReasonRelease
+	if info.Actor == "" {
+		info.Actor = "unknown"
+	}
+
+	if s.flashSales != nil {
+		// Sales still running, and sales whose log has the purchase in the
+		// ledger already
+		purchased, err := s.repo.ListFlashSales(ctx, models.FlashSaleQuery{RequestID: requestID})
+		if err != nil {
+			return false, nil, err
+		}
+		s.activeMu.RLock()
+		for _, id := range productIDs {
+			if sale, ok := s.activeSales[id]; ok {
+				purchased = append(purchased, sale)
+			}
+		}
+		s.activeMu.RUnlock()
+		var held []models.FlashSaleItem
+		for _, sale := range purchased {
+			if !slices.ContainsFunc(held, func(h models.FlashSaleItem) bool { return h.Sale.ID == sale.ID }) {
+				held = append(held, models.FlashSaleItem{Sale: sale})
+			}
+		}
+		if len(held) > 0 {
+			// Refunding a request that bought nothing changes nothing
+			s.refundFlashSale(ctx, held, requestID, info.Actor)
+		}
+	}
+
+	released, restored, err := s.repo.ReleaseStock(ctx, requestID, info)
+	if err != nil {
+		return false, nil, err
+	}
+	if len(restored) > 0 {
+		ids := make([]string, len(restored))
+		for i, a := range restored {
+			ids[i] = a.ProductID
+		}
+		slices.Sort(ids)
+		s.notifyStockChange(ctx, slices.Compact(ids)...)
+	}
+	return released, restored, nil
+}
+
+// prepareAllocation validates req, fills in the default strategy and returns
+// the active locations ranked for its postcode.
+func (s *InventoryService) prepareAllocation(ctx context.Context, req *models.AllocationRequest) ([]*models.Location, error) {
+	if len(req.Items) == 0 {
+		return nil, newError(ErrInvalidArgument, "EMPTY_BATCH", "items cannot be empty")
+	}
+	if len(req.Items) > MaxBatchSize {
+		return nil, newError(ErrInvalidArgument, "BATCH_TOO_LARGE", "at most %d items per request", MaxBatchSize)
+	}
+	for _, item := range req.Items {
+		if item.ProductID == "" {
+			return nil, newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+		}
+		if item.Quantity <= 0 {
+			return nil, newError(ErrInvalidArgument, "INVALID_QUANTITY", "quantity must be positive")
+		}
+	}
+	switch req.Strategy {
+	case "":
+		req.Strategy = models.StrategyNearest
+	case models.StrategyNearest, models.StrategyFewestSplits:
+	default:
+		return nil, newError(ErrInvalidArgument, "INVALID_STRATEGY", "unknown allocation strategy %q", req.Strategy)
+	}
+
+	locations, err := s.repo.ListLocations(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	return rankLocations(locations, req.Postcode), nil
+}
+
+// EnsureDefaultLocation creates the default location if it is missing and
+// moves stock that predates locations into it.
+func (s *InventoryService) EnsureDefaultLocation(ctx context.Context, location *models.Location) (int64, error) {
+	location.Code = s.defaultLocation
+	location.Active = true
+	if err := s.repo.EnsureLocation(ctx, location); err != nil {
+		return 0, err
+	}
+	return s.repo.MoveStockToLocation(ctx, s.defaultLocation)
+}
+
+// BackfillOpeningBalances seeds the ledger for stock that predates it.
+func (s *InventoryService) BackfillOpeningBalances(ctx context.Context) (int64, error) {
+	return s.repo.BackfillOpeningBalances(ctx)
+}
+
+// RunIdempotencyKeyExpiry deletes request ids older than ttl every interval
+// until ctx is cancelled. A retry arriving after that is applied again.
+func (s *InventoryService) RunIdempotencyKeyExpiry(ctx context.Context, interval, ttl time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := s.repo.DeleteIdempotencyKeysBefore(ctx, time.Now().Add(-ttl))
+		if err != nil {
+			slog.Error("Idempotency key expiry failed", "error", err)
+		} else if deleted > 0 {
+			slog.Info("Expired idempotency keys", "count", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
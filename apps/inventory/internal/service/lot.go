@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+const (
+	defaultExpiringWindow = 30
+	maxExpiringWindow     = 365
+)
+
+// validateLot checks the lot that change units of stock arrive in. Lots
+// only make sense for stock being added, and expired stock can't be.
+func validateLot(lot *models.LotInfo, change int32) error {
+	if lot == nil {
+		return nil
+	}
+	lot.Code = strings.TrimSpace(lot.Code)
+	if lot.Code == "" {
+		return newError(ErrInvalidArgument, "LOT_CODE_REQUIRED", "lot_code is required with expires_on")
+	}
+	if change <= 0 {
+		return newError(ErrInvalidArgument, "LOT_ON_DEDUCTION", "a lot can only be given for stock being added")
+	}
+	if lot.ExpiresOn.IsZero() {
+		return newError(ErrInvalidArgument, "EXPIRY_REQUIRED", "expires_on is required with lot_code")
+	}
+	if lot.ExpiresOn.Before(startOfDay(time.Now())) {
+		return newError(ErrInvalidArgument, "LOT_EXPIRED", "lot %s expired on %s", lot.Code, lot.ExpiresOn.Format(time.DateOnly))
+	}
+	return nil
+}
+
+// ListLots returns a product's lots with stock left, optionally with the
+// expired ones written off.
+func (s *InventoryService) ListLots(ctx context.Context, productID, locationCode string, includeExpired bool) ([]*models.Lot, error) {
+	if productID == "" {
+		return nil, newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+	}
+	return s.repo.ListLots(ctx, models.LotQuery{ProductID: productID, LocationCode: locationCode, IncludeExpired: includeExpired})
+}
+
+// ExpiringLots returns the lots, of every product, whose last day of sale is
+// within withinDays from today, soonest first.
+func (s *InventoryService) ExpiringLots(ctx context.Context, withinDays int, locationCode string) ([]*models.Lot, error) {
+	switch {
+	case withinDays == 0:
+		withinDays = defaultExpiringWindow
+	case withinDays < 0 || withinDays > maxExpiringWindow:
+		return nil, newError(ErrInvalidArgument, "INVALID_WINDOW", "within_days must be between 1 and %d", maxExpiringWindow)
+	}
+	before := startOfDay(time.Now()).AddDate(0, 0, withinDays+1)
+	return s.repo.ListLots(ctx, models.LotQuery{LocationCode: locationCode, ExpiresBefore: &before})
+}
+
+// RunLotExpiry writes off lots past their expiry date every interval, until
+// ctx is done. Until then, sales already refuse to take from them.
+func (s *InventoryService) RunLotExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		lots, err := s.repo.ExpireLots(ctx, startOfDay(time.Now()))
+		if err != nil {
+			slog.Error("Lot expiry failed", "error", err)
+		} else if len(lots) > 0 {
+			ids := make([]string, 0, len(lots))
+			for _, l := range lots {
+				slog.Info("Expired lot written off", "product_id", l.ProductID, "location", l.LocationCode, "lot", l.Code, "quantity", l.Quantity)
+				ids = append(ids, l.ProductID)
+			}
+			s.notifyStockChange(ctx, slices.Compact(ids)...)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
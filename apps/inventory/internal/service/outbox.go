@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+// outboxBatchSize is how many outbox events one transaction publishes.
+const outboxBatchSize = 100
+
+// RunOutboxRelay publishes the events saved in the outbox until ctx is
+// cancelled: as soon as this replica saves some, and every interval for
+// those saved by other replicas or left over by a failed publish.
+func (s *InventoryService) RunOutboxRelay(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.relayOutbox(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.outboxReady:
+		}
+	}
+}
+
+func (s *InventoryService) relayOutbox(ctx context.Context) {
+	for {
+		n, err := s.repo.PublishOutboxEvents(ctx, outboxBatchSize, func(event *models.OutboxEvent) error {
+			return s.publisher.PublishOutboxEvent(ctx, event)
+		})
+		if err != nil {
+			slog.Error("Failed to publish outbox events", "published", n, "error", err)
+			return
+		}
+		if n < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// outboxSaved wakes the relay after a change saved events to the outbox.
+func (s *InventoryService) outboxSaved() {
+	select {
+	case s.outboxReady <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+// CreateSupplier adds a supplier. New suppliers are always active.
+func (s *InventoryService) CreateSupplier(ctx context.Context, supplier *models.Supplier) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+	supplier.Active = true
+	return s.repo.CreateSupplier(ctx, supplier)
+}
+
+// UpdateSupplier replaces the details of the supplier with supplier.Code;
+// fields left empty are cleared, not kept.
+func (s *InventoryService) UpdateSupplier(ctx context.Context, supplier *models.Supplier) error {
+	if err := validateSupplier(supplier); err != nil {
+		return err
+	}
+	return s.repo.UpdateSupplier(ctx, supplier)
+}
+
+func validateSupplier(supplier *models.Supplier) error {
+	supplier.Code = strings.TrimSpace(supplier.Code)
+	if supplier.Code == "" {
+		return newError(ErrInvalidArgument, "SUPPLIER_CODE_REQUIRED", "code is required")
+	}
+	if supplier.Name == "" {
+		return newError(ErrInvalidArgument, "SUPPLIER_NAME_REQUIRED", "name is required")
+	}
+	if supplier.LeadTimeDays < 0 {
+		return newError(ErrInvalidArgument, "INVALID_LEAD_TIME", "lead_time_days cannot be negative")
+	}
+	return nil
+}
+
+func (s *InventoryService) ListSuppliers(ctx context.Context, includeInactive bool) ([]*models.Supplier, error) {
+	return s.repo.ListSuppliers(ctx, includeInactive)
+}
+
+// CreatePurchaseOrder records an open purchase order from an active
+// supplier. Without an expected date, delivery is expected after the
+// supplier's lead time. A non-empty requestID makes the call idempotent.
+func (s *InventoryService) CreatePurchaseOrder(ctx context.Context, po *models.PurchaseOrder, requestID string) error {
+	if po.SupplierCode == "" {
+		return newError(ErrInvalidArgument, "SUPPLIER_CODE_REQUIRED", "supplier_code is required")
+	}
+	supplier, err := s.repo.GetSupplier(ctx, po.SupplierCode)
+	if err != nil {
+		return err
+	}
+	if !supplier.Active {
+		return newError(ErrFailedPrecondition, "SUPPLIER_INACTIVE", "supplier %s is inactive", supplier.Code)
+	}
+	if po.LocationCode, err = s.resolveLocation(ctx, po.LocationCode); err != nil {
+		return err
+	}
+	if len(po.Lines) == 0 {
+		return newError(ErrInvalidArgument, "EMPTY_BATCH", "lines cannot be empty")
+	}
+	if len(po.Lines) > MaxBatchSize {
+		return newError(ErrInvalidArgument, "BATCH_TOO_LARGE", "at most %d lines per purchase order", MaxBatchSize)
+	}
+	seen := make(map[string]bool, len(po.Lines))
+	for _, line := range po.Lines {
+		if line.ProductID == "" {
+			return newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+		}
+		if line.Quantity <= 0 {
+			return newError(ErrInvalidArgument, "INVALID_QUANTITY", "quantity must be positive")
+		}
+		if line.UnitCost < 0 {
+			return newError(ErrInvalidArgument, "INVALID_UNIT_COST", "unit_cost cannot be negative")
+		}
+		if seen[line.ProductID] {
+			return newError(ErrInvalidArgument, "DUPLICATE_LINE", "product %s is listed more than once", line.ProductID)
+		}
+		seen[line.ProductID] = true
+	}
+	// Stored in product_id order, which is also the order receiving locks rows
+	slices.SortFunc(po.Lines, func(a, b models.PurchaseOrderLine) int { return cmp.Compare(a.ProductID, b.ProductID) })
+
+	if po.ExpectedAt == nil {
+		expected := time.Now().UTC().AddDate(0, 0, int(supplier.LeadTimeDays))
+		po.ExpectedAt = &expected
+	}
+	po.Status = models.PurchaseOrderOpen
+	po.CreatedBy = defaultActor(po.CreatedBy)
+	return s.repo.CreatePurchaseOrder(ctx, po, requestID)
+}
+
+func (s *InventoryService) GetPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error) {
+	return s.repo.GetPurchaseOrder(ctx, id)
+}
+
+// ListPurchaseOrders returns one page of purchase orders, newest first, and
+// the token for the next page (empty on the last page).
+func (s *InventoryService) ListPurchaseOrders(ctx context.Context, q models.PurchaseOrderQuery) ([]*models.PurchaseOrder, string, error) {
+	switch q.Status {
+	case "", models.PurchaseOrderOpen, models.PurchaseOrderPartiallyReceived, models.PurchaseOrderReceived, models.PurchaseOrderCancelled:
+	default:
+		return nil, "", newError(ErrInvalidArgument, "INVALID_STATUS", "unknown purchase order status %q", q.Status)
+	}
+	var err error
+	if q.BeforeID, err = decodePageToken(q.PageToken); err != nil {
+		return nil, "", err
+	}
+	limit := pageSize(q.Limit)
+	q.Limit = limit + 1
+
+	orders, err := s.repo.ListPurchaseOrders(ctx, q)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(orders) <= limit {
+		return orders, "", nil
+	}
+	orders = orders[:limit]
+	return orders, encodePageToken(orders[limit-1].ID), nil
+}
+
+// ReceivePurchaseOrder adds one delivery of a purchase order to stock.
+// Deliveries may be partial; each product's total can't exceed what was
+// ordered. A non-empty requestID makes the call idempotent.
+func (s *InventoryService) ReceivePurchaseOrder(ctx context.Context, id int64, deliveries []models.Delivery, actor, requestID string) (*models.PurchaseOrder, error) {
+	if len(deliveries) == 0 {
+		return nil, newError(ErrInvalidArgument, "EMPTY_BATCH", "lines cannot be empty")
+	}
+	seen := make(map[string]bool, len(deliveries))
+	for _, d := range deliveries {
+		if d.ProductID == "" {
+			return nil, newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+		}
+		if d.Quantity < 0 {
+			return nil, newError(ErrInvalidArgument, "INVALID_QUANTITY", "received quantity cannot be negative")
+		}
+		if err := validateLot(d.Lot, d.Quantity); err != nil {
+			return nil, err
+		}
+		if seen[d.ProductID] {
+			return nil, newError(ErrInvalidArgument, "DUPLICATE_LINE", "product %s is listed more than once", d.ProductID)
+		}
+		seen[d.ProductID] = true
+	}
+	po, err := s.repo.GetPurchaseOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deliveries {
+		if !slices.ContainsFunc(po.Lines, func(l models.PurchaseOrderLine) bool { return l.ProductID == d.ProductID }) {
+			return nil, newError(ErrInvalidArgument, "UNKNOWN_LINE", "product %s is not on purchase order %d", d.ProductID, id)
+		}
+	}
+	po, err = s.repo.ReceivePurchaseOrder(ctx, id, deliveries, defaultActor(actor), requestID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(deliveries))
+	for i, d := range deliveries {
+		ids[i] = d.ProductID
+	}
+	s.notifyStockChange(ctx, ids...)
+	return po, nil
+}
+
+// CancelPurchaseOrder stops expecting the rest of a purchase order.
+func (s *InventoryService) CancelPurchaseOrder(ctx context.Context, id int64) (*models.PurchaseOrder, error) {
+	return s.repo.CancelPurchaseOrder(ctx, id)
+}
+
+// IncomingStock reports what open purchase orders still bring of productID.
+func (s *InventoryService) IncomingStock(ctx context.Context, productID string) (models.IncomingStock, error) {
+	incoming, err := s.repo.IncomingStock(ctx, []string{productID})
+	if err != nil || len(incoming) == 0 {
+		return models.IncomingStock{ProductID: productID}, err
+	}
+	return incoming[0], nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+// ProductCatalog looks up product details owned by the product service.
+type ProductCatalog interface {
+	// ProductCosts returns what it knows for productIDs; unknown products
+	// are left out.
+	ProductCosts(ctx context.Context, productIDs []string) (map[string]models.ProductCost, error)
+}
+
+// inventoryCursor is the decoded form of a ListInventory page token.
+type inventoryCursor struct {
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// ListInventory returns one page of stock rows and the token for the next
+// page (empty on the last page).
+func (s *InventoryService) ListInventory(ctx context.Context, q models.InventoryQuery) ([]*models.Inventory, string, error) {
+	if err := normalizeInventoryQuery(&q); err != nil {
+		return nil, "", err
+	}
+	limit := pageSize(q.Limit)
+	q.Limit = limit + 1
+
+	inventories, err := s.repo.ListInventory(ctx, q)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(inventories) <= limit {
+		return inventories, "", nil
+	}
+	inventories = inventories[:limit]
+	return inventories, encodeInventoryCursor(q.Sort, inventories[limit-1]), nil
+}
+
+func normalizeInventoryQuery(q *models.InventoryQuery) error {
+	switch q.Filter {
+	case "", models.FilterBelowThreshold, models.FilterZeroStock:
+	default:
+		return newError(ErrInvalidArgument, "INVALID_FILTER", "unknown inventory filter %q", q.Filter)
+	}
+	switch q.Sort {
+	case "":
+		q.Sort = models.SortProductID
+	case models.SortProductID, models.SortQuantity, models.SortUpdatedAt:
+	default:
+		return newError(ErrInvalidArgument, "INVALID_SORT", "unknown inventory sort %q", q.Sort)
+	}
+	if q.PageToken == "" {
+		return nil
+	}
+
+	invalid := newError(ErrInvalidArgument, "INVALID_PAGE_TOKEN", "invalid page_token")
+	b, err := base64.RawURLEncoding.DecodeString(q.PageToken)
+	if err != nil {
+		return invalid
+	}
+	var c inventoryCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == 0 {
+		return invalid
+	}
+	switch q.Sort {
+	case models.SortQuantity:
+		n, err := strconv.ParseInt(c.Value, 10, 32)
+		if err != nil {
+			return invalid
+		}
+		q.AfterValue = int32(n)
+	case models.SortUpdatedAt:
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return invalid
+		}
+		q.AfterValue = t
+	default:
+		q.AfterValue = c.Value
+	}
+	q.AfterID = c.ID
+	return nil
+}
+
+func encodeInventoryCursor(sort string, last *models.Inventory) string {
+	c := inventoryCursor{Value: last.ProductID, ID: last.ID}
+	switch sort {
+	case models.SortQuantity:
+		c.Value = strconv.Itoa(int(last.Quantity))
+	case models.SortUpdatedAt:
+		c.Value = last.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// reportPageSize is how many rows a report reads, and prices, per round trip.
+const reportPageSize = MaxBatchSize
+
+// eachInventoryPage calls fn with every page of rows matching q, each page
+// joined with its products' costs.
+func (s *InventoryService) eachInventoryPage(ctx context.Context, q models.InventoryQuery, fn func([]*models.Inventory, map[string]models.ProductCost) error) error {
+	if err := normalizeInventoryQuery(&q); err != nil {
+		return err
+	}
+	q.Limit = reportPageSize
+	for {
+		page, err := s.repo.ListInventory(ctx, q)
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			return nil
+		}
+		ids := make([]string, len(page))
+		for i, inv := range page {
+			ids[i] = inv.ProductID
+		}
+		costs, err := s.catalog.ProductCosts(ctx, ids)
+		if err != nil {
+			return err
+		}
+		if err := fn(page, costs); err != nil {
+			return err
+		}
+		if len(page) < reportPageSize {
+			return nil
+		}
+
+		last := page[len(page)-1]
+		q.AfterValue, q.AfterID = sortValue(q.Sort, last), last.ID
+	}
+}
+
+// sortValue is the value of inv's sort column, as the repository compares it.
+func sortValue(sort string, inv *models.Inventory) any {
+	switch sort {
+	case models.SortQuantity:
+		return inv.Quantity
+	case models.SortUpdatedAt:
+		return inv.UpdatedAt
+	}
+	return inv.ProductID
+}
+
+// InventoryValuation values all stock, archived products' included, at each
+// product's cost price. Products without a cost in the catalogue count as
+// unpriced and add nothing to the total.
+func (s *InventoryService) InventoryValuation(ctx context.Context) (*models.ValuationReport, error) {
+	report := &models.ValuationReport{GeneratedAt: time.Now().UTC()}
+	err := s.eachInventoryPage(ctx, models.InventoryQuery{WithArchived: true}, func(page []*models.Inventory, costs map[string]models.ProductCost) error {
+		for _, inv := range page {
+			line := valuationLine(inv, costs)
+			if !line.Priced {
+				report.UnpricedProducts++
+			}
+			report.TotalQuantity += int64(inv.Quantity)
+			report.TotalValue += line.Value
+			report.Lines = append(report.Lines, line)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.TotalValue = roundCents(report.TotalValue)
+	return report, nil
+}
+
+func valuationLine(inv *models.Inventory, costs map[string]models.ProductCost) models.ValuationLine {
+	line := models.ValuationLine{ProductID: inv.ProductID, Quantity: inv.Quantity}
+	if cost, ok := costs[inv.ProductID]; ok {
+		line.Name, line.CostPrice, line.Priced = cost.Name, cost.CostPrice, true
+		line.Value = roundCents(float64(inv.Quantity) * cost.CostPrice)
+	}
+	return line
+}
+
+func roundCents(v float64) float64 { return math.Round(v*100) / 100 }
+
+// ExportInventoryCSV writes every row matching q, with its valuation, to w
+// as CSV with a header row. Like the valuation it covers archived products.
+// q's paging fields are ignored.
+func (s *InventoryService) ExportInventoryCSV(ctx context.Context, q models.InventoryQuery, w io.Writer) error {
+	q.PageToken, q.WithArchived = "", true
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"product_id", "name", "quantity", "reorder_point", "stock_state", "cost_price", "value", "updated_at"}); err != nil {
+		return err
+	}
+	err := s.eachInventoryPage(ctx, q, func(page []*models.Inventory, costs map[string]models.ProductCost) error {
+		for _, inv := range page {
+			line := valuationLine(inv, costs)
+			costPrice, value := "", ""
+			if line.Priced {
+				costPrice = strconv.FormatFloat(line.CostPrice, 'f', 2, 64)
+				value = strconv.FormatFloat(line.Value, 'f', 2, 64)
+			}
+			if err := cw.Write([]string{
+				inv.ProductID,
+				line.Name,
+				strconv.Itoa(int(inv.Quantity)),
+				strconv.Itoa(int(inv.ReorderPoint)),
+				models.StockStateOf(inv.Quantity, inv.ReorderPoint),
+				costPrice,
+				value,
+				inv.UpdatedAt.UTC().Format(time.RFC3339),
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+// EventPublisher delivers stock events to the message broker.
+type EventPublisher interface {
+	PublishOutboxEvent(ctx context.Context, event *models.OutboxEvent) error
+}
+
+// SetReorderPoint changes the level at which the product counts as low on
+// stock, alerting in the background if it already is.
+func (s *InventoryService) SetReorderPoint(ctx context.Context, productID string, reorderPoint int32) (*models.Inventory, error) {
+	if productID == "" {
+		return nil, newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+	}
+	if reorderPoint < 0 {
+		return nil, newError(ErrInvalidArgument, "INVALID_REORDER_POINT", "reorder_point cannot be negative")
+	}
+	inv, err := s.repo.SetReorderPoint(ctx, productID, reorderPoint)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyStockChange(ctx, productID)
+	return inv, nil
+}
+
+// notifyStockChange queues products whose stock just changed for
+// RunBackorderFiller, which fills their backorders and then sends the low,
+// out of and back in stock alerts they call for. The change already
+// committed, so nothing here can fail it.
+func (s *InventoryService) notifyStockChange(ctx context.Context, productIDs ...string) {
+	if len(productIDs) == 0 {
+		return
+	}
+	s.queueBackorderFill(productIDs)
+}
+
+// alertStockChange saves the stock alerts the current stock of productIDs
+// calls for to the outbox.
+func (s *InventoryService) alertStockChange(ctx context.Context, productIDs []string) {
+	if len(productIDs) == 0 {
+		return
+	}
+	var announce func(models.StockAlert) (*models.OutboxEvent, error)
+	if s.publisher != nil {
+		announce = stockAlertEvent
+	}
+	alerts, err := s.repo.EvaluateStockAlerts(ctx, productIDs, s.alertDebounce, announce)
+	if err != nil {
+		slog.Error("Failed to evaluate stock alerts", "products", len(productIDs), "error", err)
+		return
+	}
+	if len(alerts) > 0 && announce != nil {
+		s.outboxSaved()
+	}
+}
+
+func stockAlertEvent(alert models.StockAlert) (*models.OutboxEvent, error) {
+	event := &models.StockEvent{
+		ID:         newEventID(),
+		Type:       alert.Type,
+		Version:    models.StockEventVersion,
+		OccurredAt: time.Now().UTC(),
+		Data: &models.StockEventData{
+			ProductID:    alert.ProductID,
+			Quantity:     alert.Quantity,
+			ReorderPoint: alert.ReorderPoint,
+		},
+	}
+	return models.NewOutboxEvent(event.ID, event.Type, event.OccurredAt, event)
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+// MaxImportSize caps how many counts one stock import may carry.
+const MaxImportSize = 5000
+
+// ImportStock replaces stored quantities with physically counted ones. Counts
+// without a location are taken at locationCode, or the default location when
+// that is empty too. Every product and location may be counted once. The
+// differences are recorded as cycle-count movements; with dryRun they are
+// only reported. When any line can't be applied the results explain which,
+// and repository.ErrAdjustmentRejected is returned.
+func (s *InventoryService) ImportStock(ctx context.Context, counts []models.StockCount, locationCode string, info models.MovementInfo, requestID string, dryRun bool) ([]models.StockCountResult, error) {
+	if len(counts) == 0 {
+		return nil, newError(ErrInvalidArgument, "EMPTY_IMPORT", "at least one count is required")
+	}
+	if len(counts) > MaxImportSize {
+		return nil, newError(ErrInvalidArgument, "IMPORT_TOO_LARGE", "at most %d counts per import", MaxImportSize)
+	}
+	resolved := make(map[string]string)
+	seen := make(map[[2]string]bool, len(counts))
+	counts = slices.Clone(counts)
+	for i, c := range counts {
+		if c.ProductID == "" {
+			return nil, newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "count %d: product_id is required", i+1)
+		}
+		if c.Counted < 0 {
+			return nil, newError(ErrInvalidArgument, "INVALID_QUANTITY", "count %d: counted quantity cannot be negative", i+1)
+		}
+		if c.LocationCode == "" {
+			c.LocationCode = locationCode
+		}
+		code, ok := resolved[c.LocationCode]
+		if !ok {
+			var err error
+			if code, err = s.resolveLocation(ctx, c.LocationCode); err != nil {
+				return nil, err
+			}
+			resolved[c.LocationCode] = code
+		}
+		key := [2]string{c.ProductID, code}
+		if seen[key] {
+			return nil, newError(ErrInvalidArgument, "DUPLICATE_COUNT", "count %d: product %s at %s is counted more than once", i+1, c.ProductID, code)
+		}
+		seen[key] = true
+		counts[i].LocationCode = code
+	}
+	info.Reason = models.ReasonCycleCount
+	info, err := normalizeMovementInfo(info)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.repo.ImportStockCounts(ctx, counts, info, requestID, dryRun)
+	if err == nil && !dryRun {
+		ids := make([]string, len(counts))
+		for i, c := range counts {
+			ids[i] = c.ProductID
+		}
+		s.notifyStockChange(ctx, ids...)
+	}
+	return results, err
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+// stockBroadcaster fans stock changes out to WatchStock streams.
+type stockBroadcaster struct {
+	mu       sync.Mutex
+	watchers map[string]map[*stockWatcher]struct{} // By product id
+}
+
+// stockWatcher holds the changes one stream hasn't sent yet. Only the latest
+// change per product is kept, so a slow reader costs at most one entry per
+// watched product and simply skips intermediate quantities.
+type stockWatcher struct {
+	mu      sync.Mutex
+	pending map[string]models.StockChange
+	ready   chan struct{} // Signalled when pending becomes non-empty
+}
+
+func newStockBroadcaster() *stockBroadcaster {
+	return &stockBroadcaster{watchers: make(map[string]map[*stockWatcher]struct{})}
+}
+
+func (b *stockBroadcaster) subscribe(productIDs []string) *stockWatcher {
+	w := &stockWatcher{pending: make(map[string]models.StockChange), ready: make(chan struct{}, 1)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, id := range productIDs {
+		if b.watchers[id] == nil {
+			b.watchers[id] = make(map[*stockWatcher]struct{})
+		}
+		b.watchers[id][w] = struct{}{}
+	}
+	return w
+}
+
+func (b *stockBroadcaster) unsubscribe(w *stockWatcher, productIDs []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, id := range productIDs {
+		delete(b.watchers[id], w)
+		if len(b.watchers[id]) == 0 {
+			delete(b.watchers, id)
+		}
+	}
+}
+
+// publish never blocks, however slow the watchers are.
+func (b *stockBroadcaster) publish(change models.StockChange) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for w := range b.watchers[change.ProductID] {
+		w.offer(change)
+	}
+}
+
+func (b *stockBroadcaster) watching(productID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.watchers[productID]) > 0
+}
+
+func (b *stockBroadcaster) watchedProducts() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ids := make([]string, 0, len(b.watchers))
+	for id := range b.watchers {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (w *stockWatcher) offer(change models.StockChange) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// Notifications from different transactions can arrive out of order
+	if prev, ok := w.pending[change.ProductID]; ok && change.UpdatedAt.Before(prev.UpdatedAt) {
+		return
+	}
+	w.pending[change.ProductID] = change
+	select {
+	case w.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (w *stockWatcher) take() []models.StockChange {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	changes := make([]models.StockChange, 0, len(w.pending))
+	for _, c := range w.pending {
+		changes = append(changes, c)
+	}
+	clear(w.pending)
+	slices.SortFunc(changes, func(a, b models.StockChange) int { return a.UpdatedAt.Compare(b.UpdatedAt) })
+	return changes
+}
+
+// WatchStock sends the current stock of productIDs, then every change to it,
+// until ctx is done or send fails. Products without stock are reported with
+// quantity 0.
+func (s *InventoryService) WatchStock(ctx context.Context, productIDs []string, send func(models.StockChange) error) error {
+	if len(productIDs) == 0 {
+		return newError(ErrInvalidArgument, "PRODUCT_IDS_REQUIRED", "at least one product_id is required")
+	}
+	if len(productIDs) > MaxBatchSize {
+		return newError(ErrInvalidArgument, "BATCH_TOO_LARGE", "at most %d products per watch", MaxBatchSize)
+	}
+	if slices.Contains(productIDs, "") {
+		return newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+	}
+	ids := slices.Clone(productIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	// Subscribe before reading the snapshot so no change falls in between
+	w := s.watchers.subscribe(ids)
+	defer s.watchers.unsubscribe(w, ids)
+
+	sent := make(map[string]models.StockChange, len(ids))
+	snapshot, err := s.stockChanges(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, c := range snapshot {
+		if err := send(c); err != nil {
+			return err
+		}
+		sent[c.ProductID] = c
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.ready:
+		}
+		for _, c := range w.take() {
+			// Skip what the snapshot or an earlier message already covered
+			if prev, ok := sent[c.ProductID]; ok && (c.UpdatedAt.Before(prev.UpdatedAt) ||
+				c.Quantity == prev.Quantity && c.ReorderPoint == prev.ReorderPoint) {
+				continue
+			}
+			if err := send(c); err != nil {
+				return err
+			}
+			sent[c.ProductID] = c
+		}
+	}
+}
+
+// stockChanges reads the current sellable stock of ids as changes, one per
+// id.
+func (s *InventoryService) stockChanges(ctx context.Context, ids []string) ([]models.StockChange, error) {
+	byProduct, err := s.GetStocks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]models.StockChange, len(ids))
+	for i, id := range ids {
+		changes[i] = models.StockChange{ProductID: id}
+		if inv, ok := byProduct[id]; ok {
+			changes[i].Quantity = inv.Quantity
+			changes[i].ReorderPoint = inv.ReorderPoint
+			changes[i].UpdatedAt = inv.UpdatedAt
+		}
+	}
+	return changes, nil
+}
+
+// PublishStockChange passes a committed change on to the streams watching
+// the product. The change carries the stored quantity, so stock in expired
+// lots is taken out first.
+func (s *InventoryService) PublishStockChange(ctx context.Context, change models.StockChange) error {
+	if !s.watchers.watching(change.ProductID) {
+		return nil
+	}
+	expired, err := s.repo.ExpiredLotStock(ctx, []string{change.ProductID})
+	if err != nil {
+		return err
+	}
+	inv := &models.Inventory{ProductID: change.ProductID, Quantity: change.Quantity}
+	withoutExpiredLots(expired, []*models.Inventory{inv}, nil)
+	change.Quantity = inv.Quantity
+	s.watchers.publish(change)
+	return nil
+}
+
+// ResyncStockWatchers republishes the current stock of every watched
+// product, for when changes may have been missed. Streams only forward the
+// ones that differ from what they last sent.
+func (s *InventoryService) ResyncStockWatchers(ctx context.Context) {
+	ids := s.watchers.watchedProducts()
+	for start := 0; start < len(ids); start += MaxBatchSize {
+		batch := ids[start:min(start+MaxBatchSize, len(ids))]
+		changes, err := s.stockChanges(ctx, batch)
+		if err != nil {
+			slog.Error("Failed to resync stock watchers", "products", len(batch), "error", err)
+			return
+		}
+		for _, c := range changes {
+			s.watchers.publish(c)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/repository"
+)
+
+func TestStockWatcherKeepsLatestChangePerProduct(t *testing.T) {
+	b := newStockBroadcaster()
+	w := b.subscribe([]string{"a", "b"})
+	t0 := time.Now()
+
+	b.publish(models.StockChange{ProductID: "a", Quantity: 5, UpdatedAt: t0})
+	b.publish(models.StockChange{ProductID: "b", Quantity: 9, UpdatedAt: t0.Add(time.Second)})
+	b.publish(models.StockChange{ProductID: "a", Quantity: 4, UpdatedAt: t0.Add(2 * time.Second)})
+	// Arrives late from an older transaction
+	b.publish(models.StockChange{ProductID: "a", Quantity: 7, UpdatedAt: t0.Add(time.Millisecond)})
+	b.publish(models.StockChange{ProductID: "c", Quantity: 1, UpdatedAt: t0})
+
+	select {
+	case <-w.ready:
+	default:
+		t.Fatal("watcher not signalled")
+	}
+	got := w.take()
+	if len(got) != 2 || got[0].ProductID != "b" || got[1].ProductID != "a" || got[1].Quantity != 4 {
+		t.Fatalf("changes = %+v, want b then a at 4", got)
+	}
+	if got := w.take(); len(got) != 0 {
+		t.Fatalf("changes after take = %+v, want none", got)
+	}
+}
+
+func TestStockBroadcasterPublishNeverBlocks(t *testing.T) {
+	b := newStockBroadcaster()
+	slow := b.subscribe([]string{"a"})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 10_000 {
+			b.publish(models.StockChange{ProductID: "a", Quantity: int32(i), UpdatedAt: time.Unix(int64(i), 0)})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish blocked on a watcher that never reads")
+	}
+
+	if len(slow.ready) != 1 {
+		t.Fatalf("ready holds %d signals, want 1", len(slow.ready))
+	}
+	got := slow.take()
+	if len(got) != 1 || got[0].Quantity != 9_999 {
+		t.Fatalf("changes = %+v, want only the last", got)
+	}
+}
+
+func TestStockBroadcasterUnsubscribe(t *testing.T) {
+	b := newStockBroadcaster()
+	w1 := b.subscribe([]string{"a", "b"})
+	w2 := b.subscribe([]string{"b"})
+	b.unsubscribe(w1, []string{"a", "b"})
+
+	if ids := b.watchedProducts(); !slices.Equal(ids, []string{"b"}) {
+		t.Fatalf("watched = %v, want [b]", ids)
+	}
+	b.publish(models.StockChange{ProductID: "b", Quantity: 1})
+	if got := w1.take(); len(got) != 0 {
+		t.Fatalf("unsubscribed watcher got %+v", got)
+	}
+	if got := w2.take(); len(got) != 1 {
+		t.Fatalf("remaining watcher got %+v, want one change", got)
+	}
+}
+
+// expiredLotRepo holds 10 of product "a" at each of two locations, 3 of
+// them in an expired lot at "north".
+type expiredLotRepo struct {
+	repository.InventoryRepository
+}
+
+func (expiredLotRepo) GetStocks(_ context.Context, ids []string) ([]*models.Inventory, error) {
+	if !slices.Contains(ids, "a") {
+		return nil, nil
+	}
+	return []*models.Inventory{{ProductID: "a", Quantity: 20}}, nil
+}
+
+func (expiredLotRepo) GetLocationStocks(context.Context, []string) ([]*models.LocationStock, error) {
+	return []*models.LocationStock{
+		{ProductID: "a", LocationCode: "north", Quantity: 10},
+		{ProductID: "a", LocationCode: "south", Quantity: 10},
+	}, nil
+}
+
+func (expiredLotRepo) ExpiredLotStock(_ context.Context, ids []string) ([]*models.LocationStock, error) {
+	if !slices.Contains(ids, "a") {
+		return nil, nil
+	}
+	return []*models.LocationStock{{ProductID: "a", LocationCode: "north", Quantity: 3}}, nil
+}
+
+func (expiredLotRepo) GetLocation(_ context.Context, code string) (*models.Location, error) {
+	return &models.Location{Code: code}, nil
+}
+
+func TestStockLeavesOutExpiredLots(t *testing.T) {
+	s := &InventoryService{repo: expiredLotRepo{}, watchers: newStockBroadcaster()}
+	ctx := context.Background()
+
+	inv, stocks, err := s.GetStock(ctx, "a", "")
+	if err != nil {
+		t.Fatalf("get stock: %v", err)
+	}
+	if inv.Quantity != 17 || len(stocks) != 2 || stocks[0].Quantity != 7 || stocks[1].Quantity != 10 {
+		t.Fatalf("stock = %d %+v, want 17 with north 7 and south 10", inv.Quantity, stocks)
+	}
+	if _, stocks, err = s.GetStock(ctx, "a", "north"); err != nil || len(stocks) != 1 || stocks[0].Quantity != 7 {
+		t.Fatalf("stock at north = %+v, %v, want 7", stocks, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sent := make(chan models.StockChange)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.WatchStock(ctx, []string{"a", "b"}, func(c models.StockChange) error {
+			sent <- c
+			return nil
+		})
+	}()
+	for _, want := range []int32{17, 0} {
+		if c := <-sent; c.Quantity != want {
+			t.Fatalf("snapshot %s = %d, want %d", c.ProductID, c.Quantity, want)
+		}
+	}
+	// The notification carries the stored quantity
+	if err := s.PublishStockChange(ctx, models.StockChange{ProductID: "a", Quantity: 12, UpdatedAt: time.Now()}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if c := <-sent; c.Quantity != 9 {
+		t.Fatalf("change = %d, want 9", c.Quantity)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+}
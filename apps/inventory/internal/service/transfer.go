@@ -0,0 +1,155 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/thapakon-thai/eshop-microservices/inventory/internal/models"
+)
+
+// CreateTransfer records a pending transfer; no stock moves until it ships.
+// Lines for the same product are combined. A non-empty requestID makes the
+// call idempotent.
+func (s *InventoryService) CreateTransfer(ctx context.Context, transfer *models.StockTransfer, requestID string) error {
+	if transfer.FromLocation == "" || transfer.ToLocation == "" {
+		return newError(ErrInvalidArgument, "LOCATION_REQUIRED", "from_location and to_location are required")
+	}
+	if transfer.FromLocation == transfer.ToLocation {
+		return newError(ErrInvalidArgument, "SAME_LOCATION", "cannot transfer stock to the location it is at")
+	}
+	for _, code := range []string{transfer.FromLocation, transfer.ToLocation} {
+		if _, err := s.resolveLocation(ctx, code); err != nil {
+			return err
+		}
+	}
+	if len(transfer.Lines) == 0 {
+		return newError(ErrInvalidArgument, "EMPTY_BATCH", "lines cannot be empty")
+	}
+	if len(transfer.Lines) > MaxBatchSize {
+		return newError(ErrInvalidArgument, "BATCH_TOO_LARGE", "at most %d lines per transfer", MaxBatchSize)
+	}
+
+	// Stored in product_id order, which is also the order shipping locks rows
+	byProduct := make(map[string]int32)
+	for _, line := range transfer.Lines {
+		if line.ProductID == "" {
+			return newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+		}
+		if line.Quantity <= 0 {
+			return newError(ErrInvalidArgument, "INVALID_QUANTITY", "quantity must be positive")
+		}
+		byProduct[line.ProductID] += line.Quantity
+	}
+	transfer.Lines = transfer.Lines[:0]
+	for id, qty := range byProduct {
+		transfer.Lines = append(transfer.Lines, models.StockTransferLine{ProductID: id, Quantity: qty})
+	}
+	slices.SortFunc(transfer.Lines, func(a, b models.StockTransferLine) int { return cmp.Compare(a.ProductID, b.ProductID) })
+
+	transfer.Status = models.TransferPending
+	if transfer.CreatedBy == "" {
+		transfer.CreatedBy = "unknown"
+	}
+	return s.repo.CreateTransfer(ctx, transfer, requestID)
+}
+
+func (s *InventoryService) GetTransfer(ctx context.Context, id int64) (*models.StockTransfer, error) {
+	return s.repo.GetTransfer(ctx, id)
+}
+
+// ListTransfers returns one page of transfers, newest first, and the token
+// for the next page (empty on the last page).
+func (s *InventoryService) ListTransfers(ctx context.Context, q models.TransferQuery) ([]*models.StockTransfer, string, error) {
+	switch q.Status {
+	case "", models.TransferPending, models.TransferInTransit, models.TransferReceived, models.TransferCancelled:
+	default:
+		return nil, "", newError(ErrInvalidArgument, "INVALID_STATUS", "unknown transfer status %q", q.Status)
+	}
+	var err error
+	if q.BeforeID, err = decodePageToken(q.PageToken); err != nil {
+		return nil, "", err
+	}
+	limit := pageSize(q.Limit)
+	q.Limit = limit + 1
+
+	transfers, err := s.repo.ListTransfers(ctx, q)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(transfers) <= limit {
+		return transfers, "", nil
+	}
+	transfers = transfers[:limit]
+	return transfers, encodePageToken(transfers[limit-1].ID), nil
+}
+
+// ShipTransfer deducts a pending transfer's lines from its source location.
+func (s *InventoryService) ShipTransfer(ctx context.Context, id int64, actor string) (*models.StockTransfer, error) {
+	transfer, err := s.repo.ShipTransfer(ctx, id, defaultActor(actor))
+	if err != nil {
+		return nil, err
+	}
+	s.notifyStockChange(ctx, transferProductIDs(transfer)...)
+	return transfer, nil
+}
+
+// ReceiveTransfer adds an in-transit transfer's lines to its destination.
+// received overrides the quantity for products that didn't arrive as
+// shipped; a discrepancy should come with a note saying why.
+func (s *InventoryService) ReceiveTransfer(ctx context.Context, id int64, received []models.ReceivedLine, actor string) (*models.StockTransfer, error) {
+	seen := make(map[string]bool, len(received))
+	for _, rl := range received {
+		if rl.ProductID == "" {
+			return nil, newError(ErrInvalidArgument, "PRODUCT_ID_REQUIRED", "product_id is required")
+		}
+		if rl.Quantity < 0 {
+			return nil, newError(ErrInvalidArgument, "INVALID_QUANTITY", "received quantity cannot be negative")
+		}
+		if seen[rl.ProductID] {
+			return nil, newError(ErrInvalidArgument, "DUPLICATE_LINE", "product %s is listed more than once", rl.ProductID)
+		}
+		seen[rl.ProductID] = true
+	}
+	transfer, err := s.repo.GetTransfer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, rl := range received {
+		if !slices.ContainsFunc(transfer.Lines, func(l models.StockTransferLine) bool { return l.ProductID == rl.ProductID }) {
+			return nil, newError(ErrInvalidArgument, "UNKNOWN_LINE", "product %s is not on transfer %d", rl.ProductID, id)
+		}
+	}
+	transfer, err = s.repo.ReceiveTransfer(ctx, id, received, defaultActor(actor))
+	if err != nil {
+		return nil, err
+	}
+	s.notifyStockChange(ctx, transferProductIDs(transfer)...)
+	return transfer, nil
+}
+
+// CancelTransfer abandons a pending or in-transit transfer; shipped stock
+// returns to the source location.
+func (s *InventoryService) CancelTransfer(ctx context.Context, id int64, actor string) (*models.StockTransfer, error) {
+	transfer, err := s.repo.CancelTransfer(ctx, id, defaultActor(actor))
+	if err != nil {
+		return nil, err
+	}
+	s.notifyStockChange(ctx, transferProductIDs(transfer)...)
+	return transfer, nil
+}
+
+func defaultActor(actor string) string {
+	if actor == "" {
+		return "unknown"
+	}
+	return actor
+}
+
+func transferProductIDs(transfer *models.StockTransfer) []string {
+	ids := make([]string, len(transfer.Lines))
+	for i, line := range transfer.Lines {
+		ids[i] = line.ProductID
+	}
+	return ids
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"os"
@@ -38,7 +39,7 @@ func main() {
 	}
 
 	// Migrate database schema
-	if err := gormDB.AutoMigrate(&models.Order{}, &models.OrderItem{}); err != nil {
+	if err := gormDB.AutoMigrate(&models.Order{}, &models.OrderItem{}, &models.OrderAllocation{}, &models.OrderBackorder{}, &models.PendingBackorderFill{}); err != nil {
 		slog.Error("Failed to migrate database schema", "error", err)
 		os.Exit(1)
 	}
@@ -62,6 +63,20 @@ func main() {
 	svc := service.NewOrderService(repo, grpcClients, publisher)
 	h := handler.NewOrderHandler(svc)
 
+	// Orders waiting on backorders move on when inventory fills them
+	consumer, err := infrastructure.NewInventoryEventConsumer(os.Getenv("RABBITMQ_URL"), svc)
+	if err != nil {
+		slog.Error("Failed to initialize inventory event consumer", "error", err)
+		os.Exit(1)
+	}
+	defer consumer.Close()
+	go func() {
+		if err := consumer.Start(context.Background()); err != nil {
+			slog.Error("Inventory event consumer stopped", "error", err)
+			os.Exit(1)
+		}
+	}()
+
 	// Router
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
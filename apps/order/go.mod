@@ -25,7 +25,7 @@ require (
 	github.com/jinzhu/now v1.1.5 // indirect
 	github.com/shopspring/decimal v1.4.0
 	golang.org/x/crypto v0.46.0 // indirect
-	golang.org/x/sync v0.19.0 // indirect
+	golang.org/x/sync v0.19.0
 	golang.org/x/text v0.32.0 // indirect
 	gorm.io/driver/postgres v1.5.7
 	gorm.io/gorm v1.25.7
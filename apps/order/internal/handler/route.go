@@ -2,11 +2,15 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/thapakon-thai/eshop-microservices/order/internal/models"
 	"github.com/thapakon-thai/eshop-microservices/order/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
 type OrderHandler struct {
@@ -24,6 +28,7 @@ func Route(handler *OrderHandler) chi.Router {
 	r.Post("/orders", handler.CreateOrder)
 	r.Get("/orders", handler.ListOrders)
 	r.Get("/orders/{id}", handler.GetOrders)
+	r.Post("/orders/product-references", handler.ProductReferences)
 	return r
 }
 
@@ -49,7 +54,7 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 
 	order, err := h.service.CreateOrder(r.Context(), &req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -67,7 +72,7 @@ func (h *OrderHandler) GetOrders(w http.ResponseWriter, r *http.Request) {
 
 	order, err := h.service.GetOrders(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -85,3 +90,67 @@ func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(orders)
 }
+
+type productReferences struct {
+	ProductIDs []string `json:"product_ids"`
+}
+
+func (h *OrderHandler) ProductReferences(w http.ResponseWriter, r *http.Request) {
+	var req productReferences
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ids, err := h.service.ReferencedProducts(r.Context(), req.ProductIDs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(productReferences{ProductIDs: ids})
+}
+
+// writeError responds with the HTTP status matching err: order validation
+// failures, missing records, and gRPC status codes from upstream services.
+func writeError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), httpStatus(err))
+}
+
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidOrder):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrProductUnavailable), errors.Is(err, service.ErrPurchaseLimit):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrInsufficientStock):
+		return http.StatusConflict
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.FailedPrecondition, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/thapakon-thai/eshop-microservices/order/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"invalid order", fmt.Errorf("%w: no items", service.ErrInvalidOrder), http.StatusBadRequest},
+		{"product unavailable", service.ErrProductUnavailable, http.StatusUnprocessableEntity},
+		{"purchase limit", &service.PurchaseLimitError{ProductID: "p1", Limit: 2}, http.StatusUnprocessableEntity},
+		{"insufficient stock", service.ErrInsufficientStock, http.StatusConflict},
+		{"order not found", gorm.ErrRecordNotFound, http.StatusNotFound},
+		{"upstream invalid argument", status.Error(codes.InvalidArgument, "bad id"), http.StatusBadRequest},
+		{"upstream not found", status.Error(codes.NotFound, "no product"), http.StatusNotFound},
+		{"upstream already exists", status.Error(codes.AlreadyExists, "taken"), http.StatusConflict},
+		{"upstream failed precondition", status.Error(codes.FailedPrecondition, "archived"), http.StatusConflict},
+		{"upstream aborted", status.Error(codes.Aborted, "conflict"), http.StatusConflict},
+		{"upstream permission denied", status.Error(codes.PermissionDenied, "no"), http.StatusForbidden},
+		{"upstream unauthenticated", status.Error(codes.Unauthenticated, "who"), http.StatusUnauthorized},
+		{"upstream exhausted", status.Error(codes.ResourceExhausted, "slow down"), http.StatusTooManyRequests},
+		{"upstream unavailable", status.Error(codes.Unavailable, "down"), http.StatusServiceUnavailable},
+		{"upstream deadline", status.Error(codes.DeadlineExceeded, "slow"), http.StatusGatewayTimeout},
+		{"upstream internal", status.Error(codes.Internal, "boom"), http.StatusInternalServerError},
+		{"plain error", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := httpStatus(tt.err); got != tt.want {
+				t.Fatalf("httpStatus(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/thapakon-thai/eshop-microservices/order/internal/models"
+)
+
+const (
+	inventoryExchange = "inventory_events"
+	inventoryQueue    = "order_inventory_events"
+	// Events that still fail when redelivered are parked here for inspection
+	inventoryDeadLetterQueue = "order_inventory_events.dead"
+
+	backorderFilled = "inventory.backorder_filled"
+	// supportedBackorderEventVersion is the newest schema this service can read.
+	supportedBackorderEventVersion = 1
+)
+
+// backorderEvent mirrors the envelope the inventory service publishes when it
+// fills a backorder.
+type backorderEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+	Data    struct {
+		ReferenceID string `json:"reference_id"`
+		ProductID   string `json:"product_id"`
+		Quantity    int    `json:"quantity"`
+		Allocations []struct {
+			Location string `json:"location"`
+			Quantity int    `json:"quantity"`
+		} `json:"allocations"`
+	} `json:"data"`
+}
+
+// BackorderFiller is the part of the order service the consumer drives.
+type BackorderFiller interface {
+	FillBackorder(ctx context.Context, referenceID, productID string, quantity int, allocations []models.OrderAllocation) error
+}
+
+type InventoryEventConsumer struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	svc     BackorderFiller
+}
+
+func NewInventoryEventConsumer(url string, svc BackorderFiller) (*InventoryEventConsumer, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open a channel: %v", err)
+	}
+
+	c := &InventoryEventConsumer{conn: conn, channel: ch, svc: svc}
+	if err := c.declare(); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *InventoryEventConsumer) declare() error {
+	if err := c.channel.ExchangeDeclare(inventoryExchange, "topic", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare an exchange: %v", err)
+	}
+	if _, err := c.channel.QueueDeclare(inventoryDeadLetterQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare a queue: %v", err)
+	}
+	if _, err := c.channel.QueueDeclare(inventoryQueue, true, false, false, false, amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": inventoryDeadLetterQueue,
+	}); err != nil {
+		return fmt.Errorf("failed to declare a queue: %v", err)
+	}
+	if err := c.channel.QueueBind(inventoryQueue, backorderFilled, inventoryExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind a queue: %v", err)
+	}
+	return nil
+}
+
+// Start blocks, handling deliveries until the channel is closed.
+func (c *InventoryEventConsumer) Start(ctx context.Context) error {
+	msgs, err := c.channel.Consume(inventoryQueue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to register a consumer: %v", err)
+	}
+
+	slog.Info("Waiting for inventory events", "queue", inventoryQueue)
+	for msg := range msgs {
+		if err := c.handle(ctx, msg.Body); err != nil {
+			// Retry once in case the failure was transient, then dead-letter
+			// it so a poison message can't block the queue.
+			slog.Error("Failed to handle inventory event", "redelivered", msg.Redelivered, "error", err)
+			msg.Nack(false, !msg.Redelivered)
+			continue
+		}
+		msg.Ack(false)
+	}
+	return nil
+}
+
+func (c *InventoryEventConsumer) handle(ctx context.Context, body []byte) error {
+	var event backorderEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return fmt.Errorf("invalid event payload: %v", err)
+	}
+	if event.Type != backorderFilled {
+		return nil
+	}
+	if event.Version > supportedBackorderEventVersion {
+		return fmt.Errorf("unsupported %s version %d", event.Type, event.Version)
+	}
+
+	allocations := make([]models.OrderAllocation, 0, len(event.Data.Allocations))
+	for _, a := range event.Data.Allocations {
+		allocations = append(allocations, models.OrderAllocation{
+			ProductID:    event.Data.ProductID,
+			LocationCode: a.Location,
+			Quantity:     a.Quantity,
+		})
+	}
+	return c.svc.FillBackorder(ctx, event.Data.ReferenceID, event.Data.ProductID, event.Data.Quantity, allocations)
+}
+
+func (c *InventoryEventConsumer) Close() {
+	c.channel.Close()
+	c.conn.Close()
+}
@@ -6,6 +6,18 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+const (
+	// Stock is being reserved; the order becomes pending or backordered once
+	// inventory has confirmed it.
+	StatusReserving = "reserving"
+	StatusPending   = "pending"
+	// Some items are waiting for stock; the order becomes pending once
+	// inventory has filled all of its backorders.
+	StatusBackordered = "backordered"
+	// Stock could not be reserved, and none is held for the order.
+	StatusFailed = "failed"
+)
+
 type Order struct {
 	ID          int64       `json:"id" gorm:"primaryKey"`
 	UserID      string      `json:"user_id"`
@@ -13,10 +25,14 @@ type Order struct {
 	ShippingFee float64     `json:"shipping_fee"`
 	Discount    float64     `json:"discount"`
 	TotalAmount float64     `json:"total_amount"`
-	Status      string      `json:"status"` // e.g., "pending", "paid", "shipped"
+	Status      string      `json:"status"` // e.g., "pending", "backordered", "paid", "shipped"
 	CreatedAt   time.Time   `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
 	Items       []OrderItem `json:"items,omitempty" gorm:"foreignKey:OrderID"`
+
+	ShippingPostcode string            `json:"shipping_postcode,omitempty"`
+	Allocations      []OrderAllocation `json:"allocations,omitempty" gorm:"foreignKey:OrderID"`
+	Backorders       []OrderBackorder  `json:"backorders,omitempty" gorm:"foreignKey:OrderID"`
 }
 
 type OrderItem struct {
@@ -27,12 +43,45 @@ type OrderItem struct {
 	Price     decimal.Decimal `json:"price"`
 }
 
+// OrderAllocation records which stock location ships part of an order.
+type OrderAllocation struct {
+	ID           int64  `json:"-" gorm:"primaryKey"`
+	OrderID      int64  `json:"-" gorm:"index"`
+	ProductID    string `json:"product_id"`
+	LocationCode string `json:"location"`
+	Quantity     int    `json:"quantity"`
+}
+
+// OrderBackorder is part of an item inventory had no stock for yet.
+type OrderBackorder struct {
+	ID        int64      `json:"-" gorm:"primaryKey"`
+	OrderID   int64      `json:"-" gorm:"index"`
+	ProductID string     `json:"product_id"`
+	Quantity  int        `json:"quantity"`
+	FilledAt  *time.Time `json:"filled_at,omitempty"`
+}
+
+// PendingBackorderFill is a backorder fill that arrived while its order was
+// still reserving, before the order's backorders were saved. ConfirmOrder
+// applies it.
+type PendingBackorderFill struct {
+	ID          int64 `gorm:"primaryKey"`
+	OrderID     int64 `gorm:"index"`
+	ProductID   string
+	Quantity    int
+	Allocations []OrderAllocation `gorm:"serializer:json"`
+}
+
 type CreateOrderRequest struct {
 	UserID      string            `json:"user_id"`
 	Items       []CreateOrderItem `json:"items"`
 	Subtotal    float64           `json:"subtotal"`
 	ShippingFee float64           `json:"shipping_fee"`
 	Discount    float64           `json:"discount"`
+
+	ShippingPostcode string `json:"shipping_postcode"`
+	// "nearest" (default) or "fewest_splits"; see inventory AllocateStock
+	FulfillmentStrategy string `json:"fulfillment_strategy"`
 }
 
 type CreateOrderItem struct {
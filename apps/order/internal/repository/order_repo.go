@@ -2,15 +2,22 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/thapakon-thai/eshop-microservices/order/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepo interface {
-	CreateOrder(ctx context.Context, order *models.Order) error
+	CreateOrderWith(ctx context.Context, order *models.Order, check func(purchased PurchasedFunc) error) error
+	ConfirmOrder(ctx context.Context, order *models.Order) error
+	UpdateStatus(ctx context.Context, orderID int64, status string) error
 	GetOrders(ctx context.Context, id string) (*models.Order, error)
 	ListOrders(ctx context.Context) ([]*models.Order, error)
+	ReferencedProductIDs(ctx context.Context, productIDs []string) ([]string, error)
+	FillBackorder(ctx context.Context, orderID int64, productID string, quantity int, allocations []models.OrderAllocation) (*models.Order, error)
 }
 
 type PostgresqlOrderRepo struct {
@@ -22,13 +29,161 @@ func NewPostgresqlRepo(db *gorm.DB) *PostgresqlOrderRepo {
 	return &PostgresqlOrderRepo{db: db}
 }
 
-func (r *PostgresqlOrderRepo) CreateOrder(ctx context.Context, order *models.Order) error {
-	return r.db.WithContext(ctx).Create(order).Error
+// PurchasedFunc sums how many units of each of productIDs the order's user
+// has ordered since the given time, not counting failed orders. Products
+// never ordered are left out.
+type PurchasedFunc func(productIDs []string, since time.Time) (map[string]int, error)
+
+// CreateOrderWith saves a new order once check passes, in one transaction
+// holding an advisory lock on the order's user. Orders of one user are
+// checked one at a time, each seeing the orders saved before it through
+// purchased.
+func (r *PostgresqlOrderRepo) CreateOrderWith(ctx context.Context, order *models.Order, check func(purchased PurchasedFunc) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "order-user:"+order.UserID).Error; err != nil {
+			return err
+		}
+		err := check(func(productIDs []string, since time.Time) (map[string]int, error) {
+			return purchasedQuantities(tx, order.UserID, productIDs, since)
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Create(order).Error
+	})
+}
+
+// ConfirmOrder saves the status, allocations and backorders set on an order
+// once its stock is reserved, in one transaction, then applies the fills
+// FillBackorder set aside while the order was reserving. The order's
+// status, backorders and allocations are updated to match.
+func (r *PostgresqlOrderRepo) ConfirmOrder(ctx context.Context, order *models.Order) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(order).Update("status", order.Status).Error; err != nil {
+			return err
+		}
+		if len(order.Allocations) > 0 {
+			for i := range order.Allocations {
+				order.Allocations[i].OrderID = order.ID
+			}
+			if err := tx.Create(&order.Allocations).Error; err != nil {
+				return err
+			}
+		}
+		if len(order.Backorders) > 0 {
+			for i := range order.Backorders {
+				order.Backorders[i].OrderID = order.ID
+			}
+			if err := tx.Create(&order.Backorders).Error; err != nil {
+				return err
+			}
+		}
+
+		// Saving the status above waited for any FillBackorder holding the
+		// order, so every fill it set aside is visible now
+		var pending []models.PendingBackorderFill
+		if err := tx.Where("order_id = ?", order.ID).Order("id").Find(&pending).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, fill := range pending {
+			backorderID, err := fillBackorder(tx, order, fill.ProductID, fill.Quantity, fill.Allocations)
+			if err != nil {
+				return err
+			}
+			for i := range order.Backorders {
+				if order.Backorders[i].ID == backorderID {
+					order.Backorders[i].FilledAt = &now
+					order.Allocations = append(order.Allocations, fill.Allocations...)
+				}
+			}
+			if err := tx.Delete(&fill).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PostgresqlOrderRepo) UpdateStatus(ctx context.Context, orderID int64, status string) error {
+	return r.db.WithContext(ctx).Model(&models.Order{ID: orderID}).Update("status", status).Error
+}
+
+// FillBackorder marks the order's oldest open backorder of productID for
+// quantity units as filled and records where its stock came from. Once no
+// backorder is left open a backordered order becomes pending. It returns nil
+// when there is no such open backorder, e.g. for a redelivered event, and
+// when the order is still reserving: its backorders aren't saved yet, so the
+// fill is set aside for ConfirmOrder.
+func (r *PostgresqlOrderRepo) FillBackorder(ctx context.Context, orderID int64, productID string, quantity int, allocations []models.OrderAllocation) (*models.Order, error) {
+	var order models.Order
+	var filled bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error; err != nil {
+			return err
+		}
+		if order.Status == models.StatusReserving {
+			return tx.Create(&models.PendingBackorderFill{
+				OrderID:     orderID,
+				ProductID:   productID,
+				Quantity:    quantity,
+				Allocations: allocations,
+			}).Error
+		}
+		backorderID, err := fillBackorder(tx, &order, productID, quantity, allocations)
+		filled = backorderID != 0
+		return err
+	})
+	if err != nil || !filled {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// fillBackorder is FillBackorder on an order already locked by tx. It
+// returns the id of the backorder filled, or 0 if none was open.
+func fillBackorder(tx *gorm.DB, order *models.Order, productID string, quantity int, allocations []models.OrderAllocation) (int64, error) {
+	var backorder models.OrderBackorder
+	err := tx.Where("order_id = ? AND product_id = ? AND quantity = ? AND filled_at IS NULL", order.ID, productID, quantity).
+		Order("id").
+		First(&backorder).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Model(&backorder).Update("filled_at", time.Now()).Error; err != nil {
+		return 0, err
+	}
+	if len(allocations) > 0 {
+		saved := make([]models.OrderAllocation, len(allocations))
+		for i, a := range allocations {
+			a.ID = 0
+			a.OrderID = order.ID
+			saved[i] = a
+		}
+		if err := tx.Create(&saved).Error; err != nil {
+			return 0, err
+		}
+	}
+	var open int64
+	if err := tx.Model(&models.OrderBackorder{}).Where("order_id = ? AND filled_at IS NULL", order.ID).Count(&open).Error; err != nil {
+		return 0, err
+	}
+	if open == 0 && order.Status == models.StatusBackordered {
+		order.Status = models.StatusPending
+		if err := tx.Model(order).Update("status", order.Status).Error; err != nil {
+			return 0, err
+		}
+	}
+	return backorder.ID, nil
 }
 
 func (r *PostgresqlOrderRepo) GetOrders(ctx context.Context, id string) (*models.Order, error) {
 	var order models.Order
-	if err := r.db.WithContext(ctx).Preload("Items").First(&order, "id = ?", id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Items").Preload("Allocations").Preload("Backorders").First(&order, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &order, nil
@@ -36,8 +191,39 @@ func (r *PostgresqlOrderRepo) GetOrders(ctx context.Context, id string) (*models
 
 func (r *PostgresqlOrderRepo) ListOrders(ctx context.Context) ([]*models.Order, error) {
 	var orders []*models.Order
-	if err := r.db.WithContext(ctx).Preload("Items").Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Items").Preload("Allocations").Preload("Backorders").Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
 }
+
+func (r *PostgresqlOrderRepo) ReferencedProductIDs(ctx context.Context, productIDs []string) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).Model(&models.OrderItem{}).
+		Distinct("product_id").
+		Where("product_id IN ?", productIDs).
+		Pluck("product_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func purchasedQuantities(tx *gorm.DB, userID string, productIDs []string, since time.Time) (map[string]int, error) {
+	var rows []struct {
+		ProductID string
+		Quantity  int
+	}
+	if err := tx.Model(&models.OrderItem{}).
+		Select("order_items.product_id, SUM(order_items.quantity) AS quantity").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND orders.created_at >= ? AND orders.status <> ? AND order_items.product_id IN ?", userID, since, models.StatusFailed, productIDs).
+		Group("order_items.product_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	bought := make(map[string]int, len(rows))
+	for _, row := range rows {
+		bought[row.ProductID] = row.Quantity
+	}
+	return bought, nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Order failures the HTTP layer reports with a specific status. Errors from
+// the product and inventory services are wrapped with %w so their gRPC
+// status codes survive as well.
+var (
+	ErrInvalidOrder       = errors.New("invalid order")
+	ErrProductUnavailable = errors.New("product unavailable")
+	ErrInsufficientStock  = errors.New("insufficient stock")
+	ErrPurchaseLimit      = errors.New("purchase limit exceeded")
+)
+
+// PurchaseLimitError is an ErrPurchaseLimit for one product, saying how
+// many more units the user may still buy.
+type PurchaseLimitError struct {
+	ProductID  string
+	Limit      int
+	WindowDays int // 0 for the per-order limit
+	Remaining  int
+}
+
+func (e *PurchaseLimitError) Error() string {
+	scope := "per order"
+	if e.WindowDays > 0 {
+		scope = fmt.Sprintf("per %d days", e.WindowDays)
+	}
+	return fmt.Sprintf("%v: product %s is limited to %d %s; %d more allowed", ErrPurchaseLimit, e.ProductID, e.Limit, scope, e.Remaining)
+}
+
+func (e *PurchaseLimitError) Unwrap() error { return ErrPurchaseLimit }
@@ -4,6 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/thapakon-thai/eshop-microservices/order/internal/infrastructure"
@@ -11,12 +16,16 @@ import (
 	"github.com/thapakon-thai/eshop-microservices/order/internal/repository"
 	invPb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
 	pb "github.com/thapakon-thai/eshop-microservices/proto/product"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type OrderService interface {
 	CreateOrder(ctx context.Context, req *models.CreateOrderRequest) (*models.Order, error)
 	GetOrders(ctx context.Context, id string) (*models.Order, error)
 	ListOrders(ctx context.Context) ([]*models.Order, error)
+	ReferencedProducts(ctx context.Context, productIDs []string) ([]string, error)
 }
 
 type OrderServiceImpl struct {
@@ -36,48 +45,57 @@ func NewOrderService(repo repository.OrderRepo, grpcClients *infrastructure.Grpc
 
 func (s *OrderServiceImpl) CreateOrder(ctx context.Context, req *models.CreateOrderRequest) (*models.Order, error) {
 	if len(req.Items) == 0 {
-		return nil, errors.New("items cannot be empty")
+		return nil, fmt.Errorf("%w: items cannot be empty", ErrInvalidOrder)
 	}
 
-	var totalAmount decimal.Decimal
-	var orderItems []models.OrderItem
+	products, stock, err := s.lookupItems(ctx, req.Items)
+	if err != nil {
+		return nil, err
+	}
 
-	// Validate Products and Check/Deduct Stock
+	// Validate every item up front so the caller sees all problems at once
+	requested := make(map[string]int)
 	for _, itemReq := range req.Items {
-		// 1. Get Product Details
-		productRes, err := s.grpcClients.ProductClient.GetProduct(ctx, &pb.GetProductRequest{Id: itemReq.ProductID})
-		if err != nil {
-			return nil, fmt.Errorf("failed to get product %s: %v", itemReq.ProductID, err)
+		requested[itemReq.ProductID] += itemReq.Quantity
+	}
+	var itemErrs []error
+	checked := make(map[string]bool)
+	for _, itemReq := range req.Items {
+		id := itemReq.ProductID
+		if checked[id] {
+			continue
 		}
+		checked[id] = true
 
-		// Validate Price
-		price := decimal.NewFromFloat(productRes.Price)
-
-		// 2. Check Stock
-		stockRes, err := s.grpcClients.InventoryClient.GetStock(ctx, &invPb.GetStockRequest{ProductId: itemReq.ProductID})
-		if err != nil {
-			return nil, fmt.Errorf("failed to check stock for %s: %v", itemReq.ProductID, err)
-		}
-		if stockRes.Quantity < int32(itemReq.Quantity) {
-			return nil, fmt.Errorf("insufficient stock for product %s", itemReq.ProductID)
-		}
-
-		// 3. Deduct Stock
-		_, err = s.grpcClients.InventoryClient.UpdateStock(ctx, &invPb.UpdateStockRequest{
-			ProductId:      itemReq.ProductID,
-			QuantityChange: -int32(itemReq.Quantity),
-		})
-		if err != nil {
-			// In a real system, we'd need to rollback previous deductions here!
-			return nil, fmt.Errorf("failed to deduct stock for %s: %v", itemReq.ProductID, err)
+		product, ok := products[id]
+		switch {
+		case !ok:
+			itemErrs = append(itemErrs, fmt.Errorf("%w: product %s not found", ErrProductUnavailable, id))
+		case product.Status == "archived":
+			itemErrs = append(itemErrs, fmt.Errorf("%w: product %s is no longer available", ErrProductUnavailable, id))
+		case stock[id] < int32(requested[id]) && !oversellable(product):
+			itemErrs = append(itemErrs, fmt.Errorf("%w for product %s", ErrInsufficientStock, id))
 		}
+	}
+	if len(itemErrs) > 0 {
+		return nil, errors.Join(itemErrs...)
+	}
 
+	var totalAmount decimal.Decimal
+	var orderItems []models.OrderItem
+	items := make([]*invPb.AllocationItem, 0, len(req.Items))
+	for _, itemReq := range req.Items {
+		price := decimal.NewFromFloat(products[itemReq.ProductID].Price)
 		totalAmount = totalAmount.Add(price.Mul(decimal.NewFromInt(int64(itemReq.Quantity))))
 		orderItems = append(orderItems, models.OrderItem{
 			ProductID: itemReq.ProductID,
 			Quantity:  itemReq.Quantity,
 			Price:     price,
 		})
+		items = append(items, &invPb.AllocationItem{
+			ProductId: itemReq.ProductID,
+			Quantity:  int32(itemReq.Quantity),
+		})
 	}
 
 	// Use provided subtotal if available, otherwise use calculated amount
@@ -89,29 +107,54 @@ func (s *OrderServiceImpl) CreateOrder(ctx context.Context, req *models.CreateOr
 	// Calculate final total: subtotal + shipping - discount
 	finalTotal := subtotal + req.ShippingFee - req.Discount
 
-	// Save to DB
+	// Save the order first, checking its purchase limits, so the stock
+	// ledger can reference its id; then reserve stock outside any
+	// transaction and confirm the order
 	order := &models.Order{
 		UserID:      req.UserID,
 		Subtotal:    subtotal,
 		ShippingFee: req.ShippingFee,
 		Discount:    req.Discount,
 		TotalAmount: finalTotal,
-		Status:      "pending",
+		Status:      models.StatusReserving,
 		Items:       orderItems,
+
+		ShippingPostcode: req.ShippingPostcode,
+	}
+	err = s.repo.CreateOrderWith(ctx, order, func(purchased repository.PurchasedFunc) error {
+		return checkPurchaseLimits(products, requested, purchased)
+	})
+	if errors.Is(err, ErrPurchaseLimit) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order: %v", err)
 	}
 
-	err := s.repo.CreateOrder(ctx, order)
+	order.Allocations, order.Backorders, err = s.reserveStock(ctx, order, items, req.FulfillmentStrategy)
 	if err != nil {
+		// A rejected reservation took nothing; any other failure may have
+		// reserved stock after all
+		s.failOrder(ctx, order, !errors.Is(err, ErrInsufficientStock))
+		return nil, err
+	}
+	order.Status = models.StatusPending
+	if len(order.Backorders) > 0 {
+		order.Status = models.StatusBackordered
+	}
+	if err := s.repo.ConfirmOrder(ctx, order); err != nil {
+		s.failOrder(ctx, order, true)
 		return nil, fmt.Errorf("failed to create order: %v", err)
 	}
 
 	// Publish Event
 	event := map[string]interface{}{
-		"order_id": order.ID,
-		"user_id":  order.UserID,
-		"amount":   order.TotalAmount,
-		"status":   order.Status,
-		"items":    order.Items,
+		"order_id":   order.ID,
+		"user_id":    order.UserID,
+		"amount":     order.TotalAmount,
+		"status":     order.Status,
+		"items":      order.Items,
+		"created_at": order.CreatedAt,
 	}
 	if err := s.publisher.PublishOrderCreated(event); err != nil {
 		fmt.Printf("Failed to publish order created event: %v\n", err)
@@ -120,6 +163,242 @@ func (s *OrderServiceImpl) CreateOrder(ctx context.Context, req *models.CreateOr
 	return order, nil
 }
 
+// Calls to inventory are retried when they may not have reached it; the
+// request id (derived from the order id) stops a retry from deducting twice
+// if the first attempt did commit.
+const (
+	inventoryCallAttempts = 3
+	inventoryCallTimeout  = 5 * time.Second
+)
+
+// callInventory runs call, retrying it while inventory is unreachable.
+func callInventory[T any](ctx context.Context, call func(context.Context) (T, error)) (T, error) {
+	var res T
+	var err error
+	for attempt := 1; attempt <= inventoryCallAttempts; attempt++ {
+		callCtx, cancel := context.WithTimeout(ctx, inventoryCallTimeout)
+		res, err = call(callCtx)
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+		if code := status.Code(err); code != codes.Unavailable && code != codes.DeadlineExceeded {
+			break
+		}
+	}
+	return res, err
+}
+
+func reservationID(order *models.Order) string {
+	return fmt.Sprintf("order-%d", order.ID)
+}
+
+// reserveStock takes stock for every item in one all-or-nothing call,
+// letting inventory pick the locations it ships from, and returns where each
+// item was taken. What inventory backordered instead is returned separately.
+func (s *OrderServiceImpl) reserveStock(ctx context.Context, order *models.Order, items []*invPb.AllocationItem, strategy string) ([]models.OrderAllocation, []models.OrderBackorder, error) {
+	req := &invPb.ReserveStockRequest{
+		Items:       items,
+		Postcode:    order.ShippingPostcode,
+		Strategy:    strategy,
+		Reason:      "sale",
+		ReferenceId: strconv.FormatInt(order.ID, 10),
+		Actor:       order.UserID,
+		RequestId:   reservationID(order),
+
+		AllowBackorder: true,
+	}
+	reserveRes, err := callInventory(ctx, func(ctx context.Context) (*invPb.ReserveStockResponse, error) {
+		return s.grpcClients.InventoryClient.ReserveStock(ctx, req)
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to deduct stock: %w", err)
+	}
+	if !reserveRes.Success {
+		var shortErrs []error
+		for _, sf := range reserveRes.Shortfalls {
+			shortErrs = append(shortErrs, fmt.Errorf("%w for product %s", ErrInsufficientStock, sf.ProductId))
+		}
+		if len(shortErrs) == 0 {
+			shortErrs = append(shortErrs, fmt.Errorf("failed to deduct stock: %s", reserveRes.Message))
+		}
+		return nil, nil, errors.Join(shortErrs...)
+	}
+
+	allocations := make([]models.OrderAllocation, 0, len(reserveRes.Allocations))
+	for _, a := range reserveRes.Allocations {
+		allocations = append(allocations, models.OrderAllocation{
+			ProductID:    a.ProductId,
+			LocationCode: a.Location,
+			Quantity:     int(a.Quantity),
+		})
+	}
+	var backorders []models.OrderBackorder
+	for _, b := range reserveRes.Backorders {
+		backorders = append(backorders, models.OrderBackorder{
+			ProductID: b.ProductId,
+			Quantity:  int(b.Quantity),
+		})
+	}
+	return allocations, backorders, nil
+}
+
+// failOrder marks an order whose stock could not be reserved or saved as
+// failed. With release set, whatever inventory reserved for it is released
+// first; if that fails the order stays reserving, and holds its stock, until
+// it is released by hand.
+func (s *OrderServiceImpl) failOrder(ctx context.Context, order *models.Order, release bool) {
+	ctx = context.WithoutCancel(ctx)
+	if release {
+		productIDs := make([]string, 0, len(order.Items))
+		for _, item := range order.Items {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		req := &invPb.ReleaseStockRequest{
+			RequestId:   reservationID(order),
+			ReferenceId: strconv.FormatInt(order.ID, 10),
+			ProductIds:  productIDs,
+			Actor:       order.UserID,
+		}
+		if _, err := callInventory(ctx, func(ctx context.Context) (*invPb.ReleaseStockResponse, error) {
+			return s.grpcClients.InventoryClient.ReleaseStock(ctx, req)
+		}); err != nil {
+			slog.Error("Failed to release stock of failed order", "order_id", order.ID, "error", err)
+			return
+		}
+	}
+	if err := s.repo.UpdateStatus(ctx, order.ID, models.StatusFailed); err != nil {
+		slog.Error("Failed to mark order failed", "order_id", order.ID, "error", err)
+	}
+}
+
+// checkPurchaseLimits fails with a *PurchaseLimitError for each product the
+// user would buy more of than its purchase limit allows, counting the
+// user's earlier orders from purchased for limits per window.
+func checkPurchaseLimits(products map[string]*pb.ProductResponse, requested map[string]int, purchased repository.PurchasedFunc) error {
+	// Products limited per window, grouped by window so each needs one query
+	windows := make(map[int32][]string)
+	for id := range requested {
+		if l := products[id].PurchaseLimit; l != nil && l.MaxPerWindow > 0 && l.WindowDays > 0 {
+			windows[l.WindowDays] = append(windows[l.WindowDays], id)
+		}
+	}
+	bought := make(map[string]int)
+	for days, ids := range windows {
+		since := time.Now().AddDate(0, 0, -int(days))
+		quantities, err := purchased(ids, since)
+		if err != nil {
+			return fmt.Errorf("failed to check purchase history: %w", err)
+		}
+		for id, qty := range quantities {
+			bought[id] = qty
+		}
+	}
+	return errors.Join(purchaseLimitErrors(products, requested, bought)...)
+}
+
+// purchaseLimitErrors returns a *PurchaseLimitError for each product
+// requested beyond its limits, given what the user bought in each
+// product's window.
+func purchaseLimitErrors(products map[string]*pb.ProductResponse, requested, bought map[string]int) []error {
+	var limitErrs []error
+	for _, id := range slices.Sorted(maps.Keys(requested)) {
+		qty := requested[id]
+		l := products[id].PurchaseLimit
+		if l == nil {
+			continue
+		}
+		// The tighter of the two limits decides what is left
+		var tightest *PurchaseLimitError
+		if l.MaxPerOrder > 0 {
+			tightest = &PurchaseLimitError{ProductID: id, Limit: int(l.MaxPerOrder), Remaining: int(l.MaxPerOrder)}
+		}
+		if l.MaxPerWindow > 0 && l.WindowDays > 0 {
+			left := max(int(l.MaxPerWindow)-bought[id], 0)
+			if tightest == nil || left < tightest.Remaining {
+				tightest = &PurchaseLimitError{ProductID: id, Limit: int(l.MaxPerWindow), WindowDays: int(l.WindowDays), Remaining: left}
+			}
+		}
+		if tightest != nil && qty > tightest.Remaining {
+			limitErrs = append(limitErrs, tightest)
+		}
+	}
+	return limitErrs
+}
+
+// oversellable reports whether inventory may take orders for the product
+// beyond its stock, up to the product's backorder limit.
+func oversellable(p *pb.ProductResponse) bool {
+	switch p.Availability {
+	case "backorder":
+		return true
+	case "preorder":
+		release, err := time.Parse(time.RFC3339, p.ReleaseDate)
+		return err == nil && time.Now().Before(release)
+	}
+	return false
+}
+
+// FillBackorder records that inventory filled one of the order's backorders,
+// moving the order on once nothing is backordered any more.
+func (s *OrderServiceImpl) FillBackorder(ctx context.Context, referenceID, productID string, quantity int, allocations []models.OrderAllocation) error {
+	orderID, err := strconv.ParseInt(referenceID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: reference %q is not an order id", ErrInvalidOrder, referenceID)
+	}
+	order, err := s.repo.FillBackorder(ctx, orderID, productID, quantity, allocations)
+	if err != nil {
+		return err
+	}
+	if order != nil {
+		slog.Info("Backorder filled", "order_id", orderID, "product_id", productID, "quantity", quantity)
+	}
+	return nil
+}
+
+// lookupItems fetches product details and stock levels for the order items
+// with one batch call to each service, run concurrently. Products that don't
+// exist are simply absent from the returned map.
+func (s *OrderServiceImpl) lookupItems(ctx context.Context, items []models.CreateOrderItem) (map[string]*pb.ProductResponse, map[string]int32, error) {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	var productsRes *pb.BatchGetProductsResponse
+	var stockRes *invPb.BatchGetStockResponse
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		productsRes, err = s.grpcClients.ProductClient.BatchGetProducts(gctx, &pb.BatchGetProductsRequest{Ids: ids})
+		if err != nil {
+			return fmt.Errorf("failed to get products: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		var err error
+		stockRes, err = s.grpcClients.InventoryClient.BatchGetStock(gctx, &invPb.BatchGetStockRequest{ProductIds: ids})
+		if err != nil {
+			return fmt.Errorf("failed to check stock: %w", err)
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	products := make(map[string]*pb.ProductResponse, len(productsRes.Products))
+	for _, p := range productsRes.Products {
+		products[p.Id] = p
+	}
+	stock := make(map[string]int32, len(stockRes.Stocks))
+	for _, level := range stockRes.Stocks {
+		stock[level.ProductId] = level.Quantity
+	}
+	return products, stock, nil
+}
+
 func (s *OrderServiceImpl) GetOrders(ctx context.Context, id string) (*models.Order, error) {
 	return s.repo.GetOrders(ctx, id)
 }
@@ -127,3 +406,12 @@ func (s *OrderServiceImpl) GetOrders(ctx context.Context, id string) (*models.Or
 func (s *OrderServiceImpl) ListOrders(ctx context.Context) ([]*models.Order, error) {
 	return s.repo.ListOrders(ctx)
 }
+
+// ReferencedProducts reports which products appear on any order. The product
+// service uses it to avoid purging products that order history points to.
+func (s *OrderServiceImpl) ReferencedProducts(ctx context.Context, productIDs []string) ([]string, error) {
+	if len(productIDs) == 0 {
+		return []string{}, nil
+	}
+	return s.repo.ReferencedProductIDs(ctx, productIDs)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	pb "github.com/thapakon-thai/eshop-microservices/proto/product"
+)
+
+func TestPurchaseLimitErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     *pb.PurchaseLimit
+		requested int
+		bought    int
+		want      *PurchaseLimitError
+	}{
+		{name: "no limit", requested: 100},
+		{name: "within per order", limit: &pb.PurchaseLimit{MaxPerOrder: 3}, requested: 3},
+		{
+			name:      "over per order",
+			limit:     &pb.PurchaseLimit{MaxPerOrder: 3},
+			requested: 4,
+			want:      &PurchaseLimitError{ProductID: "p1", Limit: 3, Remaining: 3},
+		},
+		{name: "within window", limit: &pb.PurchaseLimit{MaxPerWindow: 5, WindowDays: 7}, requested: 2, bought: 3},
+		{
+			name:      "over window",
+			limit:     &pb.PurchaseLimit{MaxPerWindow: 5, WindowDays: 7},
+			requested: 3,
+			bought:    3,
+			want:      &PurchaseLimitError{ProductID: "p1", Limit: 5, WindowDays: 7, Remaining: 2},
+		},
+		{
+			name:      "window used up beyond its limit",
+			limit:     &pb.PurchaseLimit{MaxPerWindow: 5, WindowDays: 7},
+			requested: 1,
+			bought:    6,
+			want:      &PurchaseLimitError{ProductID: "p1", Limit: 5, WindowDays: 7, Remaining: 0},
+		},
+		{name: "window without days is ignored", limit: &pb.PurchaseLimit{MaxPerWindow: 1}, requested: 5, bought: 5},
+		{
+			name:      "per order is tighter",
+			limit:     &pb.PurchaseLimit{MaxPerOrder: 2, MaxPerWindow: 10, WindowDays: 30},
+			requested: 3,
+			bought:    1,
+			want:      &PurchaseLimitError{ProductID: "p1", Limit: 2, Remaining: 2},
+		},
+		{
+			name:      "window is tighter",
+			limit:     &pb.PurchaseLimit{MaxPerOrder: 5, MaxPerWindow: 10, WindowDays: 30},
+			requested: 3,
+			bought:    8,
+			want:      &PurchaseLimitError{ProductID: "p1", Limit: 10, WindowDays: 30, Remaining: 2},
+		},
+		{
+			name:      "equal limits report per order",
+			limit:     &pb.PurchaseLimit{MaxPerOrder: 2, MaxPerWindow: 4, WindowDays: 30},
+			requested: 3,
+			bought:    2,
+			want:      &PurchaseLimitError{ProductID: "p1", Limit: 2, Remaining: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := map[string]*pb.ProductResponse{"p1": {Id: "p1", PurchaseLimit: tt.limit}}
+			errs := purchaseLimitErrors(products, map[string]int{"p1": tt.requested}, map[string]int{"p1": tt.bought})
+			if tt.want == nil {
+				if len(errs) != 0 {
+					t.Fatalf("errs = %v, want none", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("errs = %v, want one", errs)
+			}
+			var got *PurchaseLimitError
+			if !errors.As(errs[0], &got) || *got != *tt.want {
+				t.Fatalf("err = %v, want %+v", errs[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPurchaseLimitsQueriesEachWindowOnce(t *testing.T) {
+	products := map[string]*pb.ProductResponse{
+		"a": {Id: "a", PurchaseLimit: &pb.PurchaseLimit{MaxPerWindow: 2, WindowDays: 7}},
+		"b": {Id: "b", PurchaseLimit: &pb.PurchaseLimit{MaxPerWindow: 2, WindowDays: 7}},
+		"c": {Id: "c", PurchaseLimit: &pb.PurchaseLimit{MaxPerWindow: 9, WindowDays: 30}},
+		"d": {Id: "d", PurchaseLimit: &pb.PurchaseLimit{MaxPerOrder: 1}},
+	}
+	requested := map[string]int{"a": 1, "b": 1, "c": 1, "d": 1}
+	queried := make(map[int][]string)
+	purchased := func(ids []string, since time.Time) (map[string]int, error) {
+		days := int(time.Since(since).Hours()/24 + 0.5)
+		queried[days] = append(queried[days], ids...)
+		return map[string]int{"b": 2}, nil
+	}
+
+	err := checkPurchaseLimits(products, requested, purchased)
+	var limitErr *PurchaseLimitError
+	if !errors.As(err, &limitErr) || limitErr.ProductID != "b" || limitErr.Remaining != 0 {
+		t.Fatalf("err = %v, want b over its window", err)
+	}
+	slices.Sort(queried[7])
+	if len(queried) != 2 || !slices.Equal(queried[7], []string{"a", "b"}) || !slices.Equal(queried[30], []string{"c"}) {
+		t.Fatalf("queried = %v, want a and b over 7 days and c over 30", queried)
+	}
+
+	failing := func([]string, time.Time) (map[string]int, error) { return nil, errors.New("down") }
+	if err := checkPurchaseLimits(products, requested, failing); err == nil || errors.Is(err, ErrPurchaseLimit) {
+		t.Fatalf("err = %v, want the lookup failure", err)
+	}
+}
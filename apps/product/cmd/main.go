@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/thapakon-thai/eshop-microservices/product/internal/config"
 	"github.com/thapakon-thai/eshop-microservices/product/internal/handler"
+	"github.com/thapakon-thai/eshop-microservices/product/internal/infrastructure"
 	"github.com/thapakon-thai/eshop-microservices/product/internal/infrastructure/db"
 	"github.com/thapakon-thai/eshop-microservices/product/internal/repository"
 	"github.com/thapakon-thai/eshop-microservices/product/internal/service"
+	"github.com/thapakon-thai/eshop-microservices/product/internal/storage"
 	pb "github.com/thapakon-thai/eshop-microservices/proto/product"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -38,10 +41,57 @@ func main() {
 	}()
 	database := mongoClient.Database(cfg.DBName)
 
+	// Messaging
+	publisher, err := infrastructure.NewEventPublisher(cfg.RabbitMQURL)
+	if err != nil {
+		slog.Error("Failed to initialize event publisher", "error", err)
+		os.Exit(1)
+	}
+	defer publisher.Close()
+
+	stockClient, err := infrastructure.NewStockClient(cfg.InventoryServiceURL, cfg.StockCacheTTL)
+	if err != nil {
+		slog.Error("Failed to connect to inventory service", "error", err)
+		os.Exit(1)
+	}
+
 	// Layers (Dependency Injection)
 	repo := repository.NewMongoRepository(database)
-	svc := service.NewProductService(repo)
+	categoryRepo := repository.NewMongoCategoryRepository(database)
+	for _, r := range []interface{ EnsureIndexes(context.Context) error }{repo, categoryRepo} {
+		if err := r.EnsureIndexes(context.Background()); err != nil {
+			slog.Error("Failed to create MongoDB indexes", "error", err)
+			os.Exit(1)
+		}
+	}
+	svc := service.NewProductService(repo, categoryRepo, publisher, stockClient, infrastructure.NewOrderClient(cfg.OrderServiceURL))
 	grpcHandler := handler.NewProductGrpcHandler(svc)
+	categorySvc := service.NewCategoryService(categoryRepo, repo)
+	categoryHandler := handler.NewCategoryGrpcHandler(categorySvc)
+
+	// Image storage
+	store, err := storage.NewObjectStore(context.Background(), cfg.Images)
+	if err != nil {
+		slog.Error("Failed to initialize image storage", "error", err)
+		os.Exit(1)
+	}
+	imageHandler := handler.NewImageGrpcHandler(service.NewImageService(store))
+
+	// The local backend has no server of its own, so expose the directory
+	// over HTTP for the gateway to proxy /images to.
+	var imageServer *http.Server
+	if local, ok := store.(*storage.LocalStore); ok {
+		mux := http.NewServeMux()
+		mux.Handle("/images/", http.StripPrefix("/images/", http.FileServer(http.Dir(local.Dir()))))
+		imageServer = &http.Server{Addr: ":" + cfg.Images.HTTPPort, Handler: mux}
+		go func() {
+			slog.Info("Starting image file server", "port", cfg.Images.HTTPPort)
+			if err := imageServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("Failed to serve images", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
 
 	// GRPC Server
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.ServicePort))
@@ -52,6 +102,8 @@ func main() {
 
 	s := grpc.NewServer()
 	pb.RegisterProductServiceServer(s, grpcHandler)
+	pb.RegisterCategoryServiceServer(s, categoryHandler)
+	pb.RegisterImageServiceServer(s, imageHandler)
 	reflection.Register(s) // for debugging
 
 	go func() {
@@ -63,12 +115,23 @@ func main() {
 		}
 	}()
 
+	// Background jobs
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	go svc.RunRetention(jobCtx, cfg.PurgeInterval, cfg.ProductRetention)
+	go svc.RunOutboxRelay(jobCtx, cfg.OutboxRelayInterval)
+
 	// Wait for interrupt
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	slog.Info("Shutting down server...")
+	stopJobs()
 	s.GracefulStop()
+	if imageServer != nil {
+		if err := imageServer.Shutdown(context.Background()); err != nil {
+			slog.Error("Error shutting down image file server", "error", err)
+		}
+	}
 	slog.Info("Server exited")
 }
@@ -0,0 +1,130 @@
+// Command migratestock moves the stock values still stored on Mongo product
+// documents into the inventory service, which owns stock, and removes the
+// field from the documents.
+//
+// Products that inventory already tracks keep their inventory quantity
+// (that's the number checkout has been enforcing); only untracked products
+// are seeded from Mongo. Run it once after deploying the inventory-backed
+// product service:
+//
+//	go run ./cmd/migratestock -dry-run
+//	go run ./cmd/migratestock
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/thapakon-thai/eshop-microservices/product/internal/config"
+	"github.com/thapakon-thai/eshop-microservices/product/internal/infrastructure/db"
+	invPb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const batchSize = 100
+
+type legacyStock struct {
+	ID    primitive.ObjectID `bson:"_id"`
+	Stock int32              `bson:"stock"`
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	cfg := config.LoadConfig()
+	ctx := context.Background()
+
+	mongoClient, err := db.NewMongoDB(cfg.MongoDBURI)
+	if err != nil {
+		slog.Error("Failed to connect to MongoDB", "error", err)
+		os.Exit(1)
+	}
+	defer mongoClient.Disconnect(ctx)
+	products := mongoClient.Database(cfg.DBName).Collection("products")
+
+	conn, err := grpc.Dial(cfg.InventoryServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		slog.Error("Failed to connect to inventory service", "error", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+	inventory := invPb.NewInventoryServiceClient(conn)
+
+	// Load everything up front: the loop unsets the field being filtered on.
+	cursor, err := products.Find(ctx, bson.M{"stock": bson.M{"$exists": true}})
+	if err != nil {
+		slog.Error("Failed to query products", "error", err)
+		os.Exit(1)
+	}
+	var legacy []legacyStock
+	if err := cursor.All(ctx, &legacy); err != nil {
+		slog.Error("Failed to read products", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("Products with stock stored in Mongo", "count", len(legacy), "dry_run", *dryRun)
+
+	var seeded, kept, failed int
+	for start := 0; start < len(legacy); start += batchSize {
+		batch := legacy[start:min(start+batchSize, len(legacy))]
+		ids := make([]string, len(batch))
+		for i, p := range batch {
+			ids[i] = p.ID.Hex()
+		}
+
+		res, err := inventory.BatchGetStock(ctx, &invPb.BatchGetStockRequest{ProductIds: ids})
+		if err != nil {
+			slog.Error("Failed to read inventory", "error", err)
+			os.Exit(1)
+		}
+
+		for i, p := range batch {
+			level := res.Stocks[i]
+			switch {
+			case level.Tracked:
+				if level.Quantity != p.Stock {
+					slog.Info("Inventory already tracks product, keeping its quantity",
+						"product_id", ids[i], "mongo", p.Stock, "inventory", level.Quantity)
+				}
+				kept++
+			case p.Stock > 0:
+				slog.Info("Seeding inventory", "product_id", ids[i], "quantity", p.Stock)
+				if !*dryRun {
+					upd, err := inventory.UpdateStock(ctx, &invPb.UpdateStockRequest{ProductId: ids[i], QuantityChange: p.Stock})
+					if err == nil && !upd.Success {
+						err = errors.New(upd.Message)
+					}
+					if err != nil {
+						slog.Error("Failed to seed inventory", "product_id", ids[i], "error", err)
+						failed++
+						continue
+					}
+				}
+				seeded++
+			default:
+				kept++
+			}
+
+			if !*dryRun {
+				if _, err := products.UpdateByID(ctx, p.ID, bson.M{"$unset": bson.M{"stock": ""}}); err != nil {
+					slog.Error("Failed to unset stock", "product_id", ids[i], "error", err)
+					failed++
+				}
+			}
+		}
+	}
+
+	slog.Info("Stock migration finished", "seeded", seeded, "kept", kept, "failed", failed, "dry_run", *dryRun)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/product/internal/service"
+	pb "github.com/thapakon-thai/eshop-microservices/proto/product"
+)
+
+type CategoryGrpcHandler struct {
+	pb.UnimplementedCategoryServiceServer
+	svc *service.CategoryService
+}
+
+func NewCategoryGrpcHandler(svc *service.CategoryService) *CategoryGrpcHandler {
+	return &CategoryGrpcHandler{svc: svc}
+}
+
+func (h *CategoryGrpcHandler) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.CategoryResponse, error) {
+	category := &models.Category{
+		Name:      req.Name,
+		Slug:      req.Slug,
+		ParentID:  req.ParentId,
+		SortOrder: req.SortOrder,
+	}
+	if err := h.svc.CreateCategory(ctx, category); err != nil {
+		return nil, toStatus(err)
+	}
+	return toCategoryResponse(category), nil
+}
+
+func (h *CategoryGrpcHandler) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.CategoryResponse, error) {
+	category, err := h.svc.GetCategory(ctx, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toCategoryResponse(category), nil
+}
+
+func (h *CategoryGrpcHandler) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.CategoryResponse, error) {
+	category, err := h.svc.UpdateCategory(ctx, req.Id, req.Name, req.Slug, req.SortOrder)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toCategoryResponse(category), nil
+}
+
+func (h *CategoryGrpcHandler) MoveCategory(ctx context.Context, req *pb.MoveCategoryRequest) (*pb.CategoryResponse, error) {
+	category, err := h.svc.MoveCategory(ctx, req.Id, req.ParentId, req.SortOrder)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toCategoryResponse(category), nil
+}
+
+func (h *CategoryGrpcHandler) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error) {
+	if err := h.svc.DeleteCategory(ctx, req.Id); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeleteCategoryResponse{Success: true}, nil
+}
+
+func (h *CategoryGrpcHandler) GetCategoryTree(ctx context.Context, req *pb.GetCategoryTreeRequest) (*pb.CategoryTreeResponse, error) {
+	roots, err := h.svc.GetCategoryTree(ctx, req.RootId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CategoryTreeResponse{Roots: toCategoryNodes(roots)}, nil
+}
+
+func toCategoryResponse(c *models.Category) *pb.CategoryResponse {
+	return &pb.CategoryResponse{
+		Id:          c.ID.Hex(),
+		Name:        c.Name,
+		Slug:        c.Slug,
+		ParentId:    c.ParentID,
+		SortOrder:   c.SortOrder,
+		AncestorIds: c.Ancestors,
+	}
+}
+
+func toCategoryNodes(nodes []*models.CategoryNode) []*pb.CategoryNode {
+	var out []*pb.CategoryNode
+	for _, n := range nodes {
+		out = append(out, &pb.CategoryNode{
+			Category: toCategoryResponse(n.Category),
+			Children: toCategoryNodes(n.Children),
+		})
+	}
+	return out
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/thapakon-thai/eshop-microservices/proto/grpcerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const errorDomain = "product.eshop"
+
+// toStatus converts a service or repository error into a gRPC status error
+// carrying a google.rpc.ErrorInfo detail. Errors that are already statuses
+// pass through unchanged.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var svcErr *grpcerr.Error
+	switch {
+	case errors.As(err, &svcErr):
+		return newStatus(grpcerr.Code(svcErr.Kind), svcErr.Reason, svcErr.Message)
+	case errors.Is(err, primitive.ErrInvalidHex):
+		return newStatus(codes.InvalidArgument, "INVALID_ID", "id is not a valid ObjectID")
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return newStatus(codes.NotFound, "NOT_FOUND", "not found")
+	case errors.Is(err, context.DeadlineExceeded):
+		return newStatus(codes.DeadlineExceeded, "DEADLINE_EXCEEDED", err.Error())
+	case errors.Is(err, context.Canceled):
+		return newStatus(codes.Canceled, "CANCELED", err.Error())
+	case mongo.IsNetworkError(err) || mongo.IsTimeout(err):
+		slog.Error("Database unavailable", "error", err)
+		return newStatus(codes.Unavailable, "DATABASE_UNAVAILABLE", "database unavailable")
+	}
+	slog.Error("Internal error", "error", err)
+	return newStatus(codes.Internal, "INTERNAL", "internal error")
+}
+
+func newStatus(code codes.Code, reason, msg string) error {
+	return grpcerr.Status(errorDomain, code, reason, msg)
+}
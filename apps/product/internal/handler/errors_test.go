@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/thapakon-thai/eshop-microservices/product/internal/service"
+	"github.com/thapakon-thai/eshop-microservices/proto/grpcerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{"invalid argument", grpcerr.New(service.ErrInvalidArgument, "NAME_REQUIRED", "name is required"), codes.InvalidArgument, "NAME_REQUIRED"},
+		{"not found", grpcerr.New(service.ErrNotFound, "CATEGORY_NOT_FOUND", "category %s not found", "c1"), codes.NotFound, "CATEGORY_NOT_FOUND"},
+		{"already exists", grpcerr.New(service.ErrAlreadyExists, "SKU_EXISTS", "sku taken"), codes.AlreadyExists, "SKU_EXISTS"},
+		{"failed precondition", grpcerr.New(service.ErrFailedPrecondition, "CATEGORY_IN_USE", "in use"), codes.FailedPrecondition, "CATEGORY_IN_USE"},
+		{"invalid id", fmt.Errorf("find: %w", primitive.ErrInvalidHex), codes.InvalidArgument, "INVALID_ID"},
+		{"no documents", mongo.ErrNoDocuments, codes.NotFound, "NOT_FOUND"},
+		{"deadline", context.DeadlineExceeded, codes.DeadlineExceeded, "DEADLINE_EXCEEDED"},
+		{"canceled", context.Canceled, codes.Canceled, "CANCELED"},
+		{"unknown", errors.New("boom"), codes.Internal, "INTERNAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(toStatus(tt.err))
+			if !ok || st.Code() != tt.code {
+				t.Fatalf("code = %v, want %v", st.Code(), tt.code)
+			}
+			details := st.Details()
+			if len(details) != 1 {
+				t.Fatalf("details = %v, want one ErrorInfo", details)
+			}
+			info, ok := details[0].(*errdetails.ErrorInfo)
+			if !ok || info.Reason != tt.reason || info.Domain != errorDomain {
+				t.Fatalf("detail = %v, want %s in %s", details[0], tt.reason, errorDomain)
+			}
+		})
+	}
+}
@@ -2,10 +2,13 @@ package handler
 
 import (
 	"context"
+	"time"
 
 	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
 	"github.com/thapakon-thai/eshop-microservices/product/internal/service"
 	pb "github.com/thapakon-thai/eshop-microservices/proto/product"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
 )
 
 type ProductGrpcHandler struct {
@@ -20,80 +23,213 @@ func NewProductGrpcHandler(svc *service.ProductService) *ProductGrpcHandler {
 func (h *ProductGrpcHandler) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.ProductResponse, error) {
 	product, err := h.svc.GetProduct(ctx, req.Id)
 	if err != nil {
-		return nil, err
+		return nil, toStatus(err)
 	}
-	return &pb.ProductResponse{
-		Id:          product.ID.Hex(),
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		CategoryId:  product.CategoryID,
-		Sizes:       product.Sizes,
-		Colors:      product.Colors,
-		Images:      product.Images,
-	}, nil
+	return toProductResponse(product), nil
+}
+
+func (h *ProductGrpcHandler) BatchGetProducts(ctx context.Context, req *pb.BatchGetProductsRequest) (*pb.BatchGetProductsResponse, error) {
+	products, missing, err := h.svc.BatchGetProducts(ctx, req.Ids)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := &pb.BatchGetProductsResponse{MissingIds: missing}
+	for _, p := range products {
+		res.Products = append(res.Products, toProductResponse(p))
+	}
+	return res, nil
 }
 
 func (h *ProductGrpcHandler) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
-	products, total, err := h.svc.ListProducts(ctx, req.Page, req.Limit, req.CategoryId)
+	page, err := h.svc.ListProducts(ctx, models.ProductListQuery{
+		Page:            req.Page,
+		Limit:           req.Limit,
+		PageToken:       req.PageToken,
+		CategoryID:      req.CategoryId,
+		IncludeArchived: req.IncludeArchived,
+		IncludeTotal:    req.IncludeTotal,
+	})
 	if err != nil {
-		return nil, err
+		return nil, toStatus(err)
 	}
 
 	var pbProducts []*pb.ProductResponse
-	for _, p := range products {
-		pbProducts = append(pbProducts, &pb.ProductResponse{
-			Id:          p.ID.Hex(),
-			Name:        p.Name,
-			Description: p.Description,
-			Price:       p.Price,
-			Stock:       p.Stock,
-			CategoryId:  p.CategoryID,
-			Sizes:       p.Sizes,
-			Colors:      p.Colors,
-			Images:      p.Images,
-		})
-	}
-
-	return &pb.ListProductsResponse{
-		Products:   pbProducts,
-		TotalCount: int32(total),
-	}, nil
+	for _, p := range page.Products {
+		pbProducts = append(pbProducts, toProductResponse(p))
+	}
+
+	res := &pb.ListProductsResponse{
+		Products:      pbProducts,
+		NextPageToken: page.NextPageToken,
+	}
+	if page.Total != nil {
+		total := int32(*page.Total)
+		res.TotalCount = &total
+	}
+	return res, nil
 }
 
 func (h *ProductGrpcHandler) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.ProductResponse, error) {
+	var err error
 	product := &models.Product{
 		Name:        req.Name,
 		Description: req.Description,
 		Price:       req.Price,
+		CostPrice:   req.CostPrice,
 		Stock:       req.Stock,
 		CategoryID:  req.CategoryId,
 		Sizes:       req.Sizes,
 		Colors:      req.Colors,
 		Images:      req.Images,
+
+		Availability:   req.Availability,
+		BackorderLimit: req.BackorderLimit,
+		PurchaseLimit:  fromProtoPurchaseLimit(req.PurchaseLimit),
+	}
+	if product.ReleaseDate, err = parseReleaseDate(req.ReleaseDate); err != nil {
+		return nil, err
 	}
 
 	if err := h.svc.CreateProduct(ctx, product); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProductResponse(product), nil
+}
+
+func (h *ProductGrpcHandler) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.ProductResponse, error) {
+	id, err := primitive.ObjectIDFromHex(req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	product := &models.Product{
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		CostPrice:   req.CostPrice,
+		CategoryID:  req.CategoryId,
+		Sizes:       req.Sizes,
+		Colors:      req.Colors,
+		Images:      req.Images,
+
+		Availability:   req.Availability,
+		BackorderLimit: req.BackorderLimit,
+		PurchaseLimit:  fromProtoPurchaseLimit(req.PurchaseLimit),
+	}
+	if product.ReleaseDate, err = parseReleaseDate(req.ReleaseDate); err != nil {
 		return nil, err
 	}
 
-	return &pb.ProductResponse{
-		Id:          product.ID.Hex(),
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		CategoryId:  product.CategoryID,
-		Sizes:       product.Sizes,
-		Colors:      product.Colors,
-		Images:      product.Images,
-	}, nil
+	updated, err := h.svc.UpdateProduct(ctx, product, req.UpdateFields)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProductResponse(updated), nil
 }
 
 func (h *ProductGrpcHandler) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
 	if err := h.svc.DeleteProduct(ctx, req.Id); err != nil {
-		return nil, err
+		return nil, toStatus(err)
 	}
 	return &pb.DeleteProductResponse{Success: true}, nil
 }
+
+func (h *ProductGrpcHandler) RestoreProduct(ctx context.Context, req *pb.RestoreProductRequest) (*pb.ProductResponse, error) {
+	product, err := h.svc.RestoreProduct(ctx, req.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toProductResponse(product), nil
+}
+
+func (h *ProductGrpcHandler) SearchProducts(ctx context.Context, req *pb.SearchProductsRequest) (*pb.SearchProductsResponse, error) {
+	products, total, facets, err := h.svc.SearchProducts(ctx, models.ProductSearch{
+		Query:      req.Query,
+		CategoryID: req.CategoryId,
+		MinPrice:   req.MinPrice,
+		MaxPrice:   req.MaxPrice,
+		Sizes:      req.Sizes,
+		Colors:     req.Colors,
+		Sort:       req.Sort,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	var pbProducts []*pb.ProductResponse
+	for _, p := range products {
+		pbProducts = append(pbProducts, toProductResponse(p))
+	}
+
+	return &pb.SearchProductsResponse{
+		Products:   pbProducts,
+		TotalCount: int32(total),
+		Facets: &pb.SearchFacets{
+			Categories: toFacetCounts(facets.Categories),
+			Sizes:      toFacetCounts(facets.Sizes),
+			Colors:     toFacetCounts(facets.Colors),
+		},
+	}, nil
+}
+
+func parseReleaseDate(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, newStatus(codes.InvalidArgument, "INVALID_RELEASE_DATE", "release_date must be an RFC 3339 timestamp")
+	}
+	return &t, nil
+}
+
+func fromProtoPurchaseLimit(l *pb.PurchaseLimit) *models.PurchaseLimit {
+	if l == nil {
+		return nil
+	}
+	return &models.PurchaseLimit{MaxPerOrder: l.MaxPerOrder, MaxPerWindow: l.MaxPerWindow, WindowDays: l.WindowDays}
+}
+
+func toProductResponse(p *models.Product) *pb.ProductResponse {
+	res := &pb.ProductResponse{
+		Id:          p.ID.Hex(),
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		CostPrice:   p.CostPrice,
+		Stock:       p.Stock,
+		CategoryId:  p.CategoryID,
+		Sizes:       p.Sizes,
+		Colors:      p.Colors,
+		Images:      p.Images,
+		Status:      models.StatusActive,
+
+		Availability:   p.Availability,
+		BackorderLimit: p.BackorderLimit,
+	}
+	if l := p.PurchaseLimit; l != nil {
+		res.PurchaseLimit = &pb.PurchaseLimit{MaxPerOrder: l.MaxPerOrder, MaxPerWindow: l.MaxPerWindow, WindowDays: l.WindowDays}
+	}
+	if p.ReleaseDate != nil {
+		res.ReleaseDate = p.ReleaseDate.Format(time.RFC3339)
+	}
+	if p.IsArchived() {
+		res.Status = models.StatusArchived
+	}
+	if p.DeletedAt != nil {
+		res.DeletedAt = p.DeletedAt.Format(time.RFC3339)
+	}
+	return res
+}
+
+func toFacetCounts(counts []models.FacetCount) []*pb.FacetCount {
+	var out []*pb.FacetCount
+	for _, c := range counts {
+		out = append(out, &pb.FacetCount{Value: c.Value, Count: c.Count})
+	}
+	return out
+}
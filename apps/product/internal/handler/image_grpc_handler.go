@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/thapakon-thai/eshop-microservices/product/internal/service"
+	pb "github.com/thapakon-thai/eshop-microservices/proto/product"
+	"google.golang.org/grpc/codes"
+)
+
+type ImageGrpcHandler struct {
+	pb.UnimplementedImageServiceServer
+	svc *service.ImageService
+}
+
+func NewImageGrpcHandler(svc *service.ImageService) *ImageGrpcHandler {
+	return &ImageGrpcHandler{svc: svc}
+}
+
+func (h *ImageGrpcHandler) UploadImage(stream pb.ImageService_UploadImageServer) error {
+	var buf bytes.Buffer
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return toStatus(err)
+		}
+		if buf.Len()+len(req.Chunk) > service.MaxImageBytes {
+			return newStatus(codes.InvalidArgument, "IMAGE_TOO_LARGE", fmt.Sprintf("image exceeds %d bytes", service.MaxImageBytes))
+		}
+		buf.Write(req.Chunk)
+	}
+
+	img, err := h.svc.UploadImage(stream.Context(), buf.Bytes())
+	if err != nil {
+		return toStatus(err)
+	}
+	return stream.SendAndClose(&pb.UploadImageResponse{Key: img.Key, Urls: img.URLs})
+}
+
+func (h *ImageGrpcHandler) DeleteImage(ctx context.Context, req *pb.DeleteImageRequest) (*pb.DeleteImageResponse, error) {
+	if err := h.svc.DeleteImage(ctx, req.Key); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeleteImageResponse{Success: true}, nil
+}
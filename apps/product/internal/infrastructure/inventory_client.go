@@ -0,0 +1,81 @@
+package infrastructure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	invPb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type cachedStock struct {
+	quantity  int32
+	expiresAt time.Time
+}
+
+// StockClient reads availability from the inventory service, which owns
+// stock. Quantities are cached for a short TTL so listing pages don't cost
+// an inventory round trip per request.
+type StockClient struct {
+	client invPb.InventoryServiceClient
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedStock
+}
+
+func NewStockClient(inventoryUrl string, ttl time.Duration) (*StockClient, error) {
+	conn, err := grpc.Dial(inventoryUrl, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return &StockClient{
+		client: invPb.NewInventoryServiceClient(conn),
+		ttl:    ttl,
+		cache:  make(map[string]cachedStock),
+	}, nil
+}
+
+// Stock returns the quantity for every id in productIDs. Misses are fetched
+// in a single BatchGetStock call; products inventory doesn't know are 0. If
+// that call fails, the cached quantities are still returned with the error.
+func (c *StockClient) Stock(ctx context.Context, productIDs []string) (map[string]int32, error) {
+	result := make(map[string]int32, len(productIDs))
+	var missing []string
+
+	now := time.Now()
+	c.mu.Lock()
+	for _, id := range productIDs {
+		if s, ok := c.cache[id]; ok && now.Before(s.expiresAt) {
+			result[id] = s.quantity
+			continue
+		}
+		missing = append(missing, id)
+	}
+	c.mu.Unlock()
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	res, err := c.client.BatchGetStock(ctx, &invPb.BatchGetStockRequest{ProductIds: missing})
+	if err != nil {
+		return result, err
+	}
+
+	expiresAt := time.Now().Add(c.ttl)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, s := range c.cache {
+		if now.After(s.expiresAt) {
+			delete(c.cache, k)
+		}
+	}
+	for _, s := range res.Stocks {
+		result[s.ProductId] = s.Quantity
+		c.cache[s.ProductId] = cachedStock{quantity: s.Quantity, expiresAt: expiresAt}
+	}
+	return result, nil
+}
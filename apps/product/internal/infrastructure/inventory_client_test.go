@@ -0,0 +1,45 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	invPb "github.com/thapakon-thai/eshop-microservices/proto/inventory"
+	"google.golang.org/grpc"
+)
+
+type flakyInventory struct {
+	invPb.InventoryServiceClient
+	down bool
+}
+
+func (f *flakyInventory) BatchGetStock(_ context.Context, req *invPb.BatchGetStockRequest, _ ...grpc.CallOption) (*invPb.BatchGetStockResponse, error) {
+	if f.down {
+		return nil, errors.New("unavailable")
+	}
+	res := &invPb.BatchGetStockResponse{}
+	for _, id := range req.ProductIds {
+		res.Stocks = append(res.Stocks, &invPb.StockLevel{ProductId: id, Quantity: 5, Tracked: true})
+	}
+	return res, nil
+}
+
+func TestStockKeepsCachedQuantitiesWhenInventoryFails(t *testing.T) {
+	inventory := &flakyInventory{}
+	c := &StockClient{client: inventory, ttl: time.Minute, cache: make(map[string]cachedStock)}
+	ctx := context.Background()
+
+	if _, err := c.Stock(ctx, []string{"a"}); err != nil {
+		t.Fatalf("warm cache: %v", err)
+	}
+	inventory.down = true
+	stock, err := c.Stock(ctx, []string{"a", "b"})
+	if err == nil {
+		t.Fatal("err = nil, want the BatchGetStock failure")
+	}
+	if len(stock) != 1 || stock["a"] != 5 {
+		t.Fatalf("stock = %v, want a at 5 from the cache", stock)
+	}
+}
@@ -0,0 +1,59 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OrderClient talks to the order service's internal HTTP API.
+type OrderClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func NewOrderClient(baseURL string) *OrderClient {
+	return &OrderClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ReferencedProducts returns which of productIDs appear on at least one order.
+func (c *OrderClient) ReferencedProducts(ctx context.Context, productIDs []string) (map[string]bool, error) {
+	body, err := json.Marshal(map[string][]string{"product_ids": productIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/orders/product-references", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("order service returned %s", res.Status)
+	}
+
+	var out struct {
+		ProductIDs []string `json:"product_ids"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	referenced := make(map[string]bool, len(out.ProductIDs))
+	for _, id := range out.ProductIDs {
+		referenced[id] = true
+	}
+	return referenced, nil
+}
@@ -0,0 +1,102 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
+)
+
+const productExchange = "product_events"
+
+type EventPublisher struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+func NewEventPublisher(url string) (*EventPublisher, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open a channel: %v", err)
+	}
+
+	err = ch.ExchangeDeclare(
+		productExchange, // name
+		"topic",         // type
+		true,            // durable
+		false,           // auto-deleted
+		false,           // internal
+		false,           // no-wait
+		nil,             // arguments
+	)
+	if err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare an exchange: %v", err)
+	}
+	// Events leave the outbox only once the broker has them
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %v", err)
+	}
+
+	return &EventPublisher{
+		conn:    conn,
+		channel: ch,
+	}, nil
+}
+
+// PublishProductEvent uses the event type as routing key, so consumers can
+// bind to "product.*" or a single event. It returns once the broker has
+// confirmed the event.
+func (p *EventPublisher) PublishProductEvent(ctx context.Context, event *models.ProductEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	confirm, err := p.channel.PublishWithDeferredConfirmWithContext(ctx,
+		productExchange, // exchange
+		event.Type,      // routing key
+		false,           // mandatory
+		false,           // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    event.ID,
+			Type:         event.Type,
+			Timestamp:    event.OccurredAt,
+			Body:         body,
+		})
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %v", err)
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to confirm message: %v", err)
+	}
+	if !acked {
+		return fmt.Errorf("broker refused message %s", event.ID)
+	}
+	slog.Info("Published product event", "type", event.Type, "product_id", event.Data.ProductID)
+	return nil
+}
+
+func (p *EventPublisher) Close() {
+	p.channel.Close()
+	p.conn.Close()
+}
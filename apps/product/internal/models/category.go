@@ -0,0 +1,19 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Category stores its full ancestor chain (root first) so subtree lookups are
+// a single indexed query on ancestors instead of a recursive walk.
+type Category struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Slug      string             `bson:"slug" json:"slug"`
+	ParentID  string             `bson:"parent_id" json:"parent_id"`
+	Ancestors []string           `bson:"ancestors" json:"ancestors"`
+	SortOrder int32              `bson:"sort_order" json:"sort_order"`
+}
+
+type CategoryNode struct {
+	Category *Category
+	Children []*CategoryNode
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	ProductCreated  = "product.created"
+	ProductUpdated  = "product.updated"
+	ProductDeleted  = "product.deleted"
+	ProductRestored = "product.restored"
+
+	// ProductEventVersion is bumped on breaking changes to ProductEventData so
+	// consumers can reject payloads they don't understand.
+	ProductEventVersion = 1
+)
+
+// ProductEvent is also stored in the outbox of the product it is about
+// until it has been published.
+type ProductEvent struct {
+	ID         string            `bson:"id" json:"id"`
+	Type       string            `bson:"type" json:"type"`
+	Version    int               `bson:"version" json:"version"`
+	OccurredAt time.Time         `bson:"occurred_at" json:"occurred_at"`
+	Data       *ProductEventData `bson:"data" json:"data"`
+}
+
+// ProductEventData is a snapshot of the product when the event happened.
+// Stock is only meaningful on product.created, where it is the initial
+// quantity the inventory service should start from. Availability,
+// ReleaseDate and BackorderLimit tell inventory how far it may oversell.
+type ProductEventData struct {
+	ProductID  string   `bson:"product_id" json:"product_id"`
+	Name       string   `bson:"name,omitempty" json:"name,omitempty"`
+	Price      float64  `bson:"price,omitempty" json:"price,omitempty"`
+	Stock      int32    `bson:"stock,omitempty" json:"stock,omitempty"`
+	CategoryID string   `bson:"category_id,omitempty" json:"category_id,omitempty"`
+	Sizes      []string `bson:"sizes,omitempty" json:"sizes,omitempty"`
+	Colors     []string `bson:"colors,omitempty" json:"colors,omitempty"`
+
+	Availability   string     `bson:"availability,omitempty" json:"availability,omitempty"`
+	ReleaseDate    *time.Time `bson:"release_date,omitempty" json:"release_date,omitempty"`
+	BackorderLimit int32      `bson:"backorder_limit,omitempty" json:"backorder_limit,omitempty"`
+}
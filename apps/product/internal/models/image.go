@@ -0,0 +1,9 @@
+package models
+
+// Image is one uploaded picture. Key is the storage prefix shared by all of
+// its variants; URLs maps variant name (original, large, thumbnail) to the
+// public URL that products should reference.
+type Image struct {
+	Key  string
+	URLs map[string]string
+}
@@ -1,15 +1,109 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	StatusActive   = "active"
+	StatusArchived = "archived"
+)
+
+// Availability says what happens to orders for more than is in stock.
+const (
+	AvailabilityInStock   = "in_stock"  // They are refused
+	AvailabilityBackorder = "backorder" // They wait for the next delivery
+	AvailabilityPreorder  = "preorder"  // Not released yet; they wait for the first deliveries
+)
 
 type Product struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Name        string             `bson:"name" json:"name"`
 	Description string             `bson:"description" json:"description"`
 	Price       float64            `bson:"price" json:"price"`
-	Stock       int32              `bson:"stock" json:"stock"`
+	CostPrice   float64            `bson:"cost_price" json:"cost_price"`
+	Stock       int32              `bson:"-" json:"stock"` // Owned by inventory; filled in on reads, never stored
 	CategoryID  string             `bson:"category_id" json:"category_id"`
 	Sizes       []string           `bson:"sizes" json:"sizes"`
 	Colors      []string           `bson:"colors" json:"colors"`
 	Images      map[string]string  `bson:"images" json:"images"`
+	Status      string             `bson:"status" json:"status"`
+	DeletedAt   *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+
+	Availability   string     `bson:"availability" json:"availability"`
+	ReleaseDate    *time.Time `bson:"release_date,omitempty" json:"release_date,omitempty"` // Preorder only
+	BackorderLimit int32      `bson:"backorder_limit" json:"backorder_limit"`               // Most units owed to orders at once
+
+	PurchaseLimit *PurchaseLimit `bson:"purchase_limit,omitempty" json:"purchase_limit,omitempty"` // nil for no limit
+
+	// Outbox holds the events of writes to this product that haven't been
+	// published yet. Mongo writes one document atomically, so an event is
+	// saved if and only if its write is.
+	Outbox []*ProductEvent `bson:"outbox,omitempty" json:"-"`
+}
+
+// PurchaseLimit caps how many units one user may buy. Zero fields don't
+// limit. The order service enforces it when orders are placed.
+type PurchaseLimit struct {
+	MaxPerOrder  int32 `bson:"max_per_order" json:"max_per_order"`
+	MaxPerWindow int32 `bson:"max_per_window" json:"max_per_window"` // Across the user's orders in the last WindowDays
+	WindowDays   int32 `bson:"window_days" json:"window_days"`
+}
+
+// IsArchived also covers documents written before status existed, which
+// only ever had deleted_at.
+func (p *Product) IsArchived() bool {
+	return p.Status == StatusArchived || p.DeletedAt != nil
+}
+
+type ProductListQuery struct {
+	Page            int32
+	Limit           int32
+	PageToken       string
+	AfterID         primitive.ObjectID // Decoded from PageToken
+	CategoryID      string
+	CategoryIDs     []string // CategoryID expanded with its descendants
+	IncludeArchived bool
+	IncludeTotal    bool
+}
+
+type ProductPage struct {
+	Products      []*Product
+	Total         *int64 // nil when the count was skipped
+	NextPageToken string
+}
+
+// Sort options accepted by ProductSearch.Sort.
+const (
+	SortRelevance = "relevance"
+	SortNewest    = "newest"
+	SortOldest    = "oldest"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+)
+
+type ProductSearch struct {
+	Query       string
+	CategoryID  string
+	CategoryIDs []string // CategoryID expanded with its descendants
+	MinPrice    float64
+	MaxPrice    float64
+	Sizes       []string
+	Colors      []string
+	Sort        string
+	Page        int32
+	Limit       int32
+}
+
+type FacetCount struct {
+	Value string `bson:"_id" json:"value"`
+	Count int32  `bson:"count" json:"count"`
+}
+
+type SearchFacets struct {
+	Categories []FacetCount `bson:"categories" json:"categories"`
+	Sizes      []FacetCount `bson:"sizes" json:"sizes"`
+	Colors     []FacetCount `bson:"colors" json:"colors"`
 }
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *models.Category) error
+	FindByID(ctx context.Context, id string) (*models.Category, error)
+	Update(ctx context.Context, category *models.Category) error
+	Move(ctx context.Context, category *models.Category, ancestors []string) error
+	Delete(ctx context.Context, id string) error
+	FindSubtree(ctx context.Context, rootID string) ([]*models.Category, error)
+	HasChildren(ctx context.Context, id string) (bool, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type mongoCategoryRepository struct {
+	db *mongo.Database
+}
+
+func NewMongoCategoryRepository(db *mongo.Database) CategoryRepository {
+	return &mongoCategoryRepository{db: db}
+}
+
+func (r *mongoCategoryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.db.Collection("categories").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "ancestors", Value: 1}}},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "sort_order", Value: 1}}},
+	})
+	return err
+}
+
+func (r *mongoCategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	if category.ID.IsZero() {
+		category.ID = primitive.NewObjectID()
+	}
+	_, err := r.db.Collection("categories").InsertOne(ctx, category)
+	return err
+}
+
+func (r *mongoCategoryRepository) FindByID(ctx context.Context, id string) (*models.Category, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	var category models.Category
+	err = r.db.Collection("categories").FindOne(ctx, bson.M{"_id": oid}).Decode(&category)
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *mongoCategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	_, err := r.db.Collection("categories").UpdateByID(ctx, category.ID, bson.M{"$set": bson.M{
+		"name":       category.Name,
+		"slug":       category.Slug,
+		"sort_order": category.SortOrder,
+	}})
+	return err
+}
+
+// Move re-parents category and rewrites the ancestor chain of every
+// descendant: each keeps the part of its chain below category and gets the
+// new prefix in front of it.
+func (r *mongoCategoryRepository) Move(ctx context.Context, category *models.Category, ancestors []string) error {
+	id := category.ID.Hex()
+	coll := r.db.Collection("categories")
+
+	cursor, err := coll.Find(ctx, bson.M{"ancestors": id})
+	if err != nil {
+		return err
+	}
+	var descendants []*models.Category
+	if err := cursor.All(ctx, &descendants); err != nil {
+		return err
+	}
+
+	writes := []mongo.WriteModel{
+		mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": category.ID}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"parent_id":  category.ParentID,
+				"ancestors":  ancestors,
+				"sort_order": category.SortOrder,
+			}}),
+	}
+	for _, d := range descendants {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": d.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"ancestors": movedAncestors(d.Ancestors, id, ancestors)}}))
+	}
+
+	_, err = coll.BulkWrite(ctx, writes)
+	return err
+}
+
+// movedAncestors is the chain of a descendant of category id once id has
+// moved under ancestors: the part from id down is kept.
+func movedAncestors(chain []string, id string, ancestors []string) []string {
+	var below []string
+	for i, a := range chain {
+		if a == id {
+			below = chain[i:]
+			break
+		}
+	}
+	return append(append([]string{}, ancestors...), below...)
+}
+
+func (r *mongoCategoryRepository) Delete(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Collection("categories").DeleteOne(ctx, bson.M{"_id": oid})
+	return err
+}
+
+// FindSubtree returns rootID and all of its descendants, or every category
+// when rootID is empty, ordered by sort_order.
+func (r *mongoCategoryRepository) FindSubtree(ctx context.Context, rootID string) ([]*models.Category, error) {
+	filter := bson.M{}
+	if rootID != "" {
+		oid, err := primitive.ObjectIDFromHex(rootID)
+		if err != nil {
+			return nil, err
+		}
+		filter = bson.M{"$or": bson.A{bson.M{"_id": oid}, bson.M{"ancestors": rootID}}}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "sort_order", Value: 1}, {Key: "name", Value: 1}})
+	cursor, err := r.db.Collection("categories").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var categories []*models.Category
+	if err = cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *mongoCategoryRepository) HasChildren(ctx context.Context, id string) (bool, error) {
+	n, err := r.db.Collection("categories").CountDocuments(ctx, bson.M{"parent_id": id}, options.Count().SetLimit(1))
+	return n > 0, err
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMovedAncestors(t *testing.T) {
+	tests := []struct {
+		name      string
+		chain     []string
+		ancestors []string // New chain of the moved category "b"
+		want      []string
+	}{
+		{"child to root", []string{"a", "b"}, []string{}, []string{"b"}},
+		{"grandchild to root", []string{"a", "b", "c"}, []string{}, []string{"b", "c"}},
+		{"root under another", []string{"b"}, []string{"x", "y"}, []string{"x", "y", "b"}},
+		{"deeper", []string{"a", "b", "c"}, []string{"x"}, []string{"x", "b", "c"}},
+	}
+	for _, tt := range tests {
+		chain := slices.Clone(tt.chain)
+		ancestors := slices.Clone(tt.ancestors)
+		if got := movedAncestors(chain, "b", ancestors); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: movedAncestors = %v, want %v", tt.name, got, tt.want)
+		}
+		if !slices.Equal(chain, tt.chain) || !slices.Equal(ancestors, tt.ancestors) {
+			t.Errorf("%s: inputs modified", tt.name)
+		}
+	}
+}
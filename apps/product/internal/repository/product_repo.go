@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,8 +14,17 @@ import (
 type ProductRepository interface {
 	Create(ctx context.Context, product *models.Product) error
 	FindByID(ctx context.Context, id string) (*models.Product, error)
-	FindAll(ctx context.Context, page, limit int32, categoryID string) ([]*models.Product, int64, error)
-	Delete(ctx context.Context, id string) error
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*models.Product, error)
+	FindAll(ctx context.Context, q models.ProductListQuery) ([]*models.Product, *int64, error)
+	Update(ctx context.Context, product *models.Product, fields []string, event *models.ProductEvent) error
+	Archive(ctx context.Context, id string, at time.Time, event *models.ProductEvent) error
+	Restore(ctx context.Context, id string, event *models.ProductEvent) error
+	FindArchivedBefore(ctx context.Context, before time.Time, afterID primitive.ObjectID, limit int64) ([]*models.Product, error)
+	Purge(ctx context.Context, ids []primitive.ObjectID) (int64, error)
+	ExistsInCategory(ctx context.Context, categoryID string) (bool, error)
+	Search(ctx context.Context, q models.ProductSearch) ([]*models.Product, int64, *models.SearchFacets, error)
+	PublishOutbox(ctx context.Context, limit int64, publish func(*models.ProductEvent) error) (int, error)
+	EnsureIndexes(ctx context.Context) error
 }
 
 type mongoRepository struct {
@@ -25,10 +35,12 @@ func NewMongoRepository(db *mongo.Database) ProductRepository {
 	return &mongoRepository{db: db}
 }
 
+// Create inserts product along with the events in its outbox.
 func (r *mongoRepository) Create(ctx context.Context, product *models.Product) error {
 	if product.ID.IsZero() {
 		product.ID = primitive.NewObjectID()
 	}
+	product.Status = models.StatusActive
 	_, err := r.db.Collection("products").InsertOne(ctx, product)
 	return err
 }
@@ -46,46 +58,344 @@ func (r *mongoRepository) FindByID(ctx context.Context, id string) (*models.Prod
 	return &product, nil
 }
 
-func (r *mongoRepository) FindAll(ctx context.Context, page, limit int32, categoryID string) ([]*models.Product, int64, error) {
-	filter := bson.M{}
-	if categoryID != "" {
-		filter["category_id"] = categoryID
+// FindByIDs fetches the given products in one query, in no particular order.
+func (r *mongoRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*models.Product, error) {
+	cursor, err := r.db.Collection("products").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	// Count total
-	total, err := r.db.Collection("products").CountDocuments(ctx, filter)
-	if err != nil {
-		return nil, 0, err
+	var products []*models.Product
+	if err = cursor.All(ctx, &products); err != nil {
+		return nil, err
 	}
+	return products, nil
+}
 
-	l := int64(limit)
-	p := int64(page)
-	if l <= 0 {
-		l = 10
+// FindAll lists products in _id order. With AfterID set it continues after
+// that product (keyset paging); otherwise it falls back to Page offsets. It
+// returns up to Limit+1 products so the caller can tell whether another page
+// exists, and only counts the matches when IncludeTotal is set.
+func (r *mongoRepository) FindAll(ctx context.Context, q models.ProductListQuery) ([]*models.Product, *int64, error) {
+	filter := bson.M{}
+	if !q.IncludeArchived {
+		// Also matches documents from before soft deletion, which have no
+		// deleted_at at all.
+		filter["deleted_at"] = nil
 	}
-	if p <= 0 {
-		p = 1
+	if len(q.CategoryIDs) > 0 {
+		filter["category_id"] = bson.M{"$in": q.CategoryIDs}
+	}
+
+	var total *int64
+	if q.IncludeTotal {
+		n, err := r.db.Collection("products").CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		total = &n
+	}
+
+	skip, l := pageBounds(q.Page, q.Limit)
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(l + 1)
+	if q.AfterID.IsZero() {
+		opts.SetSkip(skip)
+	} else {
+		filter["_id"] = bson.M{"$gt": q.AfterID}
 	}
 
-	opts := options.Find().SetSkip((p - 1) * l).SetLimit(l)
 	cursor, err := r.db.Collection("products").Find(ctx, filter, opts)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 	defer cursor.Close(ctx)
 
 	var products []*models.Product
 	if err = cursor.All(ctx, &products); err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 	return products, total, nil
 }
 
-func (r *mongoRepository) Delete(ctx context.Context, id string) error {
+// Update writes the named fields of product, by their bson names, leaving
+// the rest of the stored document as it is. Named fields product leaves
+// out, such as a nil release_date, are removed. event goes into the
+// product's outbox in the same write.
+func (r *mongoRepository) Update(ctx context.Context, product *models.Product, fields []string, event *models.ProductEvent) error {
+	raw, err := bson.Marshal(product)
+	if err != nil {
+		return err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+	set, unset := bson.M{}, bson.M{}
+	for _, f := range fields {
+		if v, ok := doc[f]; ok {
+			set[f] = v
+		} else {
+			unset[f] = ""
+		}
+	}
+	update := bson.M{"$set": set, "$push": bson.M{"outbox": event}}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	result, err := r.db.Collection("products").UpdateByID(ctx, product.ID, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Archive saves event to the outbox only if it archives the product.
+func (r *mongoRepository) Archive(ctx context.Context, id string, at time.Time, event *models.ProductEvent) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Collection("products").UpdateOne(ctx,
+		bson.M{"_id": oid, "deleted_at": nil},
+		bson.M{
+			"$set":  bson.M{"status": models.StatusArchived, "deleted_at": at},
+			"$push": bson.M{"outbox": event},
+		},
+	)
+	return err
+}
+
+func (r *mongoRepository) Restore(ctx context.Context, id string, event *models.ProductEvent) error {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Collection("products").DeleteOne(ctx, bson.M{"_id": oid})
+	_, err = r.db.Collection("products").UpdateByID(ctx, oid, bson.M{
+		"$set":   bson.M{"status": models.StatusActive},
+		"$unset": bson.M{"deleted_at": ""},
+		"$push":  bson.M{"outbox": event},
+	})
+	return err
+}
+
+// FindArchivedBefore pages through products archived before the cutoff in
+// _id order, starting after afterID.
+func (r *mongoRepository) FindArchivedBefore(ctx context.Context, before time.Time, afterID primitive.ObjectID, limit int64) ([]*models.Product, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": before}}
+	if !afterID.IsZero() {
+		filter["_id"] = bson.M{"$gt": afterID}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := r.db.Collection("products").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var products []*models.Product
+	if err = cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// Purge hard-deletes products. Only archived products are matched, so a
+// product restored since it was selected is left alone, and only once
+// their outbox is empty, so product.deleted still goes out.
+func (r *mongoRepository) Purge(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	result, err := r.db.Collection("products").DeleteMany(ctx, bson.M{
+		"_id":        bson.M{"$in": ids},
+		"deleted_at": bson.M{"$ne": nil},
+		"outbox.id":  bson.M{"$exists": false},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (r *mongoRepository) ExistsInCategory(ctx context.Context, categoryID string) (bool, error) {
+	n, err := r.db.Collection("products").CountDocuments(ctx, bson.M{"category_id": categoryID, "deleted_at": nil}, options.Count().SetLimit(1))
+	return n > 0, err
+}
+
+func (r *mongoRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.db.Collection("products").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}},
+			Options: options.Index().
+				SetName("text_search").
+				SetWeights(bson.D{{Key: "name", Value: 10}, {Key: "description", Value: 2}}),
+		},
+		{Keys: bson.D{{Key: "category_id", Value: 1}, {Key: "price", Value: 1}}},
+		{Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+		// Only products with unpublished events have one
+		{Keys: bson.D{{Key: "outbox.id", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
 	return err
 }
+
+// PublishOutbox hands the saved events of up to limit products to publish,
+// oldest first per product, and drops each one publish accepts. It stops at
+// the first error, leaving that event and the ones after it for the next
+// call, and returns how many events were published.
+func (r *mongoRepository) PublishOutbox(ctx context.Context, limit int64, publish func(*models.ProductEvent) error) (int, error) {
+	opts := options.Find().SetProjection(bson.M{"outbox": 1}).SetLimit(limit)
+	cursor, err := r.db.Collection("products").Find(ctx, bson.M{"outbox.id": bson.M{"$exists": true}}, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var products []*models.Product
+	if err = cursor.All(ctx, &products); err != nil {
+		return 0, err
+	}
+	published := 0
+	for _, p := range products {
+		for _, event := range p.Outbox {
+			if err := publish(event); err != nil {
+				return published, err
+			}
+			if _, err := r.db.Collection("products").UpdateByID(ctx, p.ID,
+				bson.M{"$pull": bson.M{"outbox": bson.M{"id": event.ID}}},
+			); err != nil {
+				return published, err
+			}
+			published++
+		}
+	}
+	return published, nil
+}
+
+// Search returns a page of the products matching q, how many match in all,
+// and the facet counts for the search sidebar.
+func (r *mongoRepository) Search(ctx context.Context, q models.ProductSearch) ([]*models.Product, int64, *models.SearchFacets, error) {
+	cursor, err := r.db.Collection("products").Aggregate(ctx, searchPipeline(q))
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Results []*models.Product `bson:"results"`
+		Total   []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+		models.SearchFacets `bson:",inline"`
+	}
+	if err = cursor.All(ctx, &result); err != nil {
+		return nil, 0, nil, err
+	}
+	if len(result) == 0 {
+		return nil, 0, &models.SearchFacets{}, nil
+	}
+
+	var total int64
+	if len(result[0].Total) > 0 {
+		total = result[0].Total[0].Count
+	}
+	return result[0].Results, total, &result[0].SearchFacets, nil
+}
+
+// searchPipeline runs the keyword/price match once and fans out with
+// $facet. Each facet ignores its own filter so the sidebar still offers the
+// other values of a dimension the shopper has already narrowed.
+func searchPipeline(q models.ProductSearch) mongo.Pipeline {
+	base := bson.M{"deleted_at": nil}
+	if q.Query != "" {
+		base["$text"] = bson.M{"$search": q.Query}
+	}
+	price := bson.M{}
+	if q.MinPrice > 0 {
+		price["$gte"] = q.MinPrice
+	}
+	if q.MaxPrice > 0 {
+		price["$lte"] = q.MaxPrice
+	}
+	if len(price) > 0 {
+		base["price"] = price
+	}
+
+	category := bson.M{}
+	if len(q.CategoryIDs) > 0 {
+		category["category_id"] = bson.M{"$in": q.CategoryIDs}
+	}
+	sizes := bson.M{}
+	if len(q.Sizes) > 0 {
+		sizes["sizes"] = bson.M{"$in": q.Sizes}
+	}
+	colors := bson.M{}
+	if len(q.Colors) > 0 {
+		colors["colors"] = bson.M{"$in": q.Colors}
+	}
+	all := bson.M{"$and": bson.A{category, sizes, colors}}
+
+	skip, limit := pageBounds(q.Page, q.Limit)
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: base}}}
+	if q.Query != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$facet", Value: bson.M{
+		"results": bson.A{
+			bson.M{"$match": all},
+			bson.M{"$sort": searchSort(q)},
+			bson.M{"$skip": skip},
+			bson.M{"$limit": limit},
+		},
+		"total": bson.A{
+			bson.M{"$match": all},
+			bson.M{"$count": "count"},
+		},
+		"categories": bson.A{
+			bson.M{"$match": bson.M{"$and": bson.A{sizes, colors}}},
+			bson.M{"$sortByCount": "$category_id"},
+		},
+		"sizes": bson.A{
+			bson.M{"$match": bson.M{"$and": bson.A{category, colors}}},
+			bson.M{"$unwind": "$sizes"},
+			bson.M{"$sortByCount": "$sizes"},
+		},
+		"colors": bson.A{
+			bson.M{"$match": bson.M{"$and": bson.A{category, sizes}}},
+			bson.M{"$unwind": "$colors"},
+			bson.M{"$sortByCount": "$colors"},
+		},
+	}}})
+	return pipeline
+}
+
+// ObjectIDs embed their creation time, so _id doubles as the "newest" key.
+func searchSort(q models.ProductSearch) bson.D {
+	switch q.Sort {
+	case models.SortPriceAsc:
+		return bson.D{{Key: "price", Value: 1}, {Key: "_id", Value: -1}}
+	case models.SortPriceDesc:
+		return bson.D{{Key: "price", Value: -1}, {Key: "_id", Value: -1}}
+	case models.SortOldest:
+		return bson.D{{Key: "_id", Value: 1}}
+	case models.SortNewest:
+		return bson.D{{Key: "_id", Value: -1}}
+	}
+	if q.Query != "" {
+		return bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: -1}}
+	}
+	return bson.D{{Key: "_id", Value: -1}}
+}
+
+func pageBounds(page, limit int32) (int64, int64) {
+	l := int64(limit)
+	p := int64(page)
+	if l <= 0 {
+		l = 10
+	}
+	if p <= 0 {
+		p = 1
+	}
+	return (p - 1) * l, l
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// filterFields lists the fields a facet's $match stage filters on.
+func filterFields(t *testing.T, stages bson.A) []string {
+	t.Helper()
+	match, ok := stages[0].(bson.M)["$match"].(bson.M)
+	if !ok {
+		t.Fatalf("first stage %v is not a $match", stages[0])
+	}
+	var fields []string
+	for _, f := range match["$and"].(bson.A) {
+		for k := range f.(bson.M) {
+			fields = append(fields, k)
+		}
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+func TestSearchPipeline(t *testing.T) {
+	tests := []struct {
+		name   string
+		q      models.ProductSearch
+		base   []string
+		facets map[string][]string // Fields each facet filters on
+	}{
+		{
+			name: "no filters",
+			q:    models.ProductSearch{},
+			base: []string{"deleted_at"},
+			facets: map[string][]string{
+				"results": nil, "total": nil, "categories": nil, "sizes": nil, "colors": nil,
+			},
+		},
+		{
+			name: "every filter",
+			q: models.ProductSearch{
+				Query: "shirt", MinPrice: 5, MaxPrice: 50,
+				CategoryIDs: []string{"c1", "c2"}, Sizes: []string{"M"}, Colors: []string{"red"},
+			},
+			base: []string{"$text", "deleted_at", "price"},
+			facets: map[string][]string{
+				"results":    {"category_id", "colors", "sizes"},
+				"total":      {"category_id", "colors", "sizes"},
+				"categories": {"colors", "sizes"},
+				"sizes":      {"category_id", "colors"},
+				"colors":     {"category_id", "sizes"},
+			},
+		},
+		{
+			name: "one facet narrowed",
+			q:    models.ProductSearch{Sizes: []string{"S", "M"}},
+			base: []string{"deleted_at"},
+			facets: map[string][]string{
+				"results": {"sizes"}, "total": {"sizes"}, "categories": {"sizes"}, "sizes": nil, "colors": {"sizes"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := searchPipeline(tt.q)
+			base := pipeline[0][0].Value.(bson.M)
+			var fields []string
+			for k := range base {
+				fields = append(fields, k)
+			}
+			slices.Sort(fields)
+			if !slices.Equal(fields, tt.base) {
+				t.Errorf("base match on %v, want %v", fields, tt.base)
+			}
+
+			facet := pipeline[len(pipeline)-1][0].Value.(bson.M)
+			for name, want := range tt.facets {
+				if got := filterFields(t, facet[name].(bson.A)); !slices.Equal(got, want) {
+					t.Errorf("%s filters on %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchPipelinePriceRange(t *testing.T) {
+	base := searchPipeline(models.ProductSearch{MinPrice: 5})[0][0].Value.(bson.M)
+	if price := base["price"].(bson.M); price["$gte"] != 5.0 || price["$lte"] != nil {
+		t.Fatalf("price = %v, want only $gte 5", price)
+	}
+}
+
+func TestSearchPipelinePages(t *testing.T) {
+	facet := searchPipeline(models.ProductSearch{Page: 3, Limit: 20})
+	results := facet[len(facet)-1][0].Value.(bson.M)["results"].(bson.A)
+	if skip := results[2].(bson.M)["$skip"]; skip != int64(40) {
+		t.Errorf("$skip = %v, want 40", skip)
+	}
+	if limit := results[3].(bson.M)["$limit"]; limit != int64(20) {
+		t.Errorf("$limit = %v, want 20", limit)
+	}
+}
+
+func TestSearchSort(t *testing.T) {
+	tests := []struct {
+		q    models.ProductSearch
+		want string // First sort key
+	}{
+		{models.ProductSearch{}, "_id"},
+		{models.ProductSearch{Query: "shirt"}, "score"},
+		{models.ProductSearch{Query: "shirt", Sort: models.SortRelevance}, "score"},
+		{models.ProductSearch{Query: "shirt", Sort: models.SortPriceAsc}, "price"},
+		{models.ProductSearch{Sort: models.SortPriceDesc}, "price"},
+		{models.ProductSearch{Sort: models.SortOldest}, "_id"},
+	}
+	for _, tt := range tests {
+		if got := searchSort(tt.q); got[0].Key != tt.want {
+			t.Errorf("searchSort(%+v) sorts by %s first, want %s", tt.q, got[0].Key, tt.want)
+		}
+	}
+}
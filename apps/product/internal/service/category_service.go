@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/product/internal/repository"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CategoryService struct {
+	repo     repository.CategoryRepository
+	products repository.ProductRepository
+}
+
+func NewCategoryService(repo repository.CategoryRepository, products repository.ProductRepository) *CategoryService {
+	return &CategoryService{repo: repo, products: products}
+}
+
+func (s *CategoryService) CreateCategory(ctx context.Context, category *models.Category) error {
+	if strings.TrimSpace(category.Name) == "" {
+		return newError(ErrInvalidArgument, "NAME_REQUIRED", "category name is required")
+	}
+	category.Slug = slugify(category.Slug, category.Name)
+	if category.Slug == "" {
+		return newError(ErrInvalidArgument, "SLUG_REQUIRED", "category slug is required")
+	}
+
+	category.Ancestors = []string{}
+	if category.ParentID != "" {
+		parent, err := s.repo.FindByID(ctx, category.ParentID)
+		if err != nil {
+			if isMissing(err) {
+				return newError(ErrInvalidArgument, "PARENT_NOT_FOUND", "parent category does not exist")
+			}
+			return err
+		}
+		// Stored ids are compared as strings, so keep the canonical form
+		category.ParentID = parent.ID.Hex()
+		category.Ancestors = append(slices.Clone(parent.Ancestors), category.ParentID)
+	}
+
+	if err := s.repo.Create(ctx, category); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return newError(ErrAlreadyExists, "SLUG_EXISTS", "category slug already exists")
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *CategoryService) GetCategory(ctx context.Context, id string) (*models.Category, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *CategoryService) UpdateCategory(ctx context.Context, id, name, slug string, sortOrder int32) (*models.Category, error) {
+	category, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil, newError(ErrInvalidArgument, "NAME_REQUIRED", "category name is required")
+	}
+
+	category.Name = name
+	category.Slug = slugify(slug, name)
+	category.SortOrder = sortOrder
+	if category.Slug == "" {
+		return nil, newError(ErrInvalidArgument, "SLUG_REQUIRED", "category slug is required")
+	}
+
+	if err := s.repo.Update(ctx, category); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, newError(ErrAlreadyExists, "SLUG_EXISTS", "category slug already exists")
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *CategoryService) MoveCategory(ctx context.Context, id, parentID string, sortOrder int32) (*models.Category, error) {
+	category, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors := []string{}
+	if parentID != "" {
+		parent, err := s.repo.FindByID(ctx, parentID)
+		if err != nil {
+			if isMissing(err) {
+				return nil, newError(ErrInvalidArgument, "PARENT_NOT_FOUND", "parent category does not exist")
+			}
+			return nil, err
+		}
+		if parent.ID == category.ID || slices.Contains(parent.Ancestors, category.ID.Hex()) {
+			return nil, newError(ErrInvalidArgument, "INVALID_PARENT", "cannot move a category into its own subtree")
+		}
+		// Stored ids are compared as strings, so keep the canonical form
+		parentID = parent.ID.Hex()
+		ancestors = append(slices.Clone(parent.Ancestors), parentID)
+	}
+
+	category.ParentID = parentID
+	category.SortOrder = sortOrder
+	if err := s.repo.Move(ctx, category, ancestors); err != nil {
+		return nil, err
+	}
+	category.Ancestors = ancestors
+	return category, nil
+}
+
+func (s *CategoryService) DeleteCategory(ctx context.Context, id string) error {
+	hasChildren, err := s.repo.HasChildren(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return newError(ErrFailedPrecondition, "CATEGORY_HAS_CHILDREN", "category has subcategories")
+	}
+
+	hasProducts, err := s.products.ExistsInCategory(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasProducts {
+		return newError(ErrFailedPrecondition, "CATEGORY_HAS_PRODUCTS", "category still has products")
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// GetCategoryTree nests the subtree under rootID (or the whole catalogue when
+// rootID is empty). Siblings keep the sort_order the repository returned.
+func (s *CategoryService) GetCategoryTree(ctx context.Context, rootID string) ([]*models.CategoryNode, error) {
+	categories, err := s.repo.FindSubtree(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*models.CategoryNode, len(categories))
+	for _, c := range categories {
+		nodes[c.ID.Hex()] = &models.CategoryNode{Category: c}
+	}
+
+	var roots []*models.CategoryNode
+	for _, c := range categories {
+		node := nodes[c.ID.Hex()]
+		parent, ok := nodes[c.ParentID]
+		if !ok || c.ID.Hex() == rootID {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+	return roots, nil
+}
+
+// slugify lowercases slug (or name when slug is empty) and collapses every
+// run of non letter/digit characters into a single dash. Thai letters are
+// kept as-is.
+func slugify(slug, name string) string {
+	src := slug
+	if strings.TrimSpace(src) == "" {
+		src = name
+	}
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(src) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *fakeCategoryRepo) Create(_ context.Context, c *models.Category) error {
+	c.ID = primitive.NewObjectID()
+	r.categories[c.ID.Hex()] = c
+	return nil
+}
+
+// Move rewrites the chains like the Mongo repository does.
+func (r *fakeCategoryRepo) Move(_ context.Context, moved *models.Category, ancestors []string) error {
+	id := moved.ID.Hex()
+	for _, c := range r.categories {
+		if i := slices.Index(c.Ancestors, id); i >= 0 {
+			c.Ancestors = append(slices.Clone(ancestors), c.Ancestors[i:]...)
+		}
+	}
+	c := r.categories[id]
+	c.ParentID = moved.ParentID
+	c.SortOrder = moved.SortOrder
+	c.Ancestors = ancestors
+	return nil
+}
+
+func TestMoveCategory(t *testing.T) {
+	// men > shirts > tees, and women at the root
+	men := &models.Category{ID: primitive.NewObjectID()}
+	shirts := &models.Category{ID: primitive.NewObjectID(), ParentID: men.ID.Hex()}
+	tees := &models.Category{ID: primitive.NewObjectID(), ParentID: shirts.ID.Hex()}
+	women := &models.Category{ID: primitive.NewObjectID()}
+	hex := func(c *models.Category) string { return c.ID.Hex() }
+
+	tests := []struct {
+		name     string
+		move     *models.Category
+		parentID string
+		reason   string
+		parent   string              // Stored parent_id of the moved category
+		chains   map[string][]string // Ancestors afterwards, by category id
+	}{
+		{
+			name: "to the root",
+			move: shirts,
+			chains: map[string][]string{
+				hex(shirts): {},
+				hex(tees):   {hex(shirts)},
+			},
+		},
+		{
+			name:     "under another root",
+			move:     shirts,
+			parentID: hex(women),
+			parent:   hex(women),
+			chains: map[string][]string{
+				hex(shirts): {hex(women)},
+				hex(tees):   {hex(women), hex(shirts)},
+				hex(men):    {},
+			},
+		},
+		{
+			name:     "parent id in upper case",
+			move:     tees,
+			parentID: strings.ToUpper(hex(women)),
+			parent:   hex(women),
+			chains:   map[string][]string{hex(tees): {hex(women)}},
+		},
+		{name: "into itself", move: shirts, parentID: hex(shirts), reason: "INVALID_PARENT"},
+		{name: "into its child", move: men, parentID: hex(shirts), reason: "INVALID_PARENT"},
+		{name: "into its grandchild", move: men, parentID: hex(tees), reason: "INVALID_PARENT"},
+		{
+			name:     "into its grandchild in upper case",
+			move:     men,
+			parentID: strings.ToUpper(hex(tees)),
+			reason:   "INVALID_PARENT",
+		},
+		{name: "unknown parent", move: shirts, parentID: primitive.NewObjectID().Hex(), reason: "PARENT_NOT_FOUND"},
+		{name: "malformed parent", move: shirts, parentID: "nope", reason: "PARENT_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeCategoryRepo(
+				&models.Category{ID: men.ID},
+				&models.Category{ID: shirts.ID, ParentID: shirts.ParentID},
+				&models.Category{ID: tees.ID, ParentID: tees.ParentID},
+				&models.Category{ID: women.ID},
+			)
+			s := NewCategoryService(repo, &fakeProductRepo{})
+			moved, err := s.MoveCategory(context.Background(), hex(tt.move), tt.parentID, 3)
+			if got := reasonOf(err); got != tt.reason || (tt.reason == "" && err != nil) {
+				t.Fatalf("err = %v, want reason %q", err, tt.reason)
+			}
+			if tt.reason != "" {
+				return
+			}
+			if moved.ParentID != tt.parent || repo.categories[hex(tt.move)].ParentID != tt.parent {
+				t.Errorf("parent = %q, stored %q, want %q", moved.ParentID, repo.categories[hex(tt.move)].ParentID, tt.parent)
+			}
+			if moved.SortOrder != 3 {
+				t.Errorf("sort order = %d, want 3", moved.SortOrder)
+			}
+			for id, want := range tt.chains {
+				if got := repo.categories[id].Ancestors; !slices.Equal(got, want) {
+					t.Errorf("ancestors of %s = %v, want %v", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateCategoryStoresCanonicalParent(t *testing.T) {
+	men := &models.Category{ID: primitive.NewObjectID()}
+	repo := newFakeCategoryRepo(men)
+	s := NewCategoryService(repo, &fakeProductRepo{})
+
+	c := &models.Category{Name: "Shirts", ParentID: strings.ToUpper(men.ID.Hex())}
+	if err := s.CreateCategory(context.Background(), c); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if c.ParentID != men.ID.Hex() || !slices.Equal(c.Ancestors, []string{men.ID.Hex()}) {
+		t.Fatalf("parent = %q, ancestors = %v, want %s", c.ParentID, c.Ancestors, men.ID.Hex())
+	}
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/thapakon-thai/eshop-microservices/proto/grpcerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Failure kinds this service reports; handlers map them with grpcerr.Code.
+var (
+	ErrInvalidArgument    = grpcerr.InvalidArgument
+	ErrNotFound           = grpcerr.NotFound
+	ErrAlreadyExists      = grpcerr.AlreadyExists
+	ErrFailedPrecondition = grpcerr.FailedPrecondition
+)
+
+func newError(kind error, reason, format string, args ...any) error {
+	return grpcerr.New(kind, reason, format, args...)
+}
+
+// isMissing reports whether a repository lookup failed because the id is
+// malformed or matches nothing, as opposed to the database failing.
+func isMissing(err error) bool {
+	return errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, primitive.ErrInvalidHex)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"regexp"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/product/internal/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	MaxImageBytes = 10 << 20
+	// MaxImagePixels caps width times height, as decoding allocates for
+	// every pixel however small the file is
+	MaxImagePixels = 40_000_000
+	thumbnailSize  = 320
+	largeSize      = 1600
+)
+
+var imageKeyPattern = regexp.MustCompile(`^products/[0-9a-f]{24}$`)
+
+var imageExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+type ImageService struct {
+	store storage.ObjectStore
+}
+
+func NewImageService(store storage.ObjectStore) *ImageService {
+	return &ImageService{store: store}
+}
+
+// UploadImage stores the original upload plus WebP "large" and "thumbnail"
+// variants under a fresh key. The content type is sniffed from the bytes
+// rather than trusted from the client.
+func (s *ImageService) UploadImage(ctx context.Context, data []byte) (*models.Image, error) {
+	if len(data) == 0 {
+		return nil, newError(ErrInvalidArgument, "EMPTY_IMAGE", "image is empty")
+	}
+	if len(data) > MaxImageBytes {
+		return nil, newError(ErrInvalidArgument, "IMAGE_TOO_LARGE", "image exceeds %d bytes", MaxImageBytes)
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, newError(ErrInvalidArgument, "UNSUPPORTED_IMAGE_TYPE", "unsupported image type %s", contentType)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, newError(ErrInvalidArgument, "INVALID_IMAGE", "failed to decode image: %v", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > MaxImagePixels/config.Height {
+		return nil, newError(ErrInvalidArgument, "IMAGE_TOO_LARGE", "image exceeds %d pixels", MaxImagePixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, newError(ErrInvalidArgument, "INVALID_IMAGE", "failed to decode image: %v", err)
+	}
+
+	large, err := encodeWebP(fit(img, largeSize))
+	if err != nil {
+		return nil, err
+	}
+	thumbnail, err := encodeWebP(fit(img, thumbnailSize))
+	if err != nil {
+		return nil, err
+	}
+
+	key := "products/" + primitive.NewObjectID().Hex()
+	objects := []struct {
+		name, file, contentType string
+		data                    []byte
+	}{
+		{"original", "original." + ext, contentType, data},
+		{"large", "large.webp", "image/webp", large},
+		{"thumbnail", "thumbnail.webp", "image/webp", thumbnail},
+	}
+
+	result := &models.Image{Key: key, URLs: make(map[string]string, len(objects))}
+	for _, o := range objects {
+		objectKey := key + "/" + o.file
+		if err := s.store.Put(ctx, objectKey, o.contentType, o.data); err != nil {
+			_ = s.store.DeletePrefix(ctx, key)
+			return nil, err
+		}
+		result.URLs[o.name] = s.store.URL(objectKey)
+	}
+	return result, nil
+}
+
+func (s *ImageService) DeleteImage(ctx context.Context, key string) error {
+	if !imageKeyPattern.MatchString(key) {
+		return newError(ErrInvalidArgument, "INVALID_IMAGE_KEY", "invalid image key")
+	}
+	return s.store.DeletePrefix(ctx, key)
+}
+
+// fit scales img down so neither side exceeds size, keeping the aspect
+// ratio. Smaller images are returned untouched.
+func fit(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	if w >= h {
+		h = max(1, h*size/w)
+		w = size
+	} else {
+		w = max(1, w*size/h)
+		h = size
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+func encodeWebP(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := nativewebp.Encode(&buf, img, nil); err != nil {
+		return nil, fmt.Errorf("failed to encode webp: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
+)
+
+// outboxBatchSize is how many products' outboxes one pass reads.
+const outboxBatchSize = 100
+
+// RunOutboxRelay publishes the events saved in product outboxes until ctx is
+// cancelled: as soon as this replica saves some, and every interval for
+// those saved by other replicas or left over by a failed publish.
+func (s *ProductService) RunOutboxRelay(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.relayOutbox(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.outboxReady:
+		}
+	}
+}
+
+func (s *ProductService) relayOutbox(ctx context.Context) {
+	for {
+		n, err := s.repo.PublishOutbox(ctx, outboxBatchSize, func(event *models.ProductEvent) error {
+			return s.publisher.PublishProductEvent(ctx, event)
+		})
+		if err != nil {
+			slog.Error("Failed to publish product events", "published", n, "error", err)
+			return
+		}
+		if n < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// outboxSaved wakes the relay after a write saved events to an outbox.
+func (s *ProductService) outboxSaved() {
+	select {
+	case s.outboxReady <- struct{}{}:
+	default:
+	}
+}
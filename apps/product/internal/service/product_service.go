@@ -2,31 +2,471 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/thapakon-thai/eshop-microservices/product/internal/infrastructure"
 	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
 	"github.com/thapakon-thai/eshop-microservices/product/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// StockReader reads live stock from the inventory service.
+type StockReader interface {
+	// Stock returns the quantity of each of productIDs it could resolve.
+	Stock(ctx context.Context, productIDs []string) (map[string]int32, error)
+}
+
 type ProductService struct {
-	repo repository.ProductRepository
+	repo       repository.ProductRepository
+	categories repository.CategoryRepository
+	publisher  *infrastructure.EventPublisher
+	stock      StockReader
+	orders     *infrastructure.OrderClient
+
+	outboxReady chan struct{} // Wakes RunOutboxRelay
 }
 
-func NewProductService(repo repository.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+func NewProductService(repo repository.ProductRepository, categories repository.CategoryRepository, publisher *infrastructure.EventPublisher, stock StockReader, orders *infrastructure.OrderClient) *ProductService {
+	return &ProductService{
+		repo:        repo,
+		categories:  categories,
+		publisher:   publisher,
+		stock:       stock,
+		orders:      orders,
+		outboxReady: make(chan struct{}, 1),
+	}
 }
 
 func (s *ProductService) CreateProduct(ctx context.Context, product *models.Product) error {
-	return s.repo.Create(ctx, product)
+	if product.CostPrice < 0 {
+		return newError(ErrInvalidArgument, "INVALID_COST_PRICE", "cost_price cannot be negative")
+	}
+	if err := validateAvailability(product); err != nil {
+		return err
+	}
+	if err := validatePurchaseLimit(product); err != nil {
+		return err
+	}
+	if err := s.validateCategory(ctx, product.CategoryID); err != nil {
+		return err
+	}
+	if err := validateImages(product.Images); err != nil {
+		return err
+	}
+	if product.ID.IsZero() {
+		product.ID = primitive.NewObjectID()
+	}
+	product.Outbox = []*models.ProductEvent{newProductEvent(models.ProductCreated, product)}
+	if err := s.repo.Create(ctx, product); err != nil {
+		return err
+	}
+	s.outboxSaved()
+	return nil
+}
+
+// updatableFields are the fields UpdateProduct changes when it isn't told
+// which, by their stored names.
+var updatableFields = []string{
+	"name", "description", "price", "cost_price", "category_id", "sizes", "colors", "images",
+	"availability", "release_date", "backorder_limit", "purchase_limit",
+}
+
+// UpdateProduct sets the named fields of the stored product to their values
+// in changes, or every updatable field when fields is empty, and returns the
+// product as updated. Fields not named keep their stored values.
+func (s *ProductService) UpdateProduct(ctx context.Context, changes *models.Product, fields []string) (*models.Product, error) {
+	if len(fields) == 0 {
+		fields = updatableFields
+	}
+	for _, f := range fields {
+		if !slices.Contains(updatableFields, f) {
+			return nil, newError(ErrInvalidArgument, "UNKNOWN_FIELD", "field %q cannot be updated", f)
+		}
+	}
+	existing, err := s.repo.FindByID(ctx, changes.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if existing.IsArchived() {
+		return nil, newError(ErrFailedPrecondition, "PRODUCT_ARCHIVED", "product is archived; restore it before editing")
+	}
+
+	product := *existing
+	for _, f := range fields {
+		switch f {
+		case "name":
+			product.Name = changes.Name
+		case "description":
+			product.Description = changes.Description
+		case "price":
+			product.Price = changes.Price
+		case "cost_price":
+			product.CostPrice = changes.CostPrice
+		case "category_id":
+			product.CategoryID = changes.CategoryID
+		case "sizes":
+			product.Sizes = changes.Sizes
+		case "colors":
+			product.Colors = changes.Colors
+		case "images":
+			product.Images = changes.Images
+		case "availability":
+			product.Availability = changes.Availability
+		case "release_date":
+			product.ReleaseDate = changes.ReleaseDate
+		case "backorder_limit":
+			product.BackorderLimit = changes.BackorderLimit
+		case "purchase_limit":
+			product.PurchaseLimit = changes.PurchaseLimit
+		}
+	}
+	product.Status = models.StatusActive
+	if product.CostPrice < 0 {
+		return nil, newError(ErrInvalidArgument, "INVALID_COST_PRICE", "cost_price cannot be negative")
+	}
+	if err := validateAvailability(&product); err != nil {
+		return nil, err
+	}
+	if err := validatePurchaseLimit(&product); err != nil {
+		return nil, err
+	}
+	if err := s.validateCategory(ctx, product.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := validateImages(product.Images); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, &product, append(fields, "status"), newProductEvent(models.ProductUpdated, &product)); err != nil {
+		return nil, err
+	}
+	s.outboxSaved()
+	s.attachStock(ctx, &product)
+	return &product, nil
 }
 
 func (s *ProductService) GetProduct(ctx context.Context, id string) (*models.Product, error) {
-	return s.repo.FindByID(ctx, id)
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.attachStock(ctx, product)
+	return product, nil
 }
 
-func (s *ProductService) ListProducts(ctx context.Context, page, limit int32, categoryID string) ([]*models.Product, int64, error) {
-	return s.repo.FindAll(ctx, page, limit, categoryID)
+// MaxBatchSize caps how many products one batch lookup may ask for.
+const MaxBatchSize = 500
+
+// BatchGetProducts returns the products found for ids in request order, plus
+// the ids that matched nothing (including ones that aren't valid ObjectIDs).
+func (s *ProductService) BatchGetProducts(ctx context.Context, ids []string) ([]*models.Product, []string, error) {
+	if len(ids) > MaxBatchSize {
+		return nil, nil, newError(ErrInvalidArgument, "BATCH_TOO_LARGE", "at most %d products per batch", MaxBatchSize)
+	}
+
+	oids := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+
+	byID := make(map[string]*models.Product, len(oids))
+	if len(oids) > 0 {
+		found, err := s.repo.FindByIDs(ctx, oids)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, p := range found {
+			byID[p.ID.Hex()] = p
+		}
+	}
+
+	products := make([]*models.Product, 0, len(byID))
+	var missing []string
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if p, ok := byID[id]; ok {
+			products = append(products, p)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	s.attachStock(ctx, products...)
+	return products, missing, nil
 }
 
+const maxPageSize = 100
+
+func (s *ProductService) ListProducts(ctx context.Context, q models.ProductListQuery) (*models.ProductPage, error) {
+	if q.Limit <= 0 {
+		q.Limit = 10
+	}
+	q.Limit = min(q.Limit, maxPageSize)
+
+	if q.PageToken != "" {
+		after, err := decodePageToken(q.PageToken)
+		if err != nil {
+			return nil, err
+		}
+		q.AfterID = after
+		q.Page = 0
+	} else if q.Page > 0 {
+		// Page-number clients have always been given a total
+		q.IncludeTotal = true
+	}
+
+	categoryIDs, err := s.categoryScope(ctx, q.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	q.CategoryIDs = categoryIDs
+
+	products, total, err := s.repo.FindAll(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.ProductPage{Products: products, Total: total}
+	if len(products) > int(q.Limit) {
+		page.Products = products[:q.Limit]
+		page.NextPageToken = encodePageToken(page.Products[q.Limit-1].ID)
+	}
+	s.attachStock(ctx, page.Products...)
+	return page, nil
+}
+
+// DeleteProduct archives the product rather than removing it, so orders
+// keep a product to point at. RunRetention purges it later.
 func (s *ProductService) DeleteProduct(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if product.IsArchived() {
+		return nil
+	}
+	if err := s.repo.Archive(ctx, id, time.Now().UTC(), newProductEvent(models.ProductDeleted, product)); err != nil {
+		return err
+	}
+	s.outboxSaved()
+	return nil
+}
+
+func (s *ProductService) RestoreProduct(ctx context.Context, id string) (*models.Product, error) {
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product.IsArchived() {
+		if err := s.validateCategory(ctx, product.CategoryID); err != nil {
+			return nil, err
+		}
+		product.Status = models.StatusActive
+		product.DeletedAt = nil
+		if err := s.repo.Restore(ctx, id, newProductEvent(models.ProductRestored, product)); err != nil {
+			return nil, err
+		}
+		s.outboxSaved()
+	}
+	s.attachStock(ctx, product)
+	return product, nil
+}
+
+func (s *ProductService) SearchProducts(ctx context.Context, q models.ProductSearch) ([]*models.Product, int64, *models.SearchFacets, error) {
+	if q.MinPrice < 0 || q.MaxPrice < 0 {
+		return nil, 0, nil, newError(ErrInvalidArgument, "INVALID_PRICE_RANGE", "price range cannot be negative")
+	}
+	if q.MaxPrice > 0 && q.MinPrice > q.MaxPrice {
+		return nil, 0, nil, newError(ErrInvalidArgument, "INVALID_PRICE_RANGE", "min_price cannot be greater than max_price")
+	}
+	switch q.Sort {
+	case "", models.SortRelevance, models.SortNewest, models.SortOldest, models.SortPriceAsc, models.SortPriceDesc:
+	default:
+		return nil, 0, nil, newError(ErrInvalidArgument, "INVALID_SORT", "unknown sort: %s", q.Sort)
+	}
+	q.Limit = min(q.Limit, maxPageSize)
+	categoryIDs, err := s.categoryScope(ctx, q.CategoryID)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	q.CategoryIDs = categoryIDs
+	products, total, facets, err := s.repo.Search(ctx, q)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	s.attachStock(ctx, products...)
+	return products, total, facets, nil
+}
+
+// attachStock fills in live stock from inventory. Catalogue reads still
+// succeed while inventory is down; stock then reads as last cached, or 0.
+func (s *ProductService) attachStock(ctx context.Context, products ...*models.Product) {
+	if len(products) == 0 {
+		return
+	}
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID.Hex())
+	}
+
+	stock, err := s.stock.Stock(ctx, ids)
+	if err != nil {
+		slog.Warn("Failed to fetch stock from inventory", "cached", len(stock), "error", err)
+	}
+	for _, p := range products {
+		p.Stock = stock[p.ID.Hex()]
+	}
+}
+
+// Page tokens are opaque to clients; today they carry the last _id seen.
+type pageToken struct {
+	After string `json:"after"`
+}
+
+func encodePageToken(after primitive.ObjectID) string {
+	b, _ := json.Marshal(pageToken{After: after.Hex()})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePageToken(token string) (primitive.ObjectID, error) {
+	invalid := newError(ErrInvalidArgument, "INVALID_PAGE_TOKEN", "invalid page_token")
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return primitive.NilObjectID, invalid
+	}
+	var t pageToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		return primitive.NilObjectID, invalid
+	}
+	after, err := primitive.ObjectIDFromHex(t.After)
+	if err != nil {
+		return primitive.NilObjectID, invalid
+	}
+	return after, nil
+}
+
+// newProductEvent snapshots p for an event to save with the write it
+// describes. RunOutboxRelay publishes it once the write is stored.
+func newProductEvent(eventType string, p *models.Product) *models.ProductEvent {
+	return &models.ProductEvent{
+		ID:         primitive.NewObjectID().Hex(),
+		Type:       eventType,
+		Version:    models.ProductEventVersion,
+		OccurredAt: time.Now().UTC(),
+		Data: &models.ProductEventData{
+			ProductID:  p.ID.Hex(),
+			Name:       p.Name,
+			Price:      p.Price,
+			Stock:      p.Stock,
+			CategoryID: p.CategoryID,
+			Sizes:      p.Sizes,
+			Colors:     p.Colors,
+
+			Availability:   p.Availability,
+			ReleaseDate:    p.ReleaseDate,
+			BackorderLimit: p.BackorderLimit,
+		},
+	}
+}
+
+// validateAvailability defaults an empty availability to in stock only, and
+// checks that products sold beyond stock say how far.
+func validateAvailability(p *models.Product) error {
+	switch p.Availability {
+	case "":
+		p.Availability = models.AvailabilityInStock
+	case models.AvailabilityInStock, models.AvailabilityBackorder:
+	case models.AvailabilityPreorder:
+		if p.ReleaseDate == nil {
+			return newError(ErrInvalidArgument, "RELEASE_DATE_REQUIRED", "release_date is required for pre-orders")
+		}
+	default:
+		return newError(ErrInvalidArgument, "INVALID_AVAILABILITY", "availability must be in_stock, backorder or preorder")
+	}
+	if p.Availability != models.AvailabilityPreorder {
+		p.ReleaseDate = nil
+	}
+	if p.BackorderLimit < 0 {
+		return newError(ErrInvalidArgument, "INVALID_BACKORDER_LIMIT", "backorder_limit cannot be negative")
+	}
+	if p.Availability != models.AvailabilityInStock && p.BackorderLimit == 0 {
+		return newError(ErrInvalidArgument, "INVALID_BACKORDER_LIMIT", "backorder_limit is required for %s", p.Availability)
+	}
+	return nil
+}
+
+// validatePurchaseLimit drops a limit that limits nothing and checks that
+// a per-window limit says how long its window is.
+func validatePurchaseLimit(p *models.Product) error {
+	l := p.PurchaseLimit
+	if l == nil {
+		return nil
+	}
+	if l.MaxPerOrder < 0 || l.MaxPerWindow < 0 || l.WindowDays < 0 {
+		return newError(ErrInvalidArgument, "INVALID_PURCHASE_LIMIT", "purchase_limit fields cannot be negative")
+	}
+	if (l.MaxPerWindow > 0) != (l.WindowDays > 0) {
+		return newError(ErrInvalidArgument, "INVALID_PURCHASE_LIMIT", "max_per_window and window_days must be set together")
+	}
+	if l.MaxPerOrder == 0 && l.MaxPerWindow == 0 {
+		p.PurchaseLimit = nil
+	}
+	return nil
+}
+
+func (s *ProductService) validateCategory(ctx context.Context, categoryID string) error {
+	if categoryID == "" {
+		return nil
+	}
+	if _, err := s.categories.FindByID(ctx, categoryID); err != nil {
+		if isMissing(err) {
+			return newError(ErrInvalidArgument, "CATEGORY_NOT_FOUND", "category %s does not exist", categoryID)
+		}
+		return err
+	}
+	return nil
+}
+
+// validateImages rejects inline (data URI) images; they must be uploaded
+// through ImageService first and referenced by URL.
+func validateImages(images map[string]string) error {
+	for name, src := range images {
+		if strings.HasPrefix(src, "data:") {
+			return newError(ErrInvalidArgument, "INLINE_IMAGE", "image %q must be uploaded and referenced by URL", name)
+		}
+	}
+	return nil
+}
+
+// categoryScope expands categoryID into itself plus all of its descendants.
+// Ids that are not categories (e.g. data created before categories were
+// validated) are still matched literally.
+func (s *ProductService) categoryScope(ctx context.Context, categoryID string) ([]string, error) {
+	if categoryID == "" {
+		return nil, nil
+	}
+	if _, err := primitive.ObjectIDFromHex(categoryID); err != nil {
+		return []string{categoryID}, nil
+	}
+
+	subtree, err := s.categories.FindSubtree(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{categoryID}
+	for _, c := range subtree {
+		if id := c.ID.Hex(); id != categoryID {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
 }
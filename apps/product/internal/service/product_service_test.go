@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/thapakon-thai/eshop-microservices/product/internal/models"
+	"github.com/thapakon-thai/eshop-microservices/product/internal/repository"
+	"github.com/thapakon-thai/eshop-microservices/proto/grpcerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeProductRepo holds products in _id order.
+type fakeProductRepo struct {
+	repository.ProductRepository
+	products []*models.Product
+	search   models.ProductSearch // What Search was last asked
+	updated  []string             // Fields Update was last asked to write
+	event    *models.ProductEvent // Event Update was last asked to save
+}
+
+func (r *fakeProductRepo) FindByID(_ context.Context, id string) (*models.Product, error) {
+	for _, p := range r.products {
+		if p.ID.Hex() == id {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *fakeProductRepo) Update(_ context.Context, product *models.Product, fields []string, event *models.ProductEvent) error {
+	r.updated, r.event = fields, event
+	return nil
+}
+
+func (r *fakeProductRepo) FindAll(_ context.Context, q models.ProductListQuery) ([]*models.Product, *int64, error) {
+	var matched []*models.Product
+	for _, p := range r.products {
+		if q.AfterID.IsZero() || p.ID.Hex() > q.AfterID.Hex() {
+			matched = append(matched, p)
+		}
+	}
+	var total *int64
+	if q.IncludeTotal {
+		n := int64(len(matched))
+		total = &n
+	}
+	if q.AfterID.IsZero() && q.Page > 1 {
+		matched = matched[min(int(q.Page-1)*int(q.Limit), len(matched)):]
+	}
+	return matched[:min(int(q.Limit)+1, len(matched))], total, nil
+}
+
+func (r *fakeProductRepo) Search(_ context.Context, q models.ProductSearch) ([]*models.Product, int64, *models.SearchFacets, error) {
+	r.search = q
+	return nil, 0, &models.SearchFacets{}, nil
+}
+
+// fakeCategoryRepo holds categories by hex id.
+type fakeCategoryRepo struct {
+	repository.CategoryRepository
+	categories map[string]*models.Category
+}
+
+// newFakeCategoryRepo stores categories, filling in their ancestors from
+// their parents, which must come first.
+func newFakeCategoryRepo(categories ...*models.Category) *fakeCategoryRepo {
+	r := &fakeCategoryRepo{categories: make(map[string]*models.Category)}
+	for _, c := range categories {
+		c.Ancestors = []string{}
+		if parent, ok := r.categories[c.ParentID]; ok {
+			c.Ancestors = append(slices.Clone(parent.Ancestors), c.ParentID)
+		}
+		r.categories[c.ID.Hex()] = c
+	}
+	return r
+}
+
+func (r *fakeCategoryRepo) FindByID(_ context.Context, id string) (*models.Category, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := r.categories[oid.Hex()]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (r *fakeCategoryRepo) FindSubtree(_ context.Context, rootID string) ([]*models.Category, error) {
+	var subtree []*models.Category
+	for id, c := range r.categories {
+		if id == rootID || slices.Contains(c.Ancestors, rootID) {
+			subtree = append(subtree, c)
+		}
+	}
+	return subtree, nil
+}
+
+type fakeStock map[string]int32
+
+func (f fakeStock) Stock(context.Context, []string) (map[string]int32, error) {
+	return f, nil
+}
+
+// reasonOf is the ErrorInfo reason err carries, or "" for none.
+func reasonOf(err error) string {
+	var e *grpcerr.Error
+	if errors.As(err, &e) {
+		return e.Reason
+	}
+	return ""
+}
+
+func TestSearchProducts(t *testing.T) {
+	shirts := &models.Category{ID: primitive.NewObjectID()}
+	tees := &models.Category{ID: primitive.NewObjectID(), ParentID: shirts.ID.Hex()}
+	categories := newFakeCategoryRepo(shirts, tees)
+
+	tests := []struct {
+		name       string
+		q          models.ProductSearch
+		reason     string
+		categories []string // Sent to the repository, sorted
+		limit      int32
+	}{
+		{name: "no filters", q: models.ProductSearch{Limit: 20}, limit: 20},
+		{name: "negative min price", q: models.ProductSearch{MinPrice: -1}, reason: "INVALID_PRICE_RANGE"},
+		{name: "negative max price", q: models.ProductSearch{MaxPrice: -1}, reason: "INVALID_PRICE_RANGE"},
+		{name: "min above max", q: models.ProductSearch{MinPrice: 10, MaxPrice: 5}, reason: "INVALID_PRICE_RANGE"},
+		{name: "min without max", q: models.ProductSearch{MinPrice: 10}},
+		{name: "unknown sort", q: models.ProductSearch{Sort: "popular"}, reason: "INVALID_SORT"},
+		{name: "known sort", q: models.ProductSearch{Sort: models.SortPriceDesc}},
+		{
+			name:       "category covers its subtree",
+			q:          models.ProductSearch{CategoryID: shirts.ID.Hex()},
+			categories: sortedIDs(shirts, tees),
+		},
+		{
+			name:       "leaf category",
+			q:          models.ProductSearch{CategoryID: tees.ID.Hex()},
+			categories: []string{tees.ID.Hex()},
+		},
+		{name: "legacy category id", q: models.ProductSearch{CategoryID: "shoes"}, categories: []string{"shoes"}},
+		{name: "page size capped", q: models.ProductSearch{Limit: 1000}, limit: maxPageSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeProductRepo{}
+			s := NewProductService(repo, categories, nil, fakeStock{}, nil)
+			_, _, _, err := s.SearchProducts(context.Background(), tt.q)
+			if got := reasonOf(err); got != tt.reason || (tt.reason == "" && err != nil) {
+				t.Fatalf("err = %v, want reason %q", err, tt.reason)
+			}
+			if tt.reason != "" {
+				return
+			}
+			got := slices.Sorted(slices.Values(repo.search.CategoryIDs))
+			if !slices.Equal(got, tt.categories) {
+				t.Errorf("category ids = %v, want %v", got, tt.categories)
+			}
+			if repo.search.Limit != tt.limit {
+				t.Errorf("limit = %d, want %d", repo.search.Limit, tt.limit)
+			}
+		})
+	}
+}
+
+func sortedIDs(categories ...*models.Category) []string {
+	ids := make([]string, len(categories))
+	for i, c := range categories {
+		ids[i] = c.ID.Hex()
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestPageToken(t *testing.T) {
+	id := primitive.NewObjectID()
+	got, err := decodePageToken(encodePageToken(id))
+	if err != nil || got != id {
+		t.Fatalf("round trip = %v, %v, want %v", got, err, id)
+	}
+
+	for _, token := range []string{
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"after":"nope"}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{}`)),
+	} {
+		if _, err := decodePageToken(token); reasonOf(err) != "INVALID_PAGE_TOKEN" {
+			t.Errorf("decodePageToken(%q) err = %v, want INVALID_PAGE_TOKEN", token, err)
+		}
+	}
+}
+
+func TestListProductsPages(t *testing.T) {
+	repo := &fakeProductRepo{}
+	for i := range 25 {
+		id := primitive.NewObjectIDFromTimestamp(time.Unix(int64(1_700_000_000+i), 0))
+		repo.products = append(repo.products, &models.Product{ID: id})
+	}
+	s := NewProductService(repo, newFakeCategoryRepo(), nil, fakeStock{}, nil)
+	ctx := context.Background()
+
+	t.Run("tokens walk every product once", func(t *testing.T) {
+		var seen []primitive.ObjectID
+		token := ""
+		for pages := 1; ; pages++ {
+			page, err := s.ListProducts(ctx, models.ProductListQuery{Limit: 10, PageToken: token})
+			if err != nil {
+				t.Fatalf("page %d: %v", pages, err)
+			}
+			if page.Total != nil {
+				t.Errorf("page %d counted %d products; tokens skip the count", pages, *page.Total)
+			}
+			for _, p := range page.Products {
+				seen = append(seen, p.ID)
+			}
+			if token = page.NextPageToken; token == "" {
+				if pages != 3 {
+					t.Errorf("pages = %d, want 3", pages)
+				}
+				break
+			}
+		}
+		want := make([]primitive.ObjectID, len(repo.products))
+		for i, p := range repo.products {
+			want[i] = p.ID
+		}
+		if !slices.Equal(seen, want) {
+			t.Fatalf("walked %d products, want all %d in order", len(seen), len(want))
+		}
+	})
+
+	tests := []struct {
+		name  string
+		q     models.ProductListQuery
+		first int // Index of the first product on the page
+		count int
+		total bool
+		next  bool
+	}{
+		{name: "default page size", q: models.ProductListQuery{}, count: 10, next: true},
+		{name: "page numbers count", q: models.ProductListQuery{Page: 2, Limit: 10}, first: 10, count: 10, total: true, next: true},
+		{name: "last page number", q: models.ProductListQuery{Page: 3, Limit: 10}, first: 20, count: 5, total: true},
+		{name: "exact fit has no next page", q: models.ProductListQuery{Limit: 25}, count: 25},
+		{name: "total on request", q: models.ProductListQuery{Limit: 5, IncludeTotal: true}, count: 5, total: true, next: true},
+		{
+			name:  "token wins over page",
+			q:     models.ProductListQuery{Page: 3, Limit: 5, PageToken: encodePageToken(repo.products[4].ID)},
+			first: 5, count: 5, next: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := s.ListProducts(ctx, tt.q)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if len(page.Products) != tt.count || page.Products[0].ID != repo.products[tt.first].ID {
+				t.Errorf("got %d products from %v, want %d from #%d", len(page.Products), page.Products[0].ID, tt.count, tt.first)
+			}
+			if (page.Total != nil) != tt.total || page.Total != nil && *page.Total != 25 {
+				t.Errorf("total = %v, want counted: %v", page.Total, tt.total)
+			}
+			if (page.NextPageToken != "") != tt.next {
+				t.Errorf("next page token = %q, want one: %v", page.NextPageToken, tt.next)
+			}
+		})
+	}
+
+	if _, err := s.ListProducts(ctx, models.ProductListQuery{PageToken: "garbage"}); reasonOf(err) != "INVALID_PAGE_TOKEN" {
+		t.Errorf("bad token err = %v, want INVALID_PAGE_TOKEN", err)
+	}
+}
+
+func TestUpdateProductMergesNamedFields(t *testing.T) {
+	tops := &models.Category{ID: primitive.NewObjectID()}
+	release := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	stored := func() *models.Product {
+		return &models.Product{
+			ID:             primitive.NewObjectID(),
+			Name:           "Tee",
+			Description:    "Cotton",
+			Price:          20,
+			CostPrice:      8,
+			CategoryID:     tops.ID.Hex(),
+			Sizes:          []string{"M"},
+			Status:         models.StatusActive,
+			Availability:   models.AvailabilityPreorder,
+			ReleaseDate:    &release,
+			BackorderLimit: 50,
+			PurchaseLimit:  &models.PurchaseLimit{MaxPerOrder: 2},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		changes models.Product
+		fields  []string
+		reason  string
+		check   func(t *testing.T, before, after *models.Product)
+	}{
+		{
+			name:    "only named fields change",
+			changes: models.Product{Name: "Shirt", Price: 0, Description: ""},
+			fields:  []string{"name"},
+			check: func(t *testing.T, before, after *models.Product) {
+				if after.Name != "Shirt" || after.Price != before.Price || after.Description != before.Description {
+					t.Errorf("got %q at %v (%q), want Shirt at %v (%q)", after.Name, after.Price, after.Description, before.Price, before.Description)
+				}
+				if after.ReleaseDate == nil || after.PurchaseLimit == nil {
+					t.Errorf("unnamed release date or purchase limit was cleared")
+				}
+			},
+		},
+		{
+			name:    "zero values are written when named",
+			changes: models.Product{Availability: models.AvailabilityInStock},
+			fields:  []string{"availability", "backorder_limit", "purchase_limit"},
+			check: func(t *testing.T, _, after *models.Product) {
+				if after.Availability != models.AvailabilityInStock || after.BackorderLimit != 0 || after.PurchaseLimit != nil {
+					t.Errorf("got %s with limit %d and %+v, want in stock with no limits", after.Availability, after.BackorderLimit, after.PurchaseLimit)
+				}
+				if after.ReleaseDate != nil {
+					t.Errorf("release date kept for an in-stock product")
+				}
+			},
+		},
+		{
+			name: "no fields means every field",
+			changes: models.Product{
+				Name: "Shirt", Price: 30, CategoryID: tops.ID.Hex(),
+				Availability: models.AvailabilityBackorder, BackorderLimit: 5,
+			},
+			check: func(t *testing.T, _, after *models.Product) {
+				if after.Name != "Shirt" || after.Description != "" || after.Sizes != nil || after.ReleaseDate != nil {
+					t.Errorf("got %+v, want every field replaced", after)
+				}
+			},
+		},
+		{name: "unknown field", fields: []string{"status"}, reason: "UNKNOWN_FIELD"},
+		{name: "stock is not updatable", fields: []string{"stock"}, reason: "UNKNOWN_FIELD"},
+		{
+			name:    "merged product is validated",
+			changes: models.Product{Availability: models.AvailabilityPreorder},
+			fields:  []string{"release_date"},
+			reason:  "RELEASE_DATE_REQUIRED",
+		},
+		{
+			name:    "unknown category",
+			changes: models.Product{CategoryID: primitive.NewObjectID().Hex()},
+			fields:  []string{"category_id"},
+			reason:  "CATEGORY_NOT_FOUND",
+		},
+		{
+			name:    "invalid purchase limit",
+			changes: models.Product{PurchaseLimit: &models.PurchaseLimit{MaxPerWindow: 3}},
+			fields:  []string{"purchase_limit"},
+			reason:  "INVALID_PURCHASE_LIMIT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := stored()
+			repo := &fakeProductRepo{products: []*models.Product{before}}
+			s := NewProductService(repo, newFakeCategoryRepo(tops), nil, fakeStock{}, nil)
+			changes := tt.changes
+			changes.ID = before.ID
+			after, err := s.UpdateProduct(context.Background(), &changes, tt.fields)
+			if got := reasonOf(err); got != tt.reason || (tt.reason == "" && err != nil) {
+				t.Fatalf("err = %v, want reason %q", err, tt.reason)
+			}
+			if tt.reason != "" {
+				if repo.updated != nil {
+					t.Fatalf("rejected update wrote %v", repo.updated)
+				}
+				return
+			}
+			tt.check(t, before, after)
+
+			want := append(slices.Clone(tt.fields), "status")
+			if len(tt.fields) == 0 {
+				want = append(slices.Clone(updatableFields), "status")
+			}
+			if !slices.Equal(repo.updated, want) {
+				t.Errorf("wrote %v, want %v", repo.updated, want)
+			}
+			if repo.event == nil || repo.event.Type != models.ProductUpdated || repo.event.Data.Name != after.Name {
+				t.Errorf("event = %+v, want product.updated for the merged product", repo.event)
+			}
+		})
+	}
+}
+
+func TestUpdateProductRefusesArchived(t *testing.T) {
+	archived := &models.Product{ID: primitive.NewObjectID(), Status: models.StatusArchived}
+	repo := &fakeProductRepo{products: []*models.Product{archived}}
+	s := NewProductService(repo, newFakeCategoryRepo(), nil, fakeStock{}, nil)
+	_, err := s.UpdateProduct(context.Background(), &models.Product{ID: archived.ID, Name: "x"}, []string{"name"})
+	if reasonOf(err) != "PRODUCT_ARCHIVED" {
+		t.Fatalf("err = %v, want PRODUCT_ARCHIVED", err)
+	}
+}
+
+func TestValidatePurchaseLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		limit  *models.PurchaseLimit
+		reason string
+		want   *models.PurchaseLimit // Stored afterwards
+	}{
+		{name: "no limit"},
+		{name: "per order", limit: &models.PurchaseLimit{MaxPerOrder: 2}, want: &models.PurchaseLimit{MaxPerOrder: 2}},
+		{
+			name:  "per window",
+			limit: &models.PurchaseLimit{MaxPerWindow: 5, WindowDays: 7},
+			want:  &models.PurchaseLimit{MaxPerWindow: 5, WindowDays: 7},
+		},
+		{
+			name:  "both",
+			limit: &models.PurchaseLimit{MaxPerOrder: 2, MaxPerWindow: 5, WindowDays: 7},
+			want:  &models.PurchaseLimit{MaxPerOrder: 2, MaxPerWindow: 5, WindowDays: 7},
+		},
+		{name: "all zero is dropped", limit: &models.PurchaseLimit{}},
+		{name: "window limit without days", limit: &models.PurchaseLimit{MaxPerWindow: 5}, reason: "INVALID_PURCHASE_LIMIT"},
+		{name: "days without a window limit", limit: &models.PurchaseLimit{MaxPerOrder: 1, WindowDays: 7}, reason: "INVALID_PURCHASE_LIMIT"},
+		{name: "negative per order", limit: &models.PurchaseLimit{MaxPerOrder: -1}, reason: "INVALID_PURCHASE_LIMIT"},
+		{name: "negative per window", limit: &models.PurchaseLimit{MaxPerWindow: -1, WindowDays: 7}, reason: "INVALID_PURCHASE_LIMIT"},
+		{name: "negative days", limit: &models.PurchaseLimit{MaxPerWindow: 5, WindowDays: -7}, reason: "INVALID_PURCHASE_LIMIT"},
+	}
+	for _, tt := range tests {
+		p := &models.Product{PurchaseLimit: tt.limit}
+		err := validatePurchaseLimit(p)
+		if got := reasonOf(err); got != tt.reason || (tt.reason == "" && err != nil) {
+			t.Errorf("%s: err = %v, want reason %q", tt.name, err, tt.reason)
+			continue
+		}
+		if tt.reason != "" {
+			continue
+		}
+		if (p.PurchaseLimit == nil) != (tt.want == nil) || p.PurchaseLimit != nil && *p.PurchaseLimit != *tt.want {
+			t.Errorf("%s: limit = %+v, want %+v", tt.name, p.PurchaseLimit, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const purgeBatchSize = 100
+
+// RunRetention purges long-archived products every interval until ctx is
+// cancelled.
+func (s *ProductService) RunRetention(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeArchived(ctx, retention)
+		if err != nil {
+			slog.Error("Product retention run failed", "purged", purged, "error", err)
+		} else if purged > 0 {
+			slog.Info("Purged archived products", "count", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeArchived hard-deletes products archived for longer than retention,
+// skipping any that still appear on an order so order history keeps its
+// product reference. If the order service can't be asked, nothing in that
+// batch is deleted.
+func (s *ProductService) PurgeArchived(ctx context.Context, retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+	var purged int64
+	var after primitive.ObjectID
+
+	for {
+		products, err := s.repo.FindArchivedBefore(ctx, cutoff, after, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		if len(products) == 0 {
+			return purged, nil
+		}
+		after = products[len(products)-1].ID
+
+		ids := make([]string, len(products))
+		for i, p := range products {
+			ids[i] = p.ID.Hex()
+		}
+		referenced, err := s.orders.ReferencedProducts(ctx, ids)
+		if err != nil {
+			return purged, err
+		}
+
+		var unreferenced []primitive.ObjectID
+		for _, p := range products {
+			if !referenced[p.ID.Hex()] {
+				unreferenced = append(unreferenced, p.ID)
+			}
+		}
+		if len(unreferenced) == 0 {
+			continue
+		}
+
+		n, err := s.repo.Purge(ctx, unreferenced)
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thapakon-thai/eshop-microservices/product/internal/config"
+)
+
+// ObjectStore is where product images live. Products only keep the URLs it
+// hands out, never the bytes.
+type ObjectStore interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	DeletePrefix(ctx context.Context, prefix string) error
+	URL(key string) string
+}
+
+func NewObjectStore(ctx context.Context, cfg config.ImageConfig) (ObjectStore, error) {
+	switch cfg.Storage {
+	case "local":
+		return NewLocalStore(cfg.Dir, cfg.BaseURL)
+	case "s3":
+		return NewS3Store(ctx, cfg)
+	}
+	return nil, fmt.Errorf("unknown IMAGE_STORAGE %q", cfg.Storage)
+}
@@ -0,0 +1,61 @@
+// Package grpcerr holds the failure kinds the services report and turns them
+// into gRPC status errors carrying a google.rpc.ErrorInfo detail.
+package grpcerr
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Kinds of failure a caller can act on. Anything else is treated as an
+// internal or database error.
+var (
+	InvalidArgument    = errors.New("invalid argument")
+	NotFound           = errors.New("not found")
+	AlreadyExists      = errors.New("already exists")
+	FailedPrecondition = errors.New("failed precondition")
+)
+
+// Error is a failure of one of the kinds above. Reason is a stable
+// UPPER_SNAKE_CASE identifier reported as google.rpc.ErrorInfo.reason.
+type Error struct {
+	Kind    error
+	Reason  string
+	Message string
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func (e *Error) Unwrap() error { return e.Kind }
+
+func New(kind error, reason, format string, args ...any) error {
+	return &Error{Kind: kind, Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
+// Code is the gRPC code of a kind, Internal for anything else.
+func Code(kind error) codes.Code {
+	switch kind {
+	case InvalidArgument:
+		return codes.InvalidArgument
+	case NotFound:
+		return codes.NotFound
+	case AlreadyExists:
+		return codes.AlreadyExists
+	case FailedPrecondition:
+		return codes.FailedPrecondition
+	}
+	return codes.Internal
+}
+
+// Status builds a status error whose ErrorInfo names the service's domain.
+func Status(domain string, code codes.Code, reason, msg string) error {
+	st := status.New(code, msg)
+	if withInfo, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: domain}); err == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
@@ -0,0 +1,56 @@
+package grpcerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		kind error
+		want codes.Code
+	}{
+		{InvalidArgument, codes.InvalidArgument},
+		{NotFound, codes.NotFound},
+		{AlreadyExists, codes.AlreadyExists},
+		{FailedPrecondition, codes.FailedPrecondition},
+		{errors.New("invalid argument"), codes.Internal},
+		{nil, codes.Internal},
+	}
+	for _, tt := range tests {
+		if got := Code(tt.kind); got != tt.want {
+			t.Errorf("Code(%v) = %v, want %v", tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestNewWrapsKind(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", New(NotFound, "SKU_NOT_FOUND", "sku %q not found", "x"))
+	var e *Error
+	if !errors.As(err, &e) || e.Reason != "SKU_NOT_FOUND" || e.Message != `sku "x" not found` {
+		t.Fatalf("err = %v, want SKU_NOT_FOUND", err)
+	}
+	if !errors.Is(err, NotFound) || errors.Is(err, InvalidArgument) {
+		t.Fatalf("err = %v, want only the NotFound kind", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	st, ok := status.FromError(Status("test.eshop", codes.FailedPrecondition, "OUT_OF_STOCK", "none left"))
+	if !ok || st.Code() != codes.FailedPrecondition || st.Message() != "none left" {
+		t.Fatalf("status = %v, want FailedPrecondition none left", st)
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("details = %v, want one ErrorInfo", details)
+	}
+	info, ok := details[0].(*errdetails.ErrorInfo)
+	if !ok || info.Reason != "OUT_OF_STOCK" || info.Domain != "test.eshop" {
+		t.Fatalf("detail = %v, want OUT_OF_STOCK in test.eshop", details[0])
+	}
+}
@@ -27,17 +27,32 @@ if [ -z "$ACCESS_TOKEN" ]; then
 fi
 echo "Access Token: $ACCESS_TOKEN"
 
+echo "--------------------------------------------------"
+echo "3a. Creating Category..."
+CATEGORY_RESPONSE=$(curl -s -X POST "$GATEWAY_URL/categories" \
+  -H "Content-Type: application/json" \
+  -d "{\"name\": \"Test Category $(date +%s)\"}")
+echo "Response: $CATEGORY_RESPONSE"
+
+CATEGORY_ID=$(echo $CATEGORY_RESPONSE | grep -o '"id": *"[^"]*"' | head -1 | cut -d'"' -f4)
+
+if [ -z "$CATEGORY_ID" ]; then
+  echo "Error: Failed to get category ID"
+  exit 1
+fi
+echo "Category ID: $CATEGORY_ID"
+
 echo "--------------------------------------------------"
 echo "3. Creating Product..."
 PRODUCT_RESPONSE=$(curl -s -X POST "$GATEWAY_URL/products" \
   -H "Content-Type: application/json" \
-  -d '{
-    "name": "Test Product",
-    "description": "A product for testing",
-    "price": 99.99,
-    "stock": 100,
-    "category_id": "test-cat"
-  }')
+  -d "{
+    \"name\": \"Test Product\",
+    \"description\": \"A product for testing\",
+    \"price\": 99.99,
+    \"stock\": 100,
+    \"category_id\": \"$CATEGORY_ID\"
+  }")
 echo "Response: $PRODUCT_RESPONSE"
 
 PRODUCT_ID=$(echo $PRODUCT_RESPONSE | grep -o '"id": *"[^"]*"' | cut -d'"' -f4)
@@ -53,19 +68,11 @@ echo "Product ID: $PRODUCT_ID"
 
 echo "--------------------------------------------------"
 echo "4. Checking Stock (Inventory)..."
+# Stock is initialized asynchronously from the product.created event
+sleep 2
 INVENTORY_RESPONSE=$(curl -s -X GET "$GATEWAY_URL/inventory/$PRODUCT_ID")
 echo "Response: $INVENTORY_RESPONSE"
 
-echo "--------------------------------------------------"
-echo "4a. Seeding Stock..."
-STOCK_RESPONSE=$(curl -s -X POST "$GATEWAY_URL/inventory/stock" \
-  -H "Content-Type: application/json" \
-  -d "{
-    \"product_id\": \"$PRODUCT_ID\",
-    \"quantity_change\": 100
-  }")
-echo "Response: $STOCK_RESPONSE"
-
 echo "--------------------------------------------------"
 echo "5. Creating Order..."
 ORDER_RESPONSE=$(curl -s -X POST "$GATEWAY_URL/order/api/v1/orders" \